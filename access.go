@@ -0,0 +1,159 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// AccessStatus reports whether the configured account can read a product.
+type AccessStatus int
+
+const (
+	// AccessForbidden means the product exists in the catalog but the account
+	// is not entitled to it (401, 403 or 404 on the product endpoint).
+	AccessForbidden AccessStatus = iota
+	// AccessGranted means the product details could be read.
+	AccessGranted
+)
+
+func (s AccessStatus) String() string {
+	if s == AccessGranted {
+		return "accessible"
+	}
+	return "forbidden"
+}
+
+// MarshalText encodes the status as its String, e.g. in JSON.
+func (s AccessStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ProductAccess is a catalog product flagged with whether the account can
+// read it, as listed by ListAccessibleProducts.
+type ProductAccess struct {
+	*Product
+	Access AccessStatus `json:"access"`
+}
+
+// Accessible reports whether the account can read the product.
+func (p *ProductAccess) Accessible() bool {
+	return p.Access == AccessGranted
+}
+
+// ListAccessibleProducts lists each catalog product once, in catalog order,
+// flagged with whether the configured account can read it, so tools can
+// offer only usable products instead of failing at download time. It
+// probes each product like ProbeAccess, with one metadata request, several
+// products at a time.
+func (c *Client) ListAccessibleProducts(ctx context.Context, opts ...CallOption) ([]*ProductAccess, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+	products, err := c.ListProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The catalog lists some products in more than one section.
+	products = uniqueProducts(products)
+	result := make([]*ProductAccess, len(products))
+	// Probes run defaultConcurrency at a time; the first failure cancels the
+	// rest. Config.MaxConcurrentRequests still applies to each request.
+	ctx, cancelProbes := context.WithCancel(ctx)
+	defer cancelProbes()
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < defaultConcurrency && i < len(products); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				status, err := c.probeProduct(ctx, products[i].ID)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+						cancelProbes()
+					}
+					mu.Unlock()
+					continue
+				}
+				result[i] = &ProductAccess{Product: products[i], Access: status}
+			}
+		}()
+	}
+	for i := range products {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProbeAccess checks every product in the catalog for accessibility and returns
+// a map of product ID to AccessStatus. Each product is probed with a single
+// metadata request (GetProductOverview); no file data is downloaded. Entitlement
+// failures are recorded as AccessForbidden, while transient failures (network
+// errors, 5xx after retries, rate limiting) abort the probe with an error so a
+// flaky connection is never reported as a missing subscription.
+func (c *Client) ProbeAccess(ctx context.Context) (map[int]AccessStatus, error) {
+	products, err := c.ListAccessibleProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int]AccessStatus, len(products))
+	for _, p := range products {
+		result[p.ID] = p.Access
+	}
+	return result, nil
+}
+
+// FreeProducts reports for each catalog product whether it is free, i.e.
+// readable without credentials, or needs a subscription. It probes every
+// product like ListAccessibleProducts, but WithoutCredentials, also on a
+// client with credentials. The API does not flag free products, so this is
+// the only reliable way to tell them apart; products can change tier.
+func (c *Client) FreeProducts(ctx context.Context) (map[int]bool, error) {
+	products, err := c.ListAccessibleProducts(ctx, WithoutCredentials())
+	if err != nil {
+		return nil, err
+	}
+	free := make(map[int]bool, len(products))
+	for _, p := range products {
+		free[p.ID] = p.Accessible()
+	}
+	return free, nil
+}
+
+// probeProduct classifies a single product's accessibility.
+func (c *Client) probeProduct(ctx context.Context, productID int) (AccessStatus, error) {
+	// The overview skips decoding the file lists, which for products with
+	// years of history dominate the response.
+	_, err := c.GetProductOverview(ctx, productID)
+	if err == nil {
+		return AccessGranted, nil
+	}
+	if isEntitlementError(err) {
+		return AccessForbidden, nil
+	}
+	return AccessForbidden, err
+}
+
+// isEntitlementError reports whether err means the account may not read the
+// resource, as opposed to a transient failure.
+func isEntitlementError(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,226 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newAccessServer serves a three-product catalog (with product 2 listed twice)
+// where product 1 is readable, product 2 is forbidden and product 3 is missing.
+// Product details for product 1 answer with detailStatus.
+func newAccessServer(detailStatus int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/products/"):
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": 1, "name": "free", "description": "a"},
+				{"id": 2, "name": "paid", "description": "b"},
+				{"id": 2, "name": "paid", "description": "b"},
+				{"id": 3, "name": "gone", "description": "c"},
+			})
+		case strings.HasSuffix(r.URL.Path, "/products/1"):
+			if detailStatus != http.StatusOK {
+				w.WriteHeader(detailStatus)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": 1, "name": "free", "description": "a", "deliveries": []interface{}{},
+			})
+		case strings.HasSuffix(r.URL.Path, "/products/2"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// TestProbeAccess verifies products are classified by their detail response.
+func TestProbeAccess(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newAccessServer(http.StatusOK)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	access, err := client.ProbeAccess(context.Background())
+	if err != nil {
+		t.Fatalf("ProbeAccess: %v", err)
+	}
+	want := map[int]AccessStatus{1: AccessGranted, 2: AccessForbidden, 3: AccessForbidden}
+	if len(access) != len(want) {
+		t.Fatalf("expected %d products, got %d: %v", len(want), len(access), access)
+	}
+	for id, status := range want {
+		if access[id] != status {
+			t.Errorf("product %d: got %s, want %s", id, access[id], status)
+		}
+	}
+}
+
+// TestProbeAccessTransientError verifies a server failure aborts the probe
+// instead of being reported as a forbidden product.
+func TestProbeAccessTransientError(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newAccessServer(http.StatusInternalServerError)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	if _, err := client.ProbeAccess(context.Background()); err == nil {
+		t.Fatal("expected error when a product probe fails with 5xx")
+	}
+}
+
+// TestListAccessibleProducts verifies products are listed once, in catalog
+// order, with their access.
+func TestListAccessibleProducts(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newAccessServer(http.StatusOK)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	products, err := client.ListAccessibleProducts(context.Background())
+	if err != nil {
+		t.Fatalf("ListAccessibleProducts: %v", err)
+	}
+	var got []string
+	for _, p := range products {
+		got = append(got, fmt.Sprintf("%d:%s:%v", p.ID, p.Name, p.Accessible()))
+	}
+	if want := []string{"1:free:true", "2:paid:false", "3:gone:false"}; !slices.Equal(got, want) {
+		t.Errorf("ListAccessibleProducts = %v, want %v", got, want)
+	}
+
+	data, err := json.Marshal(products[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":1,"name":"free","description":"a","access":"accessible"}`; string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
+
+// TestListAccessibleProductsConcurrent verifies products are probed several
+// at a time, at most defaultConcurrency, and still listed in catalog order.
+func TestListAccessibleProductsConcurrent(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	const products = 8
+	var inFlight, maxInFlight atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/products/") {
+			var catalog []map[string]interface{}
+			for id := 1; id <= products; id++ {
+				catalog = append(catalog, map[string]interface{}{"id": id, "name": fmt.Sprint(id), "description": ""})
+			}
+			_ = json.NewEncoder(w).Encode(catalog)
+			return
+		}
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		var id int
+		_, _ = fmt.Sscanf(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], "%d", &id)
+		// Later products answer sooner, so results arrive out of order.
+		time.Sleep(time.Duration(products-id) * 5 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": id, "name": fmt.Sprint(id), "description": "", "deliveries": []interface{}{},
+		})
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	result, err := client.ListAccessibleProducts(context.Background())
+	if err != nil {
+		t.Fatalf("ListAccessibleProducts: %v", err)
+	}
+	var got []int
+	for _, p := range result {
+		if !p.Accessible() {
+			t.Errorf("product %d not accessible", p.ID)
+		}
+		got = append(got, p.ID)
+	}
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8}; !slices.Equal(got, want) {
+		t.Errorf("ListAccessibleProducts = %v, want %v", got, want)
+	}
+	if n := maxInFlight.Load(); n < 2 || n > defaultConcurrency {
+		t.Errorf("%d probes in flight at once, want 2 to %d", n, defaultConcurrency)
+	}
+}
+
+// TestFreeProducts verifies products are classified by anonymous probes,
+// even on a client with credentials, without retrying rejected ones.
+func TestFreeProducts(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	var authorized, paidProbes atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			authorized.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/products/"):
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": 1, "name": "free", "description": "a"},
+				{"id": 2, "name": "paid", "description": "b"},
+			})
+		case strings.HasSuffix(r.URL.Path, "/products/1"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": 1, "name": "free", "description": "a", "deliveries": []interface{}{},
+			})
+		case strings.HasSuffix(r.URL.Path, "/products/2"):
+			paidProbes.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	free, err := client.FreeProducts(context.Background())
+	if err != nil {
+		t.Fatalf("FreeProducts: %v", err)
+	}
+	if want := map[int]bool{1: true, 2: false}; !maps.Equal(free, want) {
+		t.Errorf("FreeProducts = %v, want %v", free, want)
+	}
+	if n := authorized.Load(); n != 0 {
+		t.Errorf("%d requests sent credentials", n)
+	}
+	if n := paidProbes.Load(); n != 1 {
+		t.Errorf("paid product probed %d times, want 1", n)
+	}
+
+	// Without the option the client authenticates as usual.
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if authorized.Load() == 0 {
+		t.Error("ListProducts sent no credentials")
+	}
+}
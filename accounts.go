@@ -0,0 +1,73 @@
+package bdds
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Account is a set of EPO credentials used for some products (see
+// Config.Accounts).
+type Account struct {
+	Username string
+	Password string
+	Products []int // IDs of the products requested with this account
+}
+
+// account holds the credentials and the token state of one EPO account.
+type account struct {
+	username string
+	password string
+	// credentials, if set, supplies the current username and password for
+	// password grants; username and password are those fetched first.
+	credentials *credentialSource
+
+	mu            sync.Mutex
+	token         string
+	tokenIssued   time.Time // when the client obtained the token
+	tokenExpiry   time.Time
+	rejectedToken string      // last token the API answered 401 to
+	grant         *tokenGrant // password grant in flight, if any
+}
+
+// productAccounts indexes the configured accounts by product ID.
+// Config.Validate ensures each has credentials and a product belongs to one
+// account only.
+func productAccounts(accounts []Account) map[int]*account {
+	byProduct := make(map[int]*account)
+	for _, cfg := range accounts {
+		a := &account{username: cfg.Username, password: cfg.Password}
+		for _, productID := range cfg.Products {
+			byProduct[productID] = a
+		}
+	}
+	return byProduct
+}
+
+var productPathPattern = regexp.MustCompile(`/products/(\d+)(?:/|$)`)
+
+// accountFor returns the account a request path is authorized with: the
+// account of the product it addresses, or the default account.
+func (c *Client) accountFor(path string) *account {
+	if m := productPathPattern.FindStringSubmatch(path); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		if a, ok := c.accounts[id]; ok {
+			return a
+		}
+	}
+	return c.account
+}
+
+// allAccounts returns the default account and the configured ones.
+func (c *Client) allAccounts() []*account {
+	all := []*account{c.account}
+	seen := map[*account]bool{c.account: true}
+	for _, a := range c.accounts {
+		if !seen[a] {
+			seen[a] = true
+			all = append(all, a)
+		}
+	}
+	return all
+}
@@ -0,0 +1,96 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAccountsRouteByProduct(t *testing.T) {
+	var mu sync.Mutex
+	grants := map[string]int{}
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		user := r.PostForm.Get("username")
+		mu.Lock()
+		grants[user]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token-" + user, "expires_in": 3600})
+	}))
+	defer authServer.Close()
+
+	seen := map[string]string{} // request path suffix -> Authorization
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path[strings.LastIndex(r.URL.Path, "/products"):]] = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/products/") {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"name":"x","deliveries":[]}`))
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(&Config{
+		Username: "default",
+		Password: "p",
+		BaseURL:  apiServer.URL,
+		TokenURL: authServer.URL,
+		Accounts: []Account{
+			{Username: "docdb", Password: "p", Products: []int{3}},
+			{Username: "fulltext", Password: "p", Products: []int{4, 14}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.ListProducts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{3, 4, 14, 32, 3} {
+		if _, err := client.GetProduct(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{
+		"/products/":   "Bearer token-default",
+		"/products/3":  "Bearer token-docdb",
+		"/products/4":  "Bearer token-fulltext",
+		"/products/14": "Bearer token-fulltext",
+		"/products/32": "Bearer token-default",
+	}
+	for path, auth := range want {
+		if seen[path] != auth {
+			t.Errorf("%s authorized with %q, want %q", path, seen[path], auth)
+		}
+	}
+	for user, n := range grants {
+		if n != 1 {
+			t.Errorf("%d password grants for %s, want 1", n, user)
+		}
+	}
+}
+
+func TestAccountsValidation(t *testing.T) {
+	tests := map[string][]Account{
+		"duplicate product": {
+			{Username: "a", Password: "p", Products: []int{3}},
+			{Username: "b", Password: "p", Products: []int{4, 3}},
+		},
+		"no credentials": {{Username: "a", Products: []int{3}}},
+	}
+	for name, accounts := range tests {
+		if _, err := NewClient(&Config{Accounts: accounts}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes one request sent to the API or the OAuth endpoint.
+type AuditRecord struct {
+	Time       time.Time `json:"time"` // when the request was sent
+	Method     string    `json:"method"`
+	Endpoint   string    `json:"endpoint"` // URL without query
+	ProductID  int       `json:"productId,omitempty"`
+	DeliveryID int       `json:"deliveryId,omitempty"`
+	FileID     int       `json:"fileId,omitempty"`
+	StatusCode int       `json:"status,omitempty"` // 0 if no response arrived
+	Bytes      int64     `json:"bytes"`            // response body bytes read
+	RequestID  string    `json:"requestId,omitempty"`
+	Err        string    `json:"error,omitempty"`
+}
+
+// Auditor records the requests of a client, e.g. as evidence of what was
+// retrieved from EPO for licensing compliance (see Config.Auditor).
+type Auditor interface {
+	Audit(ctx context.Context, r *AuditRecord) error
+}
+
+// AuditorFunc adapts a function to an Auditor.
+type AuditorFunc func(ctx context.Context, r *AuditRecord) error
+
+// Audit calls f.
+func (f AuditorFunc) Audit(ctx context.Context, r *AuditRecord) error {
+	return f(ctx, r)
+}
+
+// AuditLog is an Auditor appending records to a file as JSON lines.
+type AuditLog struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenAuditLog opens the audit log at path for appending, creating it if it
+// does not exist.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{f: f}, nil
+}
+
+// Audit appends r to the log in a single write.
+func (l *AuditLog) Audit(_ context.Context, r *AuditRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close flushes the log to disk and closes it.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Sync(); err != nil {
+		_ = l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+// auditPathPattern extracts the IDs addressed by an API path.
+var auditPathPattern = regexp.MustCompile(`/products/(\d+)(?:/delivery/(\d+)(?:/file/(\d+))?)?`)
+
+// auditTransport records each request with Config.Auditor once its
+// response body is closed or read to the end.
+type auditTransport struct {
+	base    http.RoundTripper
+	auditor Auditor
+	logger  *slog.Logger
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &AuditRecord{
+		Time:      time.Now().UTC(),
+		Method:    req.Method,
+		Endpoint:  logURL(req.URL),
+		RequestID: req.Header.Get(RequestIDHeader),
+	}
+	if m := auditPathPattern.FindStringSubmatch(req.URL.Path); m != nil {
+		r.ProductID, _ = strconv.Atoi(m[1])
+		r.DeliveryID, _ = strconv.Atoi(m[2])
+		r.FileID, _ = strconv.Atoi(m[3])
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		r.Err = redactURLError(err).Error()
+		t.audit(req.Context(), r)
+		return nil, err
+	}
+	r.StatusCode = resp.StatusCode
+	resp.Body = &auditBody{ReadCloser: resp.Body, record: r, done: func() { t.audit(req.Context(), r) }}
+	return resp, nil
+}
+
+func (t *auditTransport) audit(ctx context.Context, r *AuditRecord) {
+	if err := t.auditor.Audit(context.WithoutCancel(ctx), r); err != nil {
+		t.logger.ErrorContext(ctx, "bdds: audit failed", "endpoint", r.Endpoint, "error", err)
+	}
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *auditTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// auditBody counts the bytes read into its record and has it audited once
+// the body ends or is closed.
+type auditBody struct {
+	io.ReadCloser
+	record *AuditRecord
+	done   func()
+	once   sync.Once
+}
+
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.record.Bytes += int64(n)
+	if err != nil {
+		if err != io.EOF {
+			b.record.Err = err.Error()
+		}
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *auditBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,99 @@
+package bdds
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer apiServer.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	client, err := NewClient(&Config{
+		Username: "u",
+		Password: "p",
+		BaseURL:  apiServer.URL,
+		Auditor:  auditLog,
+		Transport: &testTransport{
+			authURL: authServer.URL + "/oauth2/aus3up3nz0N133c0V417/v1/token",
+			rt:      http.DefaultTransport,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.DownloadFile(context.Background(), 3, 7, 9, &bytes.Buffer{}); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if err := auditLog.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var records []AuditRecord
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var r AuditRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want token and download", records)
+	}
+	token, download := records[0], records[1]
+	if token.Method != http.MethodPost || token.ProductID != 0 || token.StatusCode != http.StatusOK {
+		t.Errorf("token record = %+v", token)
+	}
+	if download.Method != http.MethodGet || download.ProductID != 3 || download.DeliveryID != 7 || download.FileID != 9 ||
+		download.StatusCode != http.StatusOK || download.Bytes != 5 || download.RequestID == "" ||
+		!strings.HasSuffix(download.Endpoint, "/products/3/delivery/7/file/9/download") || download.Time.IsZero() {
+		t.Errorf("download record = %+v", download)
+	}
+}
+
+func TestAuditorFailedRequest(t *testing.T) {
+	apiServer := httptest.NewServer(http.NotFoundHandler())
+	apiServer.Close()
+
+	var records []*AuditRecord
+	client, err := NewClient(&Config{
+		BaseURL:    apiServer.URL,
+		MaxRetries: 1,
+		RetryDelay: 1,
+		Auditor: AuditorFunc(func(_ context.Context, r *AuditRecord) error {
+			records = append(records, r)
+			return nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.GetProduct(context.Background(), 3); err == nil {
+		t.Fatal("GetProduct of a closed server succeeded")
+	}
+	if len(records) != 2 || records[0].Err == "" || records[0].StatusCode != 0 || records[0].ProductID != 3 {
+		t.Errorf("records of two failed attempts = %+v", records)
+	}
+}
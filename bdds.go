@@ -0,0 +1,31 @@
+package bdds
+
+import (
+	"context"
+	"io"
+)
+
+// BDDS is the catalog and download API of a *Client, so code that uses the
+// client can be unit-tested with a fake instead of an httptest server. A
+// fake may embed BDDS and implement only the methods a test calls.
+type BDDS interface {
+	ListProducts(ctx context.Context, opts ...CallOption) ([]*Product, error)
+	GetProduct(ctx context.Context, productID int, opts ...CallOption) (*ProductWithDeliveries, error)
+	GetProductByName(ctx context.Context, name string) (*Product, error)
+	GetLatestDelivery(ctx context.Context, productID int) (*Delivery, error)
+	GetDelivery(ctx context.Context, productID, deliveryID int, opts ...CallOption) (*Delivery, error)
+	ListDeliveries(ctx context.Context, productID int, filter *DeliveryFilter, opts ...CallOption) ([]*Delivery, error)
+	GetFileInfo(ctx context.Context, productID, deliveryID, fileID int, opts ...CallOption) (*FileInfo, error)
+
+	DownloadFile(ctx context.Context, productID, deliveryID, fileID int, dst io.Writer, opts ...CallOption) error
+	DownloadFileWithProgress(ctx context.Context, productID, deliveryID, fileID int, dst io.Writer, progressFn func(bytesWritten, totalBytes int64), opts ...CallOption) error
+	DownloadFileToPath(ctx context.Context, productID, deliveryID, fileID int, path string, opts ...CallOption) error
+	DownloadFileStream(ctx context.Context, productID, deliveryID, fileID int, opts ...CallOption) (io.ReadCloser, error)
+	DownloadFileRange(ctx context.Context, productID, deliveryID, fileID int, offset, length int64, dst io.Writer, opts ...CallOption) (int64, error)
+	DownloadAndExtract(ctx context.Context, productID, deliveryID, fileID int, destDir string) ([]string, error)
+	DownloadDelivery(ctx context.Context, productID, deliveryID int, destDir string, opts *DownloadOptions) ([]*FileResult, error)
+	DownloadProduct(ctx context.Context, productID int, destDir string, opts *DownloadOptions) ([]*FileResult, error)
+	DownloadFiles(ctx context.Context, refs []FileRef, destDir string, opts *DownloadOptions) ([]*FileResult, error)
+}
+
+var _ BDDS = (*Client)(nil)
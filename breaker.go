@@ -0,0 +1,114 @@
+package bdds
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = time.Minute
+)
+
+// CircuitBreakerConfig enables the client's circuit breaker (see
+// Config.CircuitBreaker).
+type CircuitBreakerConfig struct {
+	// Threshold is the number of consecutive server (5xx) or authentication
+	// failures that opens the circuit (default: 5). Retries count
+	// individually.
+	Threshold int
+	// Cooldown is how long the circuit stays open (default: 1 minute). After
+	// it, the circuit is half-open: one request is let through as a probe
+	// while the others keep failing fast. A failed probe re-opens the
+	// circuit at once, a successful one closes it.
+	Cooldown time.Duration
+}
+
+// circuitBreaker tracks consecutive failures across all requests of a client.
+// A nil *circuitBreaker never opens.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool // a half-open circuit's probe is in flight
+	lastErr   error
+}
+
+func newCircuitBreaker(cfg *CircuitBreakerConfig) *circuitBreaker {
+	if cfg == nil {
+		return nil
+	}
+	b := &circuitBreaker{
+		threshold: cfg.Threshold,
+		cooldown:  cfg.Cooldown,
+		now:       time.Now,
+	}
+	if b.threshold <= 0 {
+		b.threshold = defaultBreakerThreshold
+	}
+	if b.cooldown <= 0 {
+		b.cooldown = defaultBreakerCooldown
+	}
+	return b
+}
+
+// allow returns a CircuitOpenError while the circuit is open. Once the
+// cool-down has passed, it lets one request through as the probe, reporting
+// it as such, and fails the others until the probe is recorded.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return false, nil
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false, &CircuitOpenError{Until: b.openUntil, Failures: b.failures, Err: b.lastErr}
+	}
+	b.probing = true
+	return true, nil
+}
+
+// record updates the failure count with the outcome of one request, and
+// ends the half-open circuit's probe if the request was it. Errors that say
+// nothing about the server's health (e.g. 404) leave the count unchanged; a
+// probe failing with one is followed by another.
+func (b *circuitBreaker) record(probe bool, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	if err == nil {
+		b.failures, b.lastErr = 0, nil
+		return
+	}
+	if !tripsBreaker(err) {
+		return
+	}
+	b.failures++
+	b.lastErr = err
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// tripsBreaker reports whether err is a server or authentication failure.
+func tripsBreaker(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var authErr *AuthError
+	return errors.As(err, &authErr)
+}
@@ -0,0 +1,175 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCircuitBreakerOpens verifies consecutive 5xx responses open the circuit,
+// which then fails calls fast until the cool-down has passed.
+func TestCircuitBreakerOpens(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	var calls int32
+	healthy := atomic.Bool{}
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	client.breaker = newCircuitBreaker(&CircuitBreakerConfig{Threshold: 3, Cooldown: time.Minute})
+	now := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	client.breaker.now = func() time.Time { return now }
+
+	// MaxRetries 3 allows 4 attempts; the third failure opens the circuit.
+	_, err := client.ListProducts(context.Background())
+	var open *CircuitOpenError
+	if !errors.As(err, &open) {
+		t.Fatalf("expected *CircuitOpenError, got %v", err)
+	}
+	var status *statusError
+	if !errors.As(err, &status) || status.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the last 503 to be wrapped, got %v", err)
+	}
+	if c := atomic.LoadInt32(&calls); c != 3 {
+		t.Errorf("expected 3 requests before the circuit opened, got %d", c)
+	}
+
+	// While open, calls fail without reaching the server.
+	if _, err := client.ListProducts(context.Background()); !errors.As(err, &open) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+	if c := atomic.LoadInt32(&calls); c != 3 {
+		t.Errorf("open circuit let %d requests through", c-3)
+	}
+
+	// After the cool-down a successful request closes it again.
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatalf("expected recovery after cool-down, got %v", err)
+	}
+	if client.breaker.failures != 0 {
+		t.Errorf("failures = %d after success, want 0", client.breaker.failures)
+	}
+}
+
+// TestCircuitBreakerIgnoresClientErrors verifies 404s do not count as server
+// failures.
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	b := newCircuitBreaker(&CircuitBreakerConfig{Threshold: 1})
+	b.record(false, &NotFoundError{Resource: "file", ID: "1/2/3"})
+	b.record(false, &statusError{StatusCode: http.StatusBadRequest})
+	if _, err := b.allow(); err != nil {
+		t.Fatalf("client errors opened the circuit: %v", err)
+	}
+	b.record(false, &AuthError{StatusCode: http.StatusUnauthorized})
+	if _, err := b.allow(); err == nil {
+		t.Fatal("expected auth failure to open the circuit")
+	}
+}
+
+// TestCircuitBreakerHalfOpen verifies that after the cool-down only one of
+// many concurrent requests reaches the server, and that its outcome decides
+// whether the circuit closes or re-opens.
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	var calls int32
+	healthy := atomic.Bool{}
+	release := make(chan struct{})
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	client.config.MaxRetries = 0
+	client.breaker = newCircuitBreaker(&CircuitBreakerConfig{Threshold: 1, Cooldown: time.Minute})
+	var mu sync.Mutex
+	now := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	client.breaker.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(time.Minute)
+	}
+	ctx := context.Background()
+
+	go func() { release <- struct{}{} }()
+	if _, err := client.ListProducts(ctx); err == nil {
+		t.Fatal("expected the 503 to fail the call")
+	}
+
+	// probeRound starts concurrent calls once the cool-down has passed and
+	// returns the probe's error, checking all others failed fast.
+	probeRound := func() error {
+		t.Helper()
+		advance()
+		before := atomic.LoadInt32(&calls)
+		const callers = 8
+		errs := make(chan error, callers)
+		for range callers {
+			go func() {
+				_, err := client.ListProducts(ctx)
+				errs <- err
+			}()
+		}
+		var open *CircuitOpenError
+		for range callers - 1 {
+			if err := <-errs; !errors.As(err, &open) {
+				t.Fatalf("expected a fast CircuitOpenError besides the probe, got %v", err)
+			}
+		}
+		waitFor(t, func() bool { return atomic.LoadInt32(&calls) > before })
+		release <- struct{}{}
+		err := <-errs
+		if n := atomic.LoadInt32(&calls) - before; n != 1 {
+			t.Errorf("half-open circuit let %d requests through, want 1", n)
+		}
+		return err
+	}
+
+	// A failed probe re-opens the circuit at once.
+	if err := probeRound(); err == nil {
+		t.Fatal("expected the failed probe to fail its call")
+	}
+	var open *CircuitOpenError
+	if _, err := client.ListProducts(ctx); !errors.As(err, &open) {
+		t.Fatalf("expected the circuit to re-open after a failed probe, got %v", err)
+	}
+
+	// A successful probe closes it.
+	healthy.Store(true)
+	if err := probeRound(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	go func() { release <- struct{}{} }()
+	if _, err := client.ListProducts(ctx); err != nil {
+		t.Fatalf("expected a closed circuit after a successful probe, got %v", err)
+	}
+}
@@ -0,0 +1,433 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultConcurrency is the number of files bulk downloads fetch at once when
+// DownloadOptions.Concurrency is unset.
+const defaultConcurrency = 4
+
+// DownloadOptions configures the bulk download methods.
+type DownloadOptions struct {
+	// Concurrency is the number of files downloaded at once (default: 4).
+	Concurrency int
+	// SkipExisting skips files already present at their destination with a
+	// size and checksum matching the catalog (see LocalFileMatches), making
+	// repeated mirror runs cheap.
+	SkipExisting bool
+	// CheckDiskSpace verifies before downloading that the destination file
+	// system has room for the files (by their catalog sizes) and otherwise
+	// fails with an InsufficientSpaceError.
+	CheckDiskSpace bool
+	// DeliveryFilter selects the deliveries DownloadProduct downloads; nil
+	// selects all of them.
+	DeliveryFilter func(*Delivery) bool
+	// Filter, if set, selects the files DownloadDelivery and DownloadProduct
+	// download by delivery date and name, file name and size. Files it
+	// excludes get no result. DownloadFiles downloads the listed files as is.
+	Filter *FileFilter
+	// OnFileComplete, if set, is called as soon as each file has finished,
+	// successfully, with an error, or skipped, so processing can start
+	// before the whole download is done. It is called from the download
+	// workers, possibly concurrently, and the next file of that worker waits
+	// until it returns. Files never started because ctx was cancelled are
+	// not reported.
+	OnFileComplete func(*FileResult)
+	// OnFileStart, if set, is called from the download workers right before
+	// a file's download starts (not for skipped or planned files).
+	OnFileStart func(*FileResult)
+	// Progress, if set, reports combined progress across all files of the
+	// call. The total is estimated from the catalog file sizes. Calls are
+	// serialized.
+	Progress func(bytesWritten, totalBytes int64)
+	// ManifestPath, if set, is where a JSON Manifest of the results is
+	// written once the download has finished (see WriteManifest).
+	ManifestPath string
+	// Sink, if set, receives the downloaded files instead of the local file
+	// system. Files are created under their destination path with forward
+	// slashes, so destDir becomes a name prefix (and may be empty).
+	// SkipExisting, CheckDiskSpace and ManifestPath need local files and
+	// cannot be combined with a Sink.
+	Sink Sink
+	// Layout, if set, is the path template of each file below the
+	// destination directory, with forward slashes and the placeholders
+	// {product_id}, {product_name}, {delivery_id}, {delivery_name},
+	// {delivery_date} (publication date, YYYY-MM-DD), {file_id} and
+	// {file_name}, e.g. "{product_name}/{delivery_date}/{file_name}".
+	// Placeholder values are sanitized for use as file names. The default
+	// depends on the method.
+	Layout string
+	// DryRun plans the download without fetching any file: every file that
+	// would be downloaded gets a result with Planned set (see PlannedBytes),
+	// SkipExisting and CheckDiskSpace still run, and no manifest is written.
+	DryRun bool
+}
+
+// FileRef identifies one catalog file.
+type FileRef struct {
+	ProductID  int `json:"productId"`
+	DeliveryID int `json:"deliveryId"`
+	FileID     int `json:"fileId"`
+}
+
+// FileResult is the outcome of downloading one file in a bulk download.
+type FileResult struct {
+	ProductID  int
+	DeliveryID int
+	File       *DeliveryFile
+	Path       string // local path of the downloaded file, or its Sink name
+	Bytes      int64  // bytes written
+	Skipped    bool   // already present locally (DownloadOptions.SkipExisting)
+	Planned    bool   // would be downloaded (DownloadOptions.DryRun)
+	Err        error  // nil on success
+
+	CompletedAt time.Time // when the download (or skip check) finished
+}
+
+// DownloadDelivery downloads every file of a delivery into destDir using a
+// pool of opts.Concurrency workers. Each file is written atomically (see
+// DownloadFileToPath) under its catalog file name.
+//
+// The returned results are in catalog order, one per file selected by
+// opts.Filter, and are returned even when some files fail; the error is then
+// the join of the per-file errors. A delivery that cannot be resolved, or fails the disk space check,
+// returns a nil slice and that error.
+func (c *Client) DownloadDelivery(ctx context.Context, productID, deliveryID int, destDir string, opts *DownloadOptions) ([]*FileResult, error) {
+	product, delivery, err := c.findDelivery(ctx, productID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	jobs := deliveryJobs(productID, product.Name, delivery, destDir, deliveryLayout, opts)
+	if err := preflight(destDir, jobs, opts); err != nil {
+		return nil, err
+	}
+	err = c.downloadBatch(ctx, jobs, opts)
+	return jobs, errors.Join(err, writeRequestedManifest(jobs, opts))
+}
+
+// DownloadProduct mirrors a product: every delivery accepted by
+// opts.DeliveryFilter (all of them by default) is downloaded into its own
+// subdirectory of destDir, named after the delivery:
+//
+//	destDir/<delivery name>/<file name>
+//
+// Files of all deliveries share one worker pool. Results and errors are
+// reported as for DownloadDelivery, in catalog order across deliveries.
+// Combine with SkipExisting to keep a mirror up to date by re-running it.
+func (c *Client) DownloadProduct(ctx context.Context, productID int, destDir string, opts *DownloadOptions) ([]*FileResult, error) {
+	product, err := c.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*FileResult
+	for _, d := range product.Deliveries {
+		if opts != nil && opts.DeliveryFilter != nil && !opts.DeliveryFilter(d) {
+			continue
+		}
+		jobs = append(jobs, deliveryJobs(productID, product.Name, d, destDir, productLayout, opts)...)
+	}
+	if err := preflight(destDir, jobs, opts); err != nil {
+		return nil, err
+	}
+	err = c.downloadBatch(ctx, jobs, opts)
+	return jobs, errors.Join(err, writeRequestedManifest(jobs, opts))
+}
+
+// DownloadFiles downloads an arbitrary set of files, possibly from several
+// products and deliveries, with one shared worker pool, retry policy and
+// progress. Files are resolved through the catalog (one GetProduct per
+// product) and stored as destDir/<product ID>/<delivery name>/<file name>.
+//
+// Results are in the order of refs. A reference that cannot be resolved gets
+// a result with only its IDs and a NotFoundError (or the product lookup
+// error); the other files are still downloaded. The error is the join of all
+// per-file errors.
+func (c *Client) DownloadFiles(ctx context.Context, refs []FileRef, destDir string, opts *DownloadOptions) ([]*FileResult, error) {
+	type lookup struct {
+		product *ProductWithDeliveries
+		err     error
+	}
+	products := make(map[int]*lookup)
+	jobs := make([]*FileResult, len(refs))
+	for i, ref := range refs {
+		job := &FileResult{ProductID: ref.ProductID, DeliveryID: ref.DeliveryID, File: &DeliveryFile{FileID: ref.FileID}}
+		jobs[i] = job
+
+		l, ok := products[ref.ProductID]
+		if !ok {
+			l = &lookup{}
+			l.product, l.err = c.GetProduct(ctx, ref.ProductID)
+			products[ref.ProductID] = l
+		}
+		if l.err != nil {
+			job.Err = l.err
+			continue
+		}
+		job.Err = &NotFoundError{Resource: "file", ID: fmt.Sprintf("%d/%d/%d", ref.ProductID, ref.DeliveryID, ref.FileID)}
+		for _, d := range l.product.Deliveries {
+			if d.DeliveryID != ref.DeliveryID {
+				continue
+			}
+			for _, f := range d.Files {
+				if f.FileID == ref.FileID {
+					job.File, job.Err = f, nil
+					path := expandLayout(layoutOf(opts, filesLayout), layoutFile{ref.ProductID, l.product.Name, d, f})
+					job.Path = filepath.Join(destDir, path)
+				}
+			}
+		}
+	}
+
+	var pending []*FileResult
+	for _, job := range jobs {
+		if job.Err == nil {
+			pending = append(pending, job)
+		}
+	}
+	if err := preflight(destDir, pending, opts); err != nil {
+		return nil, err
+	}
+	batchErr := c.downloadBatch(ctx, pending, opts)
+
+	var errs []error
+	for _, job := range jobs {
+		if job.Path == "" {
+			errs = append(errs, job.Err)
+		}
+	}
+	errs = append(errs, batchErr, writeRequestedManifest(jobs, opts))
+	return jobs, errors.Join(errs...)
+}
+
+// PlannedBytes returns the total catalog size of the results that a dry run
+// planned to download.
+func PlannedBytes(results []*FileResult) int64 {
+	var n int64
+	for _, r := range results {
+		if r.Planned {
+			n += parseFileSize(r.File.FileSize)
+		}
+	}
+	return n
+}
+
+// ProductSummary totals the bulk download results of one product.
+type ProductSummary struct {
+	ProductID  int
+	Downloaded int
+	Skipped    int
+	Planned    int
+	Failed     int
+	Bytes      int64 // bytes written
+}
+
+// Summarize totals results per product, ordered by product ID, e.g. for the
+// report of a Sync run over several products.
+func Summarize(results []*FileResult) []ProductSummary {
+	var summaries []ProductSummary
+	index := make(map[int]int)
+	for _, r := range results {
+		i, ok := index[r.ProductID]
+		if !ok {
+			i = len(summaries)
+			index[r.ProductID] = i
+			summaries = append(summaries, ProductSummary{ProductID: r.ProductID})
+		}
+		s := &summaries[i]
+		switch {
+		case r.Err != nil:
+			s.Failed++
+		case r.Skipped:
+			s.Skipped++
+		case r.Planned:
+			s.Planned++
+		default:
+			s.Downloaded++
+		}
+		s.Bytes += r.Bytes
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ProductID < summaries[j].ProductID })
+	return summaries
+}
+
+// deliveryJobs returns one pending result per file of a delivery selected by
+// opts.Filter, placed below destDir by opts.Layout or defaultLayout.
+func deliveryJobs(productID int, productName string, d *Delivery, destDir, defaultLayout string, opts *DownloadOptions) []*FileResult {
+	var filter *FileFilter
+	if opts != nil {
+		filter = opts.Filter
+	}
+	layout := layoutOf(opts, defaultLayout)
+	jobs := make([]*FileResult, 0, len(d.Files))
+	for _, f := range d.Files {
+		if !filter.Match(d, f) {
+			continue
+		}
+		jobs = append(jobs, &FileResult{
+			ProductID:  productID,
+			DeliveryID: d.DeliveryID,
+			File:       f,
+			Path:       filepath.Join(destDir, expandLayout(layout, layoutFile{productID, productName, d, f})),
+		})
+	}
+	return jobs
+}
+
+// layoutOf returns opts.Layout, or defaultLayout when it is unset.
+func layoutOf(opts *DownloadOptions, defaultLayout string) string {
+	if opts != nil && opts.Layout != "" {
+		return opts.Layout
+	}
+	return defaultLayout
+}
+
+// deliveryDirName returns a directory name for a delivery: its name with
+// characters unsafe in file names replaced, or its ID when the name is empty.
+func deliveryDirName(d *Delivery) string {
+	return sanitizePathElement(d.DeliveryName, strconv.Itoa(d.DeliveryID))
+}
+
+// preflight runs the checks requested in opts before a bulk download.
+func preflight(destDir string, jobs []*FileResult, opts *DownloadOptions) error {
+	if opts != nil && opts.Sink != nil && (opts.SkipExisting || opts.CheckDiskSpace || opts.ManifestPath != "") {
+		return errors.New("SkipExisting, CheckDiskSpace and ManifestPath cannot be used with a Sink")
+	}
+	if opts != nil && opts.Filter != nil {
+		if err := opts.Filter.validate(); err != nil {
+			return err
+		}
+	}
+	if opts != nil && opts.Layout != "" {
+		if err := validateLayout(opts.Layout); err != nil {
+			return err
+		}
+	}
+	if opts != nil && opts.CheckDiskSpace {
+		return checkDiskSpace(destDir, requiredSpace(jobs, opts.SkipExisting))
+	}
+	return nil
+}
+
+// findDelivery resolves a delivery (with its files) from the product catalog.
+func (c *Client) findDelivery(ctx context.Context, productID, deliveryID int, opts ...CallOption) (*ProductWithDeliveries, *Delivery, error) {
+	product, err := c.GetProduct(ctx, productID, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if d := product.Delivery(deliveryID); d != nil {
+		return product, d, nil
+	}
+	return nil, nil, &NotFoundError{
+		Resource: "delivery",
+		ID:       fmt.Sprintf("%d/%d", productID, deliveryID),
+	}
+}
+
+// downloadBatch downloads every job with a bounded worker pool, filling in
+// each job's Bytes and Err. It returns the join of the per-file errors.
+func (c *Client) downloadBatch(ctx context.Context, jobs []*FileResult, opts *DownloadOptions) error {
+	concurrency := defaultConcurrency
+	if opts != nil && opts.Concurrency > 0 {
+		concurrency = opts.Concurrency
+	}
+	skip := opts != nil && opts.SkipExisting
+	dryRun := opts != nil && opts.DryRun
+	var sink Sink = dirSink("")
+	if opts != nil && opts.Sink != nil {
+		sink = opts.Sink
+	}
+	complete := func(*FileResult) {}
+	if opts != nil && opts.OnFileComplete != nil {
+		complete = opts.OnFileComplete
+	}
+	start := func(*FileResult) {}
+	if opts != nil && opts.OnFileStart != nil {
+		start = opts.OnFileStart
+	}
+
+	var progress *segmentProgress
+	if opts != nil && opts.Progress != nil {
+		progress = &segmentProgress{fn: opts.Progress}
+		for _, job := range jobs {
+			progress.total += parseFileSize(job.File.FileSize)
+		}
+	}
+
+	queue := make(chan *FileResult)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(jobs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if skip {
+					// A file that cannot be checked is simply downloaded again.
+					if ok, _ := LocalFileMatches(job.Path, job.File); ok {
+						job.Skipped, job.CompletedAt = true, time.Now()
+						if progress != nil {
+							progress.add(parseFileSize(job.File.FileSize))
+						}
+						complete(job)
+						continue
+					}
+				}
+				if dryRun {
+					job.Planned, job.CompletedAt = true, time.Now()
+					complete(job)
+					continue
+				}
+				start(job)
+				var progressFn func(int64, int64)
+				if progress != nil {
+					var last int64
+					progressFn = func(bytesWritten, _ int64) {
+						progress.add(bytesWritten - last)
+						last = bytesWritten
+					}
+				}
+				job.Bytes, job.Err = c.downloadToSink(ctx, sink, job, progressFn)
+				job.CompletedAt = time.Now()
+				complete(job)
+			}
+		}()
+	}
+
+	for i, job := range jobs {
+		if ctx.Err() != nil {
+			// Stop scheduling; mark everything not yet started as cancelled.
+			for _, rest := range jobs[i:] {
+				rest.Err = ctx.Err()
+			}
+			break
+		}
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	var errs []error
+	for _, job := range jobs {
+		if job.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", job.File.FileName, job.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// downloadToSink downloads one job into sink under its path.
+func (c *Client) downloadToSink(ctx context.Context, sink Sink, job *FileResult, progressFn func(int64, int64)) (int64, error) {
+	w, err := sink.Create(ctx, filepath.ToSlash(job.Path), job.File)
+	if err != nil {
+		return 0, err
+	}
+	return c.downloadToSinkFile(ctx, job.ProductID, job.DeliveryID, job.File.FileID, w, job.File.FileSize, progressFn)
+}
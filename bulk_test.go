@@ -0,0 +1,468 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mirrorFile and mirrorDelivery describe the catalog served by newMirrorServer.
+type mirrorFile struct {
+	id      int
+	name    string
+	content string
+}
+
+type mirrorDelivery struct {
+	id        int
+	name      string
+	published time.Time
+	expiry    *time.Time
+	files     []mirrorFile
+}
+
+// mirrorStats counts what a mirror server was asked for.
+type mirrorStats struct {
+	downloads   int32 // download requests
+	inFlight    int32
+	maxInFlight int32
+}
+
+// sha1Hex returns the upper-case SHA-1 hex digest EPO uses as file checksum.
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+var mirrorDownloadPattern = regexp.MustCompile(`/products/(\d+)/delivery/(\d+)/file/(\d+)/download$`)
+
+// newMirrorServer serves a single product (id productID, name "Test product")
+// with the given deliveries: the product list, product details and Range-aware
+// file downloads. Checksums are SHA-1 and sizes exact byte counts.
+func newMirrorServer(t *testing.T, productID int, deliveries []mirrorDelivery) (*httptest.Server, *mirrorStats) {
+	t.Helper()
+	stats := &mirrorStats{}
+	contents := map[string]string{}
+	var wire []map[string]interface{}
+	for _, d := range deliveries {
+		files := []map[string]interface{}{}
+		for _, f := range d.files {
+			contents[fmt.Sprintf("%d/%d/%d", productID, d.id, f.id)] = f.content
+			files = append(files, map[string]interface{}{
+				"fileId":                  f.id,
+				"fileName":                f.name,
+				"fileSize":                strconv.Itoa(len(f.content)) + " B",
+				"fileChecksum":            sha1Hex(f.content),
+				"filePublicationDatetime": d.published.Format(time.RFC3339),
+			})
+		}
+		var expiry interface{}
+		if d.expiry != nil {
+			expiry = d.expiry.Format(time.RFC3339)
+		}
+		wire = append(wire, map[string]interface{}{
+			"deliveryId":                  d.id,
+			"deliveryName":                d.name,
+			"deliveryPublicationDatetime": d.published.Format(time.RFC3339),
+			"deliveryExpiryDatetime":      expiry,
+			"files":                       files,
+		})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m := mirrorDownloadPattern.FindStringSubmatch(r.URL.Path); m != nil {
+			n := atomic.AddInt32(&stats.inFlight, 1)
+			defer atomic.AddInt32(&stats.inFlight, -1)
+			for {
+				peak := atomic.LoadInt32(&stats.maxInFlight)
+				if n <= peak || atomic.CompareAndSwapInt32(&stats.maxInFlight, peak, n) {
+					break
+				}
+			}
+			atomic.AddInt32(&stats.downloads, 1)
+			content, ok := contents[m[1]+"/"+m[2]+"/"+m[3]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+			w.Header().Set("ETag", `"`+m[3]+`"`)
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader([]byte(content)))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/products/"):
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": productID, "name": "Test product", "description": "test"},
+			})
+		case strings.HasSuffix(r.URL.Path, "/products/"+strconv.Itoa(productID)):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": productID, "name": "Test product", "description": "test", "deliveries": wire,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv, stats
+}
+
+// weeklyDelivery returns a delivery with n small files.
+func weeklyDelivery(id int, name string, published time.Time, n int) mirrorDelivery {
+	d := mirrorDelivery{id: id, name: name, published: published}
+	for i := 1; i <= n; i++ {
+		d.files = append(d.files, mirrorFile{
+			id:      id*100 + i,
+			name:    fmt.Sprintf("%s_%02d.zip", strings.ReplaceAll(name, "-", ""), i),
+			content: fmt.Sprintf("delivery %d file %d", id, i),
+		})
+	}
+	return d
+}
+
+// TestDownloadDelivery verifies all files of a delivery are downloaded with
+// bounded concurrency and per-file results in catalog order.
+func TestDownloadDelivery(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, stats := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", published, 6)})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	results, err := client.DownloadDelivery(context.Background(), 3, 7, dir, &DownloadOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("DownloadDelivery: %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("expected 6 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Errorf("file %d: %v", i, r.Err)
+		}
+		if r.File.FileID != 701+i {
+			t.Errorf("result %d is file %d, want catalog order", i, r.File.FileID)
+		}
+		got, _ := os.ReadFile(filepath.Join(dir, r.File.FileName))
+		if want := fmt.Sprintf("delivery 7 file %d", i+1); string(got) != want || r.Bytes != int64(len(want)) {
+			t.Errorf("file %s = %q (%d bytes), want %q", r.File.FileName, got, r.Bytes, want)
+		}
+	}
+	if peak := atomic.LoadInt32(&stats.maxInFlight); peak > 2 {
+		t.Errorf("expected at most 2 concurrent downloads, saw %d", peak)
+	}
+}
+
+// TestDownloadDeliveryNotFound verifies an unknown delivery is a typed error.
+func TestDownloadDeliveryNotFound(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer, _ := newMirrorServer(t, 3, nil)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	_, err := client.DownloadDelivery(context.Background(), 3, 99, t.TempDir(), nil)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.Resource != "delivery" {
+		t.Fatalf("expected delivery NotFoundError, got %v", err)
+	}
+}
+
+// TestDownloadDeliverySkipExisting verifies a re-run only downloads files that
+// are missing or do not match the catalog checksum.
+func TestDownloadDeliverySkipExisting(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, stats := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", published, 3)})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	if _, err := client.DownloadDelivery(context.Background(), 3, 7, dir, nil); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	// Corrupt one file (same size) and delete another.
+	if err := os.WriteFile(filepath.Join(dir, "20241015_01.zip"), []byte("delivery 7 file X"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "20241015_02.zip")); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&stats.downloads, 0)
+
+	results, err := client.DownloadDelivery(context.Background(), 3, 7, dir, &DownloadOptions{SkipExisting: true})
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	wantSkipped := []bool{false, false, true}
+	for i, r := range results {
+		if r.Skipped != wantSkipped[i] {
+			t.Errorf("%s: Skipped = %v, want %v", r.File.FileName, r.Skipped, wantSkipped[i])
+		}
+	}
+	if n := atomic.LoadInt32(&stats.downloads); n != 2 {
+		t.Errorf("expected 2 downloads on re-run, got %d", n)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "20241015_01.zip"))
+	if string(got) != "delivery 7 file 1" {
+		t.Errorf("corrupt file not replaced, got %q", got)
+	}
+}
+
+// TestDownloadProduct verifies filtered deliveries are mirrored into one
+// directory per delivery.
+func TestDownloadProduct(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	oct15 := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", oct15, 2),
+		weeklyDelivery(8, "2024-10-22", oct15.AddDate(0, 0, 7), 1),
+		weeklyDelivery(9, "2024-10-29", oct15.AddDate(0, 0, 14), 1),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	results, err := client.DownloadProduct(context.Background(), 3, dir, &DownloadOptions{
+		DeliveryFilter: func(d *Delivery) bool { return d.DeliveryID != 9 },
+	})
+	if err != nil {
+		t.Fatalf("DownloadProduct: %v", err)
+	}
+	want := []string{
+		filepath.Join("2024-10-15", "20241015_01.zip"),
+		filepath.Join("2024-10-15", "20241015_02.zip"),
+		filepath.Join("2024-10-22", "20241022_01.zip"),
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.File.FileName, r.Err)
+		}
+		if r.Path != filepath.Join(dir, want[i]) {
+			t.Errorf("result %d path = %s, want %s", i, r.Path, want[i])
+		}
+		if _, err := os.Stat(r.Path); err != nil {
+			t.Errorf("missing %s: %v", want[i], err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024-10-29")); !os.IsNotExist(err) {
+		t.Error("filtered delivery was downloaded")
+	}
+}
+
+func TestDeliveryDirName(t *testing.T) {
+	tests := map[string]string{
+		"2024-10-15":   "2024-10-15",
+		"Week 42/2024": "Week 42_2024",
+		`a:b*c`:        "a_b_c",
+		"  ":           "12",
+		"..":           "12",
+	}
+	for name, want := range tests {
+		if got := deliveryDirName(&Delivery{DeliveryID: 12, DeliveryName: name}); got != want {
+			t.Errorf("deliveryDirName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestDownloadDeliveryOnFileComplete verifies the hook fires once per file,
+// after the file is in place.
+func TestDownloadDeliveryOnFileComplete(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	delivery := weeklyDelivery(7, "2024-10-15", published, 3)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{delivery})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	var (
+		mu        sync.Mutex
+		completed = make(map[string]error)
+	)
+	dir := t.TempDir()
+	_, err := client.DownloadDelivery(context.Background(), 3, 7, dir, &DownloadOptions{
+		Concurrency: 2,
+		OnFileComplete: func(r *FileResult) {
+			if r.Err == nil {
+				if _, err := os.Stat(r.Path); err != nil {
+					t.Errorf("%s not in place when hook fired: %v", r.File.FileName, err)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if _, dup := completed[r.File.FileName]; dup {
+				t.Errorf("hook fired twice for %s", r.File.FileName)
+			}
+			completed[r.File.FileName] = r.Err
+		},
+	})
+	if err != nil {
+		t.Fatalf("DownloadDelivery: %v", err)
+	}
+	if len(completed) != len(delivery.files) {
+		t.Errorf("hook fired for %d files, want %d", len(completed), len(delivery.files))
+	}
+}
+
+// TestDownloadFiles verifies cherry-picked files from several products are
+// downloaded with combined progress, and unknown references are reported
+// without stopping the rest.
+func TestDownloadFiles(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", published, 3),
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 2),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	var mu sync.Mutex
+	var lastWritten, lastTotal int64
+	dir := t.TempDir()
+	refs := []FileRef{
+		{ProductID: 3, DeliveryID: 8, FileID: 802},
+		{ProductID: 3, DeliveryID: 7, FileID: 799},
+		{ProductID: 3, DeliveryID: 7, FileID: 701},
+	}
+	results, err := client.DownloadFiles(context.Background(), refs, dir, &DownloadOptions{
+		Progress: func(written, total int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			lastWritten, lastTotal = written, total
+		},
+	})
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.ID != "3/7/799" {
+		t.Fatalf("expected NotFoundError for 3/7/799, got %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[1].Err == nil || results[1].Path != "" {
+		t.Errorf("unknown ref should fail without a path: %+v", results[1])
+	}
+	for i, want := range map[int]string{
+		0: filepath.Join(dir, "3", "2024-10-22", "20241022_02.zip"),
+		2: filepath.Join(dir, "3", "2024-10-15", "20241015_01.zip"),
+	} {
+		if r := results[i]; r.Err != nil || r.Path != want {
+			t.Errorf("result %d: path %s, err %v; want %s", i, r.Path, r.Err, want)
+		}
+	}
+	wantTotal := results[0].Bytes + results[2].Bytes
+	if lastWritten != wantTotal || lastTotal != wantTotal {
+		t.Errorf("progress ended at %d/%d, want %d/%d", lastWritten, lastTotal, wantTotal, wantTotal)
+	}
+}
+
+// TestDownloadDeliveryManifest verifies the manifest records catalog and
+// computed checksums for every file.
+func TestDownloadDeliveryManifest(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", published, 2)})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "manifest.json")
+	if _, err := client.DownloadDelivery(context.Background(), 3, 7, dir, &DownloadOptions{ManifestPath: manifestPath}); err != nil {
+		t.Fatalf("DownloadDelivery: %v", err)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if len(m.Files) != 2 || m.GeneratedAt.IsZero() {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+	for i, e := range m.Files {
+		content := fmt.Sprintf("delivery 7 file %d", i+1)
+		if e.FileID != 701+i || e.DeliveryID != 7 || e.ProductID != 3 {
+			t.Errorf("entry %d has IDs %d/%d/%d", i, e.ProductID, e.DeliveryID, e.FileID)
+		}
+		if e.Checksum != sha1Hex(content) || e.Checksum != e.CatalogChecksum || e.ChecksumType != "sha1" {
+			t.Errorf("entry %d checksums: computed %s (%s), catalog %s", i, e.Checksum, e.ChecksumType, e.CatalogChecksum)
+		}
+		if e.Size != int64(len(content)) || e.Path != filepath.Join(dir, e.FileName) || e.CompletedAt.IsZero() {
+			t.Errorf("entry %d: %+v", i, e)
+		}
+	}
+}
+
+// TestDownloadDeliveryDryRun verifies a dry run plans the files that are not
+// present locally without downloading anything.
+func TestDownloadDeliveryDryRun(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer, stats := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", time.Now(), 3)})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "20241015_02.zip"), []byte("delivery 7 file 2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(dir, "manifest.json")
+	results, err := client.DownloadDelivery(context.Background(), 3, 7, dir, &DownloadOptions{
+		DryRun: true, SkipExisting: true, ManifestPath: manifest,
+	})
+	if err != nil {
+		t.Fatalf("DownloadDelivery: %v", err)
+	}
+	if n := atomic.LoadInt32(&stats.downloads); n != 0 {
+		t.Errorf("dry run downloaded %d files", n)
+	}
+	if !results[0].Planned || !results[1].Skipped || results[1].Planned || !results[2].Planned {
+		t.Errorf("unexpected plan: %+v %+v %+v", results[0], results[1], results[2])
+	}
+	if got, want := PlannedBytes(results), int64(len("delivery 7 file 1")+len("delivery 7 file 3")); got != want {
+		t.Errorf("PlannedBytes = %d, want %d", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "20241015_01.zip")); !os.IsNotExist(err) {
+		t.Errorf("dry run created a file: %v", err)
+	}
+	if _, err := os.Stat(manifest); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote a manifest: %v", err)
+	}
+}
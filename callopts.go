@@ -0,0 +1,105 @@
+package bdds
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CallOption overrides the client configuration for a single call, e.g. to
+// give a catalog lookup a short deadline and a large download a long one.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout   time.Duration
+	header    http.Header
+	noRetry   bool
+	noCache   bool
+	anonymous bool
+
+	requestID string // X-Request-ID of the call's requests
+}
+
+// WithTimeout bounds the whole call, including retries and, for downloads,
+// reading the body.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) { o.timeout = d }
+}
+
+// WithHeader adds a header to the requests of the call, e.g. a tracing or
+// correlation ID. It replaces a header of the same name set by the client.
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithNoRetry makes the call fail on its first error instead of retrying
+// transient failures as configured by Config.MaxRetries.
+func WithNoRetry() CallOption {
+	return func(o *callOptions) { o.noRetry = true }
+}
+
+// WithoutCredentials sends the call's requests without authentication, as a
+// client without credentials would, e.g. to check what is available for
+// free.
+func WithoutCredentials() CallOption {
+	return func(o *callOptions) { o.anonymous = true }
+}
+
+// anonymous reports whether ctx is a call made WithoutCredentials.
+func anonymous(ctx context.Context) bool {
+	o := callOptionsFrom(ctx)
+	return o != nil && o.anonymous
+}
+
+type callOptionsKey struct{}
+
+// withCallOptions returns ctx carrying opts, on top of the options of an
+// enclosing call, and bounded by the WithTimeout deadline. A call outside
+// any other gets a request ID for all its requests, unless it sets one with
+// WithRequestID. The caller must call cancel once the call is done.
+func withCallOptions(ctx context.Context, opts []CallOption) (_ context.Context, cancel context.CancelFunc) {
+	outer := callOptionsFrom(ctx)
+	if len(opts) == 0 && outer != nil {
+		return ctx, func() {}
+	}
+	o := &callOptions{}
+	if outer != nil {
+		*o = *outer
+		o.header = outer.header.Clone()
+	}
+	o.timeout = 0 // an enclosing deadline is already on ctx
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.requestID == "" {
+		o.requestID = newRequestID()
+	}
+	ctx = context.WithValue(ctx, callOptionsKey{}, o)
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return ctx, func() {}
+}
+
+// callOptionsFrom returns the call options on ctx, or nil.
+func callOptionsFrom(ctx context.Context) *callOptions {
+	o, _ := ctx.Value(callOptionsKey{}).(*callOptions)
+	return o
+}
+
+// cancelOnClose releases a call's context when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
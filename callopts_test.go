@@ -0,0 +1,114 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallOptions(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	var calls int32
+	var requestID atomic.Value
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		requestID.Store(r.Header.Get("X-Request-Id"))
+		switch r.Header.Get("X-Test") {
+		case "fail":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+		}
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+
+	t.Run("WithNoRetry", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		_, err := client.ListProducts(ctx, WithHeader("X-Test", "fail"), WithNoRetry())
+		var statusErr *statusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected a 503 error, got %v", err)
+		}
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Errorf("%d requests, want 1", n)
+		}
+
+		atomic.StoreInt32(&calls, 0)
+		_, _ = client.ListProducts(ctx, WithHeader("X-Test", "fail"))
+		if n := atomic.LoadInt32(&calls); n != 4 {
+			t.Errorf("%d requests without WithNoRetry, want 4", n)
+		}
+	})
+
+	t.Run("WithTimeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := client.GetProduct(ctx, 3, WithHeader("X-Test", "slow"), WithTimeout(50*time.Millisecond))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected a deadline error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("call took %s despite a 50ms timeout", elapsed)
+		}
+	})
+
+	t.Run("WithHeader", func(t *testing.T) {
+		if _, err := client.ListProducts(ctx, WithHeader("X-Request-Id", "abc-123")); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := requestID.Load().(string); got != "abc-123" {
+			t.Errorf("X-Request-Id = %q", got)
+		}
+	})
+}
+
+func TestDownloadFileStreamTimeout(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1000")
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 10))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	body, err := client.DownloadFileStream(context.Background(), 3, 1, 1, WithTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = body.Close() }()
+	if _, err := io.ReadAll(body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("reading past the deadline: %v", err)
+	}
+}
+
+func TestWithCallOptionsNested(t *testing.T) {
+	ctx, cancel := withCallOptions(context.Background(), []CallOption{WithHeader("A", "1"), WithNoRetry()})
+	defer cancel()
+	inner, cancelInner := withCallOptions(ctx, []CallOption{WithHeader("B", "2")})
+	defer cancelInner()
+	o := callOptionsFrom(inner)
+	if !o.noRetry || o.header.Get("A") != "1" || o.header.Get("B") != "2" {
+		t.Errorf("nested options = %+v", o)
+	}
+	if callOptionsFrom(ctx).header.Get("B") != "" {
+		t.Error("inner options leaked into the outer call")
+	}
+}
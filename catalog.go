@@ -0,0 +1,86 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Catalog is an offline copy of the products, deliveries and files the
+// account can read, for browsing, planning and diffing (DiffDeliveries)
+// without API access.
+type Catalog struct {
+	FetchedAt time.Time                `json:"fetchedAt"`
+	Products  []*ProductWithDeliveries `json:"products"`
+}
+
+// Product returns the product with the given ID, or nil if the catalog does
+// not list it.
+func (c *Catalog) Product(productID int) *ProductWithDeliveries {
+	for _, p := range c.Products {
+		if p.ID == productID {
+			return p
+		}
+	}
+	return nil
+}
+
+// Write encodes the catalog as JSON, the format LoadCatalog reads.
+func (c *Catalog) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	return nil
+}
+
+// LoadCatalog reads a catalog written by SaveCatalog or Catalog.Write.
+func LoadCatalog(r io.Reader) (*Catalog, error) {
+	var c Catalog
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog: %w", err)
+	}
+	return &c, nil
+}
+
+// FetchCatalog reads the details of every product in the catalog, in
+// catalog order. Products the account is not entitled to are left out, as
+// ProbeAccess would report them; other failures abort with an error. It
+// makes one request per product.
+func (c *Client) FetchCatalog(ctx context.Context) (*Catalog, error) {
+	products, err := c.ListProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	catalog := &Catalog{FetchedAt: time.Now().UTC()}
+	seen := make(map[int]bool, len(products))
+	for _, p := range products {
+		// The catalog lists some products in more than one section.
+		if seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		product, err := c.GetProduct(ctx, p.ID)
+		if isEntitlementError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		catalog.Products = append(catalog.Products, product)
+	}
+	return catalog, nil
+}
+
+// SaveCatalog fetches the catalog (see FetchCatalog) and writes it to w as
+// JSON, e.g. to cache it on disk for LoadCatalog.
+func (c *Client) SaveCatalog(ctx context.Context, w io.Writer) error {
+	catalog, err := c.FetchCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	return catalog.Write(w)
+}
@@ -0,0 +1,104 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadCatalog(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	mirror, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", published, 2),
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 1),
+	})
+	defer mirror.Close()
+	// Product 4 is listed twice but not subscribed.
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/products/"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"id":4,"name":"Other"},{"id":3,"name":"Test product"},{"id":4,"name":"Other"}]`))
+		case strings.HasSuffix(r.URL.Path, "/products/4"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			mirror.Config.Handler.ServeHTTP(w, r)
+		}
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	var buf bytes.Buffer
+	if err := client.SaveCatalog(context.Background(), &buf); err != nil {
+		t.Fatalf("SaveCatalog: %v", err)
+	}
+	catalog, err := LoadCatalog(&buf)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	if len(catalog.Products) != 1 || catalog.FetchedAt.IsZero() {
+		t.Fatalf("catalog = %+v, want only product 3", catalog)
+	}
+	if catalog.Product(4) != nil {
+		t.Error("catalog lists the forbidden product")
+	}
+	product := catalog.Product(3)
+	if product == nil || product.Name != "Test product" || len(product.Deliveries) != 2 {
+		t.Fatalf("product 3 = %+v", product)
+	}
+	if f := product.Deliveries[0].Files[1]; f.FileName != "20241015_02.zip" || f.FileChecksum != sha1Hex("delivery 7 file 2") {
+		t.Errorf("file = %+v", f)
+	}
+
+	live, err := client.GetProduct(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := DiffDeliveries(product, live); !diff.Empty() {
+		t.Errorf("loaded catalog differs from the live one: %+v", diff)
+	}
+}
+
+func TestLoadCatalogInvalid(t *testing.T) {
+	if _, err := LoadCatalog(strings.NewReader("{")); err == nil {
+		t.Error("expected an error for a truncated catalog")
+	}
+}
+
+// TestCatalogJSONNames checks the JSON field names of the catalog types, and
+// that catalogs saved before they had tags, with Go field names, still load.
+func TestCatalogJSONNames(t *testing.T) {
+	expiry := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	catalog := &Catalog{Products: []*ProductWithDeliveries{{ID: 3, Name: "p", Deliveries: []*Delivery{{
+		DeliveryID: 7, DeliveryName: "d", DeliveryExpiryDatetime: &expiry,
+		Files: []*DeliveryFile{{FileID: 71, FileName: "a.zip", FileSize: "1 kB", FileChecksum: "AA"}},
+	}}}}}
+	var buf bytes.Buffer
+	if err := catalog.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"fetchedAt"`, `"products"`, `"deliveries"`, `"deliveryId"`, `"deliveryExpiryDatetime"`, `"files"`, `"fileId"`, `"fileChecksum"`} {
+		if !strings.Contains(buf.String(), key) {
+			t.Errorf("catalog JSON lacks %s", key)
+		}
+	}
+
+	old := `{"FetchedAt":"2024-10-15T10:00:00Z","Products":[{"ID":3,"Name":"p","Description":"",
+		"Deliveries":[{"DeliveryID":7,"DeliveryName":"d","DeliveryPublicationDatetime":"2024-10-15T10:00:00Z",
+		"DeliveryExpiryDatetime":"2025-10-15T00:00:00Z","Files":[{"FileID":71,"FileName":"a.zip","FileSize":"1 kB",
+		"FileChecksum":"AA","FilePublicationDatetime":"2024-10-15T10:00:00Z"}]}]}]}`
+	loaded, err := LoadCatalog(strings.NewReader(old))
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	d := loaded.Product(3).Delivery(7)
+	if d == nil || d.DeliveryExpiryDatetime == nil || len(d.Files) != 1 || d.Files[0].FileChecksum != "AA" || loaded.FetchedAt.IsZero() {
+		t.Errorf("old catalog loaded as %+v", loaded)
+	}
+}
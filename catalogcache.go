@@ -0,0 +1,111 @@
+package bdds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// defaultCatalogCacheTTL is how long catalog responses are cached when
+// Config.CatalogCacheTTL is unset.
+const defaultCatalogCacheTTL = 5 * time.Minute
+
+// CatalogCache keeps the responses of ListProducts and GetProduct for
+// Config.CatalogCacheTTL, so helpers that call them repeatedly, such as
+// GetProductByName and GetLatestDelivery in a loop, don't each fetch the
+// catalog again. Entries are the raw JSON responses; key identifies the
+// endpoint and account. Load returns nil when it holds no fresh entry. The
+// client treats the cache as best effort: failures fall back to the API.
+type CatalogCache interface {
+	Load(ctx context.Context, key string) ([]byte, error)
+	Store(ctx context.Context, key string, body []byte, ttl time.Duration) error
+}
+
+// MemoryCatalogCache is a CatalogCache in memory, which clients may share.
+type MemoryCatalogCache struct {
+	mu      sync.Mutex
+	entries map[string]catalogCacheEntry
+}
+
+type catalogCacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewMemoryCatalogCache returns an empty in-memory catalog cache.
+func NewMemoryCatalogCache() *MemoryCatalogCache {
+	return &MemoryCatalogCache{entries: make(map[string]catalogCacheEntry)}
+}
+
+// Load returns the entry stored under key, or nil if there is none or it
+// expired.
+func (m *MemoryCatalogCache) Load(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if !time.Now().Before(e.expires) {
+		delete(m.entries, key)
+		return nil, nil
+	}
+	return e.body, nil
+}
+
+// Store keeps body under key for ttl.
+func (m *MemoryCatalogCache) Store(_ context.Context, key string, body []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = catalogCacheEntry{body: body, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Clear removes all entries, e.g. once a new delivery is known to be out.
+func (m *MemoryCatalogCache) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.entries)
+}
+
+// WithNoCache makes ListProducts and GetProduct fetch the catalog from the
+// API instead of Config.CatalogCache. The response is still cached.
+func WithNoCache() CallOption {
+	return func(o *callOptions) { o.noCache = true }
+}
+
+// catalogCacheKey identifies a catalog endpoint of an account in a
+// CatalogCache without exposing the user name. Calls made
+// WithoutCredentials have keys of their own.
+func (c *Client) catalogCacheKey(ctx context.Context, path string) string {
+	username := c.accountFor(path).username
+	if anonymous(ctx) {
+		username = ""
+	}
+	sum := sha256.Sum256([]byte("bdds-catalog:" + apiURL(c.config) + path + "\x00" + username))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCatalog returns the cached response of a catalog endpoint, or nil.
+func (c *Client) loadCatalog(ctx context.Context, path string) []byte {
+	if c.config.CatalogCache == nil {
+		return nil
+	}
+	if o := callOptionsFrom(ctx); o != nil && o.noCache {
+		return nil
+	}
+	body, err := c.config.CatalogCache.Load(ctx, c.catalogCacheKey(ctx, path))
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+// storeCatalog caches the response of a catalog endpoint.
+func (c *Client) storeCatalog(ctx context.Context, path string, body []byte) {
+	if c.config.CatalogCache != nil {
+		_ = c.config.CatalogCache.Store(ctx, c.catalogCacheKey(ctx, path), body, c.config.CatalogCacheTTL)
+	}
+}
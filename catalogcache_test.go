@@ -0,0 +1,113 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCatalogCache(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	var listCalls, productCalls int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/products/"):
+			atomic.AddInt32(&listCalls, 1)
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 3, "name": "EP DocDB front file", "description": "d"}})
+		case strings.HasSuffix(r.URL.Path, "/products/3"):
+			atomic.AddInt32(&productCalls, 1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": 3, "name": "EP DocDB front file", "description": "d",
+				"deliveries": []map[string]interface{}{{
+					"deliveryId": 7, "deliveryName": "2024-10-15",
+					"deliveryPublicationDatetime": "2024-10-15T00:00:00Z", "files": []interface{}{},
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	client.config.CatalogCache = NewMemoryCatalogCache()
+	client.config.CatalogCacheTTL = time.Hour
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := client.GetProductByName(ctx, "EP DocDB front file"); err != nil {
+			t.Fatalf("GetProductByName: %v", err)
+		}
+		d, err := client.GetLatestDelivery(ctx, 3)
+		if err != nil {
+			t.Fatalf("GetLatestDelivery: %v", err)
+		}
+		if d.DeliveryID != 7 {
+			t.Fatalf("delivery %d, want 7", d.DeliveryID)
+		}
+	}
+	if n := atomic.LoadInt32(&listCalls); n != 1 {
+		t.Errorf("%d product list requests, want 1", n)
+	}
+	if n := atomic.LoadInt32(&productCalls); n != 1 {
+		t.Errorf("%d product requests, want 1", n)
+	}
+
+	t.Run("WithNoCache", func(t *testing.T) {
+		if _, err := client.GetProduct(ctx, 3, WithNoCache()); err != nil {
+			t.Fatalf("GetProduct: %v", err)
+		}
+		if n := atomic.LoadInt32(&productCalls); n != 2 {
+			t.Errorf("%d product requests, want 2", n)
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		client.config.CatalogCacheTTL = time.Nanosecond
+		for range 2 {
+			if _, err := client.ListProducts(ctx, WithNoCache()); err != nil {
+				t.Fatalf("ListProducts: %v", err)
+			}
+			time.Sleep(time.Millisecond)
+			if _, err := client.ListProducts(ctx); err != nil {
+				t.Fatalf("ListProducts: %v", err)
+			}
+		}
+		if n := atomic.LoadInt32(&listCalls); n != 5 {
+			t.Errorf("%d product list requests, want 5", n)
+		}
+	})
+
+	t.Run("NoCache", func(t *testing.T) {
+		uncached := newTestClient(t, apiServer.URL, authServer.URL)
+		before := atomic.LoadInt32(&listCalls)
+		for range 2 {
+			if _, err := uncached.ListProducts(ctx); err != nil {
+				t.Fatalf("ListProducts: %v", err)
+			}
+		}
+		if n := atomic.LoadInt32(&listCalls) - before; n != 2 {
+			t.Errorf("%d product list requests without a cache, want 2", n)
+		}
+	})
+}
+
+func TestMemoryCatalogCacheClear(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCatalogCache()
+	_ = cache.Store(ctx, "k", []byte("v"), time.Hour)
+	if body, _ := cache.Load(ctx, "k"); string(body) != "v" {
+		t.Fatalf("Load = %q, want v", body)
+	}
+	cache.Clear()
+	if body, _ := cache.Load(ctx, "k"); body != nil {
+		t.Errorf("Load after Clear = %q, want nil", body)
+	}
+}
@@ -0,0 +1,107 @@
+package bdds
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// newChecksumHash returns the hash matching a hex checksum by its length,
+// with its name: the catalog publishes SHA-1 (40 hex digits); MD5 and SHA-256
+// are recognized too. It returns nil for an empty or unrecognized checksum.
+func newChecksumHash(checksum string) (hash.Hash, string) {
+	if _, err := hex.DecodeString(checksum); err != nil {
+		return nil, ""
+	}
+	switch len(checksum) {
+	case 32:
+		return md5.New(), "md5"
+	case 40:
+		return sha1.New(), "sha1"
+	case 64:
+		return sha256.New(), "sha256"
+	}
+	return nil, ""
+}
+
+// fileChecksum hashes the file at path with h and returns the hex digest.
+func fileChecksum(path string, h hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileSizeMatches reports whether size is consistent with a rounded catalog
+// size such as "406.6 kB", allowing for the rounding of its last digit. An
+// unparseable catalog size matches any size.
+func fileSizeMatches(size int64, catalog string) bool {
+	fields := strings.Fields(strings.TrimSpace(catalog))
+	if len(fields) != 2 {
+		return true
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	mult, ok := fileSizeUnits[strings.ToUpper(fields[1])]
+	if err != nil || !ok {
+		return true
+	}
+	decimals := 0
+	if _, frac, found := strings.Cut(fields[0], "."); found {
+		decimals = len(frac)
+	}
+	tolerance := mult * 0.5 * math.Pow10(-decimals)
+	return math.Abs(float64(size)-value*mult) <= tolerance
+}
+
+// LocalFileMatches reports whether the file at path is a complete copy of the
+// catalog file: its size must agree with the catalog size and, when the
+// catalog publishes a checksum, its content must hash to it. A missing file
+// reports false without error.
+func LocalFileMatches(path string, file *DeliveryFile) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	mismatch, err := verifyLocalFile(path, info, file)
+	return mismatch == "" && err == nil, err
+}
+
+// verifyLocalFile compares an existing local file with its catalog entry and
+// describes the first mismatch, or returns "" when it matches.
+func verifyLocalFile(path string, info os.FileInfo, file *DeliveryFile) (string, error) {
+	if !info.Mode().IsRegular() {
+		return "not a regular file", nil
+	}
+	if !fileSizeMatches(info.Size(), file.FileSize) {
+		return fmt.Sprintf("size %d bytes does not match catalog size %s", info.Size(), file.FileSize), nil
+	}
+	h, _ := newChecksumHash(file.FileChecksum)
+	if h == nil {
+		return "", nil
+	}
+	sum, err := fileChecksum(path, h)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(sum, file.FileChecksum) {
+		return fmt.Sprintf("checksum %s does not match catalog checksum %s", sum, strings.ToUpper(file.FileChecksum)), nil
+	}
+	return "", nil
+}
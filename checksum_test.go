@@ -0,0 +1,61 @@
+package bdds
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSizeMatches(t *testing.T) {
+	tests := []struct {
+		size    int64
+		catalog string
+		want    bool
+	}{
+		{406624, "406.6 kB", true},
+		{406700, "406.6 kB", false},
+		{17, "17 B", true},
+		{18, "17 B", false},
+		{1_449_000_000, "1.45 GB", true},
+		{1_400_000_000, "1.45 GB", false},
+		{12345, "", true},
+		{12345, "unknown", true},
+	}
+	for _, tt := range tests {
+		if got := fileSizeMatches(tt.size, tt.catalog); got != tt.want {
+			t.Errorf("fileSizeMatches(%d, %q) = %v, want %v", tt.size, tt.catalog, got, tt.want)
+		}
+	}
+}
+
+func TestLocalFileMatches(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.zip")
+	content := "delivery 1 file 1"
+	file := &DeliveryFile{FileName: "file.zip", FileSize: "17 B", FileChecksum: sha1Hex(content)}
+
+	if ok, err := LocalFileMatches(path, file); ok || err != nil {
+		t.Fatalf("missing file: got %v, %v; want false, nil", ok, err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := LocalFileMatches(path, file); !ok || err != nil {
+		t.Errorf("matching file: got %v, %v; want true, nil", ok, err)
+	}
+
+	// Same size, different content: only the checksum catches it.
+	if err := os.WriteFile(path, []byte("delivery 1 file 2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := LocalFileMatches(path, file); ok {
+		t.Error("expected checksum mismatch to report false")
+	}
+
+	// Without a checksum the size decides.
+	noSum := &DeliveryFile{FileName: "file.zip", FileSize: "17 B"}
+	if ok, _ := LocalFileMatches(path, noSum); !ok {
+		t.Error("expected size-only match without a checksum")
+	}
+}
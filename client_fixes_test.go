@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -43,16 +44,13 @@ func newTestClient(t *testing.T, apiURL, authURL string) *Client {
 		MaxRetries: 3,
 		RetryDelay: time.Millisecond,
 		Timeout:    5 * time.Second,
-	})
-	if err != nil {
-		t.Fatalf("NewClient: %v", err)
-	}
-	client.httpClient = &http.Client{
 		Transport: &testTransport{
 			authURL: authURL + "/oauth2/aus3up3nz0N133c0V417/v1/token",
 			rt:      http.DefaultTransport,
 		},
-		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
 	}
 	return client
 }
@@ -80,7 +78,11 @@ func TestConcurrentTokenAccess(t *testing.T) {
 			if _, err := client.ListProducts(ctx); err != nil {
 				t.Errorf("ListProducts: %v", err)
 			}
-			client.clearToken()
+			token, err := client.ensureValidToken(ctx, client.account)
+			if err != nil {
+				t.Errorf("ensureValidToken: %v", err)
+			}
+			client.clearToken(token)
 		}()
 	}
 	wg.Wait()
@@ -260,6 +262,46 @@ func TestServerErrorRetried(t *testing.T) {
 	}
 }
 
+// TestOnRetry verifies OnRetry sees each failed attempt and the delay
+// before the next one.
+func TestOnRetry(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	var apiCalls int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&apiCalls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+	}))
+	defer apiServer.Close()
+
+	var attempts []int
+	var delays []time.Duration
+	client, err := newTestClient(t, apiServer.URL, authServer.URL).Clone(&ClientOverrides{
+		OnRetry: func(attempt int, err error, nextDelay time.Duration) {
+			var statusErr *statusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("attempt %d: error %v", attempt, err)
+			}
+			attempts = append(attempts, attempt)
+			delays = append(delays, nextDelay)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if fmt.Sprint(attempts, delays) != "[1 2] [1ms 2ms]" {
+		t.Errorf("OnRetry calls: attempts %v, delays %v", attempts, delays)
+	}
+}
+
 // TestRateLimitHandling verifies 429 maps to RateLimitError, honors Retry-After,
 // and is retried.
 func TestRateLimitHandling(t *testing.T) {
@@ -451,6 +493,109 @@ func TestDownloadPartialWriteNonSeekableFailsFast(t *testing.T) {
 	}
 }
 
+// newResumableDownloadServer returns an API server that advertises byte-range
+// support and drops the connection after partial bytes on the first request.
+// Later requests honour Range when honourRange is set, else resend the file.
+// The Range header of every request is recorded.
+func newResumableDownloadServer(t *testing.T, content []byte, partial int, honourRange bool) (*httptest.Server, *[]string) {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		first := len(ranges) == 1
+		mu.Unlock()
+
+		if first {
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Error("response writer does not support hijacking")
+				return
+			}
+			conn, buf, err := hj.Hijack()
+			if err != nil {
+				t.Errorf("hijack: %v", err)
+				return
+			}
+			_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nAccept-Ranges: bytes\r\nETag: \"v1\"\r\nContent-Length: " + strconv.Itoa(len(content)) + "\r\n\r\n")
+			_, _ = buf.Write(content[:partial])
+			_ = buf.Flush()
+			_ = conn.Close()
+			return
+		}
+		if !honourRange {
+			_, _ = w.Write(content)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	return srv, &ranges
+}
+
+// TestDownloadRetryResumesWithRange verifies that an interrupted download from
+// a range-capable server resumes from the last written byte, which also works
+// for a non-seekable destination.
+func TestDownloadRetryResumesWithRange(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	content := []byte("full delivery file content resumed from the last written byte")
+	apiServer, ranges := newResumableDownloadServer(t, content, 10, true)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dst := &nonSeekableWriter{}
+	var lastProgress, lastTotal int64
+	err := client.DownloadFileWithProgress(context.Background(), 1, 2, 3, dst, func(written, total int64) {
+		lastProgress, lastTotal = written, total
+	})
+	if err != nil {
+		t.Fatalf("DownloadFile with resume: %v", err)
+	}
+	if !bytes.Equal(dst.buf.Bytes(), content) {
+		t.Errorf("resumed content mismatch:\n got %q\nwant %q", dst.buf.Bytes(), content)
+	}
+	if len(*ranges) != 2 || (*ranges)[1] != "bytes=10-" {
+		t.Errorf("expected a resume request for bytes=10-, got %q", *ranges)
+	}
+	if lastProgress != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("progress ended at %d/%d, want %d/%d", lastProgress, lastTotal, len(content), len(content))
+	}
+}
+
+// TestDownloadResumeIgnoredRewinds verifies that when the server answers a
+// resume request with the whole file, a seekable destination is rewound
+// rather than receiving duplicate bytes.
+func TestDownloadResumeIgnoredRewinds(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	content := []byte("full delivery file content resent in full by the server")
+	apiServer, _ := newResumableDownloadServer(t, content, 10, false)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	tmp, err := os.CreateTemp(t.TempDir(), "bdds-download-*.zip")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	defer func() { _ = tmp.Close() }()
+
+	if err := client.DownloadFile(context.Background(), 1, 2, 3, tmp); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	got, _ := os.ReadFile(tmp.Name())
+	if !bytes.Equal(got, content) {
+		t.Errorf("content corrupted:\n got %q\nwant %q", got, content)
+	}
+}
+
 // TestAlways401SingleReauth verifies a persistent 401 triggers exactly one
 // re-authentication before surfacing the typed auth error.
 func TestAlways401SingleReauth(t *testing.T) {
@@ -506,3 +651,70 @@ func TestNonRetryableErrorStopsRetry(t *testing.T) {
 		t.Error("expected plain error to remain retryable")
 	}
 }
+
+// TestConcurrentRefreshSingleGrant verifies that concurrent requests needing a
+// token share one password grant, and that a caller giving up does not fail
+// the grant for the others.
+func TestConcurrentRefreshSingleGrant(t *testing.T) {
+	var grants int32
+	release := make(chan struct{})
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&grants, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"shared","expires_in":3600}`))
+	}))
+	defer authServer.Close()
+	client := newTestClient(t, "http://unused.invalid", authServer.URL)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ensureValidToken(cancelled, client.account); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled caller to give up, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := client.ensureValidToken(context.Background(), client.account)
+			if err != nil {
+				t.Errorf("ensureValidToken: %v", err)
+			}
+			tokens[i] = token
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&grants); n != 1 {
+		t.Errorf("expected 1 password grant, got %d", n)
+	}
+	for i, token := range tokens {
+		if token != "shared" {
+			t.Errorf("caller %d got token %q", i, token)
+		}
+	}
+}
+
+// TestClearTokenKeepsReplacement verifies that a late 401 for an old token
+// does not discard the token that already replaced it.
+func TestClearTokenKeepsReplacement(t *testing.T) {
+	client, err := NewClient(&Config{Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := client.account
+	a.token, a.tokenExpiry = "new", time.Now().Add(time.Hour)
+	client.clearToken("old")
+	if a.token != "new" {
+		t.Errorf("replacement token cleared by a 401 for the old one")
+	}
+	client.clearToken("new")
+	if a.token != "" || a.rejectedToken != "new" {
+		t.Errorf("rejected token kept: token %q, rejected %q", a.token, a.rejectedToken)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -30,6 +31,111 @@ func TestDefaultConfig(t *testing.T) {
 	if config.Timeout != 30*time.Second {
 		t.Errorf("Expected Timeout to be 30s, got %s", config.Timeout)
 	}
+	if config.ServicePath != "/bdds/bdds-bff-service" {
+		t.Errorf("Expected ServicePath to be /bdds/bdds-bff-service, got %s", config.ServicePath)
+	}
+	if config.APIVersion != "prod" {
+		t.Errorf("Expected APIVersion to be prod, got %s", config.APIVersion)
+	}
+	if config.TokenURL != "https://login.epo.org/oauth2/aus3up3nz0N133c0V417/v1/token" {
+		t.Errorf("Unexpected TokenURL %s", config.TokenURL)
+	}
+	if config.ClientID != "MG9hM3VwZG43YW41cE1JOE80MTc=" {
+		t.Errorf("Unexpected ClientID %s", config.ClientID)
+	}
+}
+
+// TestAPIURL tests joining the base URL, service path and API version
+func TestAPIURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{
+			name:   "defaults",
+			config: *DefaultConfig(),
+			want:   "https://publication-bdds.apps.epo.org/bdds/bdds-bff-service/prod/api",
+		},
+		{
+			name:   "custom version",
+			config: Config{BaseURL: "https://example.org/", ServicePath: "/bdds/bdds-bff-service/", APIVersion: "acc"},
+			want:   "https://example.org/bdds/bdds-bff-service/acc/api",
+		},
+		{
+			name:   "custom service path",
+			config: Config{BaseURL: "https://example.org", ServicePath: "bdds/v2-service", APIVersion: "prod"},
+			want:   "https://example.org/bdds/v2-service/prod/api",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apiURL(&tt.config); got != tt.want {
+				t.Errorf("apiURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCustomAPIPath tests that requests follow a configured service path and version
+func TestCustomAPIPath(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{BaseURL: server.URL, ServicePath: "/bdds/preprod-service", APIVersion: "acc"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatalf("ListProducts failed: %v", err)
+	}
+	if gotPath != "/bdds/preprod-service/acc/api/products/" {
+		t.Errorf("Expected request path /bdds/preprod-service/acc/api/products/, got %s", gotPath)
+	}
+}
+
+// TestCustomTokenEndpoint tests that authentication uses a configured token
+// URL and client ID
+func TestCustomTokenEndpoint(t *testing.T) {
+	var gotPath, gotAuth string
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer authServer.Close()
+	var gotToken string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(&Config{
+		Username: "u",
+		Password: "p",
+		BaseURL:  apiServer.URL,
+		TokenURL: authServer.URL + "/oauth2/test/v1/token",
+		ClientID: "dGVzdA==",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatalf("ListProducts failed: %v", err)
+	}
+	if gotPath != "/oauth2/test/v1/token" || gotAuth != "Basic dGVzdA==" {
+		t.Errorf("Token request to %s with %q", gotPath, gotAuth)
+	}
+	if gotToken != "Bearer test-token" {
+		t.Errorf("Expected the issued token on API requests, got %q", gotToken)
+	}
 }
 
 // TestNewClient_WithoutCredentials tests that NewClient works without credentials
@@ -152,14 +258,17 @@ func TestClientWithMockServer(t *testing.T) {
 	}))
 	defer apiServer.Close()
 
-	// Temporarily replace auth URL (not ideal but works for testing)
-	// In real tests we'd use dependency injection
+	// Redirect the OAuth calls to the auth server
 	config := &Config{
 		Username:  "test-user",
 		Password:  "test-pass",
 		BaseURL:   apiServer.URL,
 		UserAgent: "Test/1.0",
 		Timeout:   10 * time.Second,
+		Transport: &testTransport{
+			authURL: authServer.URL + "/oauth2/aus3up3nz0N133c0V417/v1/token",
+			rt:      http.DefaultTransport,
+		},
 	}
 
 	client, err := NewClient(config)
@@ -167,15 +276,6 @@ func TestClientWithMockServer(t *testing.T) {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
-	// Override httpClient to use auth server (for testing)
-	client.httpClient = &http.Client{
-		Transport: &testTransport{
-			authURL: authServer.URL + "/oauth2/aus3up3nz0N133c0V417/v1/token",
-			rt:      http.DefaultTransport,
-		},
-		Timeout: 10 * time.Second,
-	}
-
 	ctx := context.Background()
 
 	t.Run("ListProducts", func(t *testing.T) {
@@ -237,6 +337,28 @@ func TestClientWithMockServer(t *testing.T) {
 		}
 	})
 
+	t.Run("GetProductByName_Partial", func(t *testing.T) {
+		product, err := client.GetProductByName(ctx, "EP Full Text Data – Front File")
+		if err != nil {
+			t.Fatalf("GetProductByName failed: %v", err)
+		}
+		if product.ID != 4 {
+			t.Errorf("Expected product ID 4, got %d", product.ID)
+		}
+		if product, err = client.GetProductByName(ctx, "docdb back"); err != nil || product.ID != 14 {
+			t.Errorf("GetProductByName(docdb back) = %v, %v, want product 14", product, err)
+		}
+
+		_, err = client.GetProductByName(ctx, "front file")
+		var ambiguous *AmbiguousMatchError
+		if !errors.As(err, &ambiguous) {
+			t.Fatalf("Expected AmbiguousMatchError, got %T: %v", err, err)
+		}
+		if len(ambiguous.Candidates) != 2 {
+			t.Errorf("Expected 2 candidates, got %q", ambiguous.Candidates)
+		}
+	})
+
 	t.Run("GetProductByName_NotFound", func(t *testing.T) {
 		_, err := client.GetProductByName(ctx, "Non-existent Product")
 		if err == nil {
@@ -328,17 +450,17 @@ func TestTokenExpiry(t *testing.T) {
 	}
 
 	// Initially no token
-	if client.token != "" {
+	if client.account.token != "" {
 		t.Error("Expected no token initially")
 	}
 
 	// Set expired token
-	client.token = "expired-token"
-	client.tokenExpiry = time.Now().Add(-1 * time.Hour)
+	client.account.token = "expired-token"
+	client.account.tokenExpiry = time.Now().Add(-1 * time.Hour)
 
 	// ensureValidToken should detect expiry
 	// (will fail to refresh in this test, but that's expected)
-	_, err = client.ensureValidToken(context.Background())
+	_, err = client.ensureValidToken(context.Background(), client.account)
 	if err == nil {
 		t.Error("Expected error when refreshing with invalid credentials")
 	}
@@ -0,0 +1,87 @@
+package bdds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/patent-dev/epo-bdds/generated"
+)
+
+// ClientOverrides are the options a Clone changes. Zero fields keep the
+// values of the cloned client.
+type ClientOverrides struct {
+	UserAgent             string
+	MaxRetries            int
+	RetryDelay            time.Duration
+	Timeout               time.Duration
+	DownloadHeaderTimeout time.Duration
+	DownloadIdleTimeout   time.Duration
+	OnSchemaWarning       func(SchemaWarning)
+	OnRetry               func(attempt int, err error, nextDelay time.Duration)
+}
+
+// Clone returns a client with the options of o that shares the accounts and
+// tokens of c, its circuit breaker, connection pool, request limit and
+// background token refresh. It is cheap, e.g. to poll the catalog with short
+// timeouts and download with long ones from one process without a second
+// password grant. Closing a clone does nothing: Close the original client
+// once none of them is used any more.
+func (c *Client) Clone(o *ClientOverrides) (*Client, error) {
+	cfg := *c.config
+	if o != nil {
+		setString(&cfg.UserAgent, o.UserAgent)
+		if o.MaxRetries > 0 {
+			cfg.MaxRetries = o.MaxRetries
+		}
+		setDuration(&cfg.RetryDelay, o.RetryDelay)
+		setDuration(&cfg.Timeout, o.Timeout)
+		setDuration(&cfg.DownloadHeaderTimeout, o.DownloadHeaderTimeout)
+		setDuration(&cfg.DownloadIdleTimeout, o.DownloadIdleTimeout)
+		if o.OnSchemaWarning != nil {
+			cfg.OnSchemaWarning = o.OnSchemaWarning
+		}
+		if o.OnRetry != nil {
+			cfg.OnRetry = o.OnRetry
+		}
+	}
+
+	root := c
+	if c.clonedFrom != nil {
+		root = c.clonedFrom
+	}
+	httpClient := *c.httpClient
+	httpClient.Transport = newTimeoutTransport(c.httpClient.Transport.(*timeoutTransport).base, &cfg)
+	clone := &Client{
+		config:      &cfg,
+		httpClient:  &httpClient,
+		account:     c.account,
+		accounts:    c.accounts,
+		breaker:     c.breaker,
+		refreshWake: c.refreshWake,
+		lifetime:    c.lifetime,
+		stats:       c.stats,
+		clonedFrom:  root,
+	}
+	genClient, err := generated.NewClientWithResponses(
+		apiURL(&cfg),
+		generated.WithHTTPClient(&httpClient),
+		generated.WithRequestEditorFn(clone.authRequestEditor),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	clone.generatedClient = genClient
+	return clone, nil
+}
+
+func setString(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+func setDuration(field *time.Duration, value time.Duration) {
+	if value > 0 {
+		*field = value
+	}
+}
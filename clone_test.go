@@ -0,0 +1,82 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	authServer, authCalls := newAuthServer(3600)
+	defer authServer.Close()
+	var userAgent atomic.Value
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.UserAgent())
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(&Config{
+		Username:               "u",
+		Password:               "p",
+		BaseURL:                apiServer.URL,
+		TokenURL:               authServer.URL,
+		RevokeURL:              authServer.URL,
+		UserAgent:              "Original/1.0",
+		BackgroundTokenRefresh: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := client.Clone(&ClientOverrides{UserAgent: "Poller/1.0", Timeout: 20 * time.Millisecond, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.ListProducts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ua := userAgent.Load(); ua != "Original/1.0" {
+		t.Errorf("original sent User-Agent %q", ua)
+	}
+	if _, err := clone.ListProducts(ctx, WithNoRetry()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("clone ignored its shorter timeout: %v", err)
+	}
+	if ua := userAgent.Load(); ua != "Poller/1.0" {
+		t.Errorf("clone sent User-Agent %q", ua)
+	}
+	if n := atomic.LoadInt32(authCalls); n != 1 {
+		t.Errorf("%d password grants, want the token shared by the clone", n)
+	}
+	if client.config.Timeout != DefaultConfig().Timeout || client.config.UserAgent != "Original/1.0" {
+		t.Error("Clone changed the original's configuration")
+	}
+
+	if err := clone.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if client.account.token == "" {
+		t.Error("closing the clone revoked the shared token")
+	}
+	select {
+	case <-client.refreshDone:
+		t.Error("closing the clone stopped the background refresh")
+	default:
+	}
+	if err := client.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := clone.Clone(nil)
+	if err != nil || plain.config.UserAgent != "Poller/1.0" || plain.clonedFrom != client {
+		t.Errorf("clone of a clone: %+v, %v", plain.config, err)
+	}
+}
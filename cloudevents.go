@@ -0,0 +1,59 @@
+package bdds
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// CloudEventContentType is the media type of a CloudEvent in structured
+// mode; set it as Webhook.ContentType when sending CloudEvents over HTTP.
+const CloudEventContentType = "application/cloudevents+json"
+
+// CloudEventTypePrefix is prepended to the webhook event type to form the
+// CloudEvents type, e.g. "dev.patent.bdds.delivery.new".
+const CloudEventTypePrefix = "dev.patent.bdds."
+
+// CloudEvent is a CloudEvents 1.0 envelope in the JSON event format.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            any       `json:"data"`
+}
+
+// CloudEventPayload returns a Payload function for Webhook and
+// EventPublisher that wraps each event in a CloudEvents 1.0 envelope.
+// source identifies the producer (e.g. "https://mirror.example.com/bdds").
+// The subject is the event's catalog path, e.g. "products/3/deliveries/7"
+// or "products/3/deliveries/7/files/701", and the data the event itself.
+func CloudEventPayload(source string) func(*WebhookEvent) any {
+	return func(e *WebhookEvent) any {
+		subject := fmt.Sprintf("products/%d/deliveries/%d", e.ProductID, e.DeliveryID)
+		if e.FileID != 0 {
+			subject += fmt.Sprintf("/files/%d", e.FileID)
+		}
+		return &CloudEvent{
+			SpecVersion:     "1.0",
+			ID:              newEventID(),
+			Source:          source,
+			Type:            CloudEventTypePrefix + e.Type,
+			Subject:         subject,
+			Time:            e.Time,
+			DataContentType: "application/json",
+			Data:            e,
+		}
+	}
+}
+
+// newEventID returns a random 128-bit event ID.
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,58 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCloudEventWebhook(t *testing.T) {
+	var contentType string
+	var got struct {
+		CloudEvent
+		Data WebhookEvent `json:"data"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	hook := &Webhook{
+		URLs:        []string{srv.URL},
+		Payload:     CloudEventPayload("https://mirror.example.com/bdds"),
+		ContentType: CloudEventContentType,
+	}
+	completed := time.Date(2024, 10, 15, 12, 0, 0, 0, time.UTC)
+	r := &FileResult{ProductID: 3, DeliveryID: 7, File: &DeliveryFile{FileID: 701, FileName: "a.zip"}, Bytes: 10, CompletedAt: completed}
+	if err := hook.NotifyFileDownloaded(context.Background(), r); err != nil {
+		t.Fatalf("NotifyFileDownloaded: %v", err)
+	}
+	if contentType != CloudEventContentType {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+	if got.SpecVersion != "1.0" || got.Type != "dev.patent.bdds.file.downloaded" || got.Source != "https://mirror.example.com/bdds" {
+		t.Errorf("envelope = %+v", got.CloudEvent)
+	}
+	if got.Subject != "products/3/deliveries/7/files/701" || !got.Time.Equal(completed) || len(got.ID) != 32 {
+		t.Errorf("envelope = %+v", got.CloudEvent)
+	}
+	if got.Data.FileName != "a.zip" || got.Data.Bytes != 10 {
+		t.Errorf("data = %+v", got.Data)
+	}
+}
+
+func TestCloudEventPayloadIDs(t *testing.T) {
+	payload := CloudEventPayload("bdds")
+	e := newDeliveryEvent(3, &Delivery{DeliveryID: 7})
+	a, b := payload(e).(*CloudEvent), payload(e).(*CloudEvent)
+	if a.ID == b.ID {
+		t.Error("events share an ID")
+	}
+	if a.Subject != "products/3/deliveries/7" || a.Type != "dev.patent.bdds.delivery.new" {
+		t.Errorf("envelope = %+v", a)
+	}
+}
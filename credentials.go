@@ -0,0 +1,151 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials are an EPO username and password.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialProvider fetches EPO credentials, e.g. from a secrets manager
+// (see Config.Credentials).
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context) (*Credentials, error)
+
+// Credentials calls f.
+func (f CredentialProviderFunc) Credentials(ctx context.Context) (*Credentials, error) {
+	return f(ctx)
+}
+
+// credentialSource caches the credentials of a provider.
+type credentialSource struct {
+	provider CredentialProvider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	creds   *Credentials
+	fetched time.Time
+}
+
+// get returns the cached credentials, fetching them if there are none or
+// they are older than the TTL.
+func (s *credentialSource) get(ctx context.Context) (*Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.creds != nil && time.Since(s.fetched) < s.ttl {
+		return s.creds, nil
+	}
+	creds, err := s.provider.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+	if creds == nil || creds.Username == "" || creds.Password == "" {
+		return nil, fmt.Errorf("failed to fetch credentials: provider returned no username or password")
+	}
+	s.creds, s.fetched = creds, time.Now()
+	return creds, nil
+}
+
+// invalidate makes the next get fetch the credentials again, e.g. after
+// they were rotated.
+func (s *credentialSource) invalidate() {
+	s.mu.Lock()
+	s.creds = nil
+	s.mu.Unlock()
+}
+
+// VaultCredentials reads the EPO credentials from a HashiCorp Vault KV
+// version 2 secret, e.g. one written with
+//
+//	vault kv put secret/epo-bdds username=... password=...
+type VaultCredentials struct {
+	Addr        string // Vault address (default: $VAULT_ADDR)
+	Token       string // Vault token (default: $VAULT_TOKEN)
+	Namespace   string // Vault Enterprise namespace, if any
+	Mount       string // KV mount path (default: secret)
+	Path        string // secret path below the mount, e.g. epo-bdds
+	UsernameKey string // secret key of the username (default: username)
+	PasswordKey string // secret key of the password (default: password)
+	// HTTPClient sends the requests (default: a client with a 30s timeout).
+	HTTPClient *http.Client
+}
+
+// Credentials reads the latest version of the secret.
+func (v *VaultCredentials) Credentials(ctx context.Context) (*Credentials, error) {
+	addr, token := v.Addr, v.Token
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if addr == "" || v.Path == "" {
+		return nil, fmt.Errorf("vault: address and secret path are required")
+	}
+	mount, userKey, passKey := v.Mount, v.UsernameKey, v.PasswordKey
+	if mount == "" {
+		mount = "secret"
+	}
+	if userKey == "" {
+		userKey = "username"
+	}
+	if passKey == "" {
+		passKey = "password"
+	}
+
+	u := strings.TrimSuffix(addr, "/") + "/v1/" + url.PathEscape(strings.Trim(mount, "/")) +
+		"/data/" + strings.Trim(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	client := v.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("vault: reading %s failed (status %d): %s", v.Path, resp.StatusCode,
+			strings.TrimSpace(redactSecrets(string(body), token)))
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("vault: failed to parse secret %s: %w", v.Path, err)
+	}
+	username, _ := secret.Data.Data[userKey].(string)
+	password, _ := secret.Data.Data[passKey].(string)
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("vault: secret %s has no %q and %q", v.Path, userKey, passKey)
+	}
+	return &Credentials{Username: username, Password: password}, nil
+}
@@ -0,0 +1,139 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newVaultServer(t *testing.T, data map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/epo/bdds" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVaultCredentials(t *testing.T) {
+	vault := newVaultServer(t, map[string]any{"user": "vault-user", "pass": "vault-pass"})
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("username") != "vault-user" || r.PostFormValue("password") != "vault-pass" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	}))
+	defer authServer.Close()
+
+	client, err := NewClient(&Config{
+		TokenURL: authServer.URL,
+		Credentials: &VaultCredentials{
+			Addr: vault.URL, Token: "vault-token", Mount: "kv", Path: "epo/bdds",
+			UsernameKey: "user", PasswordKey: "pass",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	token, err := client.ensureValidToken(context.Background(), client.account)
+	if err != nil || token != "token" {
+		t.Fatalf("ensureValidToken = %q, %v", token, err)
+	}
+}
+
+func TestVaultCredentialsErrors(t *testing.T) {
+	vault := newVaultServer(t, map[string]any{"username": "u"})
+	ctx := context.Background()
+
+	_, err := (&VaultCredentials{Addr: vault.URL, Token: "wrong-token", Path: "epo/bdds"}).Credentials(ctx)
+	if err == nil || !strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "wrong-token") {
+		t.Errorf("forbidden: %v", err)
+	}
+	if _, err := (&VaultCredentials{Addr: vault.URL, Token: "vault-token", Mount: "kv", Path: "epo/bdds"}).Credentials(ctx); err == nil {
+		t.Error("expected an error for a secret without password")
+	}
+	if _, err := NewClient(&Config{Credentials: &VaultCredentials{Addr: vault.URL, Token: "wrong-token", Path: "x"}}); err == nil {
+		t.Error("NewClient succeeded without credentials")
+	}
+}
+
+func TestCredentialsRotation(t *testing.T) {
+	var fetches int32
+	provider := CredentialProviderFunc(func(context.Context) (*Credentials, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		if n == 1 {
+			return &Credentials{Username: "u", Password: "old-password"}, nil
+		}
+		return &Credentials{Username: "u", Password: "new-password"}, nil
+	})
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("password") != "new-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	}))
+	defer authServer.Close()
+
+	client, err := NewClient(&Config{TokenURL: authServer.URL, Credentials: provider})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err = client.ensureValidToken(ctx, client.account)
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected the rotated-away password to be rejected, got %v", err)
+	}
+	if _, err := client.ensureValidToken(ctx, client.account); err != nil {
+		t.Fatalf("rotated credentials not fetched: %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("fetched credentials %d times, want 2", n)
+	}
+}
+
+func TestCredentialsTTL(t *testing.T) {
+	var fetches int32
+	source := &credentialSource{
+		provider: CredentialProviderFunc(func(context.Context) (*Credentials, error) {
+			atomic.AddInt32(&fetches, 1)
+			return &Credentials{Username: "u", Password: "p"}, nil
+		}),
+		ttl: time.Hour,
+	}
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := source.get(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("fetched %d times within the TTL, want 1", n)
+	}
+	source.fetched = source.fetched.Add(-2 * time.Hour)
+	if _, err := source.get(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expired credentials not fetched again (%d fetches)", n)
+	}
+}
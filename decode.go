@@ -0,0 +1,290 @@
+package bdds
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaWarning describes a difference between a catalog response and the
+// schema this library models, reported in strict decoding mode.
+type SchemaWarning struct {
+	Endpoint string // Client method that received the response, e.g. "GetProduct"
+	Path     string // JSON path of the offending value, e.g. "deliveries[3].files[0].fileSize"
+	Message  string
+}
+
+func (w SchemaWarning) String() string {
+	return fmt.Sprintf("%s: %s: %s", w.Endpoint, w.Path, w.Message)
+}
+
+// Catalog responses are decoded into these wire types rather than the
+// generated ones, so unknown fields are ignored and the odd datetime formats
+// EPO occasionally emits are coerced instead of failing the whole response.
+
+type wireProduct struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Deliveries  []wireDelivery `json:"deliveries"`
+}
+
+type wireDelivery struct {
+	DeliveryID                  int        `json:"deliveryId"`
+	DeliveryName                string     `json:"deliveryName"`
+	DeliveryPublicationDatetime flexTime   `json:"deliveryPublicationDatetime"`
+	DeliveryExpiryDatetime      flexTime   `json:"deliveryExpiryDatetime"`
+	Files                       []wireFile `json:"files"`
+}
+
+type wireFile struct {
+	FileID                  int      `json:"fileId"`
+	FileName                string   `json:"fileName"`
+	FileSize                string   `json:"fileSize"`
+	FileChecksum            string   `json:"fileChecksum"`
+	FilePublicationDatetime flexTime `json:"filePublicationDatetime"`
+}
+
+// flexTimeLayouts are the non-RFC 3339 datetime formats accepted when
+// decoding. Layouts without a zone are interpreted as UTC.
+var flexTimeLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// flexTime decodes a datetime tolerantly. Set is false for null or missing
+// values; Raw holds the original text when it was not RFC 3339 (coerced) or
+// could not be parsed at all (Time left zero).
+type flexTime struct {
+	Time    time.Time
+	Set     bool
+	Raw     string
+	Coerced bool
+	Invalid bool
+}
+
+func (ft *flexTime) UnmarshalJSON(data []byte) error {
+	*ft = flexTime{}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	ft.Set = true
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Numeric epoch timestamps, in seconds or milliseconds.
+		n, nerr := strconv.ParseInt(string(data), 10, 64)
+		if nerr != nil {
+			ft.Raw, ft.Invalid = string(data), true
+			return nil
+		}
+		ft.Raw, ft.Coerced = string(data), true
+		if n > 1e12 {
+			ft.Time = time.UnixMilli(n).UTC()
+		} else {
+			ft.Time = time.Unix(n, 0).UTC()
+		}
+		return nil
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		ft.Set = false
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		ft.Time = t
+		return nil
+	}
+	ft.Raw = s
+	for _, layout := range flexTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			ft.Time, ft.Coerced = t, true
+			return nil
+		}
+	}
+	ft.Invalid = true
+	return nil
+}
+
+// ptr returns the decoded time as a pointer, nil when the value was absent.
+func (ft flexTime) ptr() *time.Time {
+	if !ft.Set || ft.Invalid {
+		return nil
+	}
+	t := ft.Time
+	return &t
+}
+
+// catalogDecoder decodes one catalog response and collects schema warnings
+// when strict decoding is enabled.
+type catalogDecoder struct {
+	endpoint string
+	strict   bool
+	warnings []SchemaWarning
+}
+
+func (d *catalogDecoder) warn(path, format string, args ...any) {
+	if !d.strict {
+		return
+	}
+	d.warnings = append(d.warnings, SchemaWarning{
+		Endpoint: d.endpoint,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// decode unmarshals body into v, ignoring unknown fields. In strict mode it
+// additionally records every unknown field as a warning.
+func (d *catalogDecoder) decode(body []byte, v any) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return fmt.Errorf("empty response body")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", d.endpoint, err)
+	}
+	if d.strict {
+		var raw any
+		if err := json.Unmarshal(body, &raw); err == nil {
+			d.unknownFields(raw, reflect.TypeOf(v), "")
+		}
+	}
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unknownFields walks a generic JSON value alongside the Go type it was
+// decoded into and warns about every object key the type does not model.
+func (d *catalogDecoder) unknownFields(raw any, t reflect.Type, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" {
+				name = f.Name
+			}
+			fields[name] = f.Type
+		}
+		for key, val := range obj {
+			child := joinPath(path, key)
+			ft, ok := fields[key]
+			if !ok {
+				d.warn(child, "unknown field")
+				continue
+			}
+			d.unknownFields(val, ft, child)
+		}
+	case reflect.Slice:
+		arr, ok := raw.([]any)
+		if !ok {
+			return
+		}
+		for i, val := range arr {
+			d.unknownFields(val, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// checkTime records a warning for a coerced or unparseable datetime.
+func (d *catalogDecoder) checkTime(path string, ft flexTime) {
+	switch {
+	case ft.Invalid:
+		d.warn(path, "unparseable datetime %q, left empty", ft.Raw)
+	case ft.Coerced:
+		d.warn(path, "non-RFC 3339 datetime %q coerced to %s", ft.Raw, ft.Time.Format(time.RFC3339))
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// products converts a decoded product list.
+func (d *catalogDecoder) products(wire []wireProduct) []*Product {
+	result := make([]*Product, len(wire))
+	for i, p := range wire {
+		result[i] = &Product{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+		}
+	}
+	return result
+}
+
+// product converts a decoded product with its deliveries.
+func (d *catalogDecoder) product(p *wireProduct) *ProductWithDeliveries {
+	result := &ProductWithDeliveries{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Deliveries:  make([]*Delivery, len(p.Deliveries)),
+	}
+	for i, wd := range p.Deliveries {
+		path := fmt.Sprintf("deliveries[%d]", i)
+		d.checkTime(path+".deliveryPublicationDatetime", wd.DeliveryPublicationDatetime)
+		d.checkTime(path+".deliveryExpiryDatetime", wd.DeliveryExpiryDatetime)
+
+		delivery := &Delivery{
+			DeliveryID:                  wd.DeliveryID,
+			DeliveryName:                wd.DeliveryName,
+			DeliveryPublicationDatetime: wd.DeliveryPublicationDatetime.Time,
+			DeliveryExpiryDatetime:      wd.DeliveryExpiryDatetime.ptr(),
+			Files:                       make([]*DeliveryFile, len(wd.Files)),
+		}
+		for j, wf := range wd.Files {
+			d.checkTime(fmt.Sprintf("%s.files[%d].filePublicationDatetime", path, j), wf.FilePublicationDatetime)
+			delivery.Files[j] = &DeliveryFile{
+				FileID:                  wf.FileID,
+				FileName:                wf.FileName,
+				FileSize:                wf.FileSize,
+				FileChecksum:            wf.FileChecksum,
+				FilePublicationDatetime: wf.FilePublicationDatetime.Time,
+			}
+		}
+		result.Deliveries[i] = delivery
+	}
+	return result
+}
+
+// newCatalogDecoder returns a decoder for one response of the given endpoint.
+func (c *Client) newCatalogDecoder(endpoint string) *catalogDecoder {
+	return &catalogDecoder{endpoint: endpoint, strict: c.config.StrictDecoding}
+}
+
+// reportSchemaWarnings hands collected warnings to the configured callback,
+// falling back to logging them at warning level to Config.Logger.
+func (c *Client) reportSchemaWarnings(d *catalogDecoder) {
+	for _, w := range d.warnings {
+		if c.config.OnSchemaWarning != nil {
+			c.config.OnSchemaWarning(w)
+			continue
+		}
+		c.logger().Warn("bdds: schema drift", "warning", w.String())
+	}
+}
@@ -76,7 +76,7 @@ type endpoint struct {
 	// TYPED value (not the raw fixture), so a passing check proves the value
 	// survived the wrapper-type round-trip and parsed into the right field. For
 	// kindRawMeta they run against the raw fixture map. Paths use dot notation with
-	// [i] for array indices, e.g. "Deliveries[0].Files[0].FileID".
+	// [i] for array indices, e.g. "deliveries[0].files[0].fileId".
 	checks []check
 }
 
@@ -107,42 +107,42 @@ var endpoints = []endpoint{
 			// ListProducts returns the full product catalogue. EPO genuinely
 			// returns 16 rows here (some product ids repeat across sections).
 			{path: "", length: ln(16)},
-			{path: "[0].ID", want: eq("5")},
-			{path: "[0].Name", want: eq("14.11 EPO worldwide legal event data (INPADOC) - front file")},
-			{path: "[0].Description"},
-			{path: "[2].ID", want: eq("20")},
-			{path: "[2].Name", want: eq("Samples of bulk data sets")},
+			{path: "[0].id", want: eq("5")},
+			{path: "[0].name", want: eq("14.11 EPO worldwide legal event data (INPADOC) - front file")},
+			{path: "[0].description"},
+			{path: "[2].id", want: eq("20")},
+			{path: "[2].name", want: eq("Samples of bulk data sets")},
 		},
 	},
 	{
 		file: "02-GetProduct.json", kind: kindTypedJSON,
 		decodeTarget: &ProductWithDeliveries{},
 		checks: []check{
-			{path: "ID", want: eq("5")},
-			{path: "Name", want: eq("14.11 EPO worldwide legal event data (INPADOC) - front file")},
-			{path: "Description"},
-			{path: "Deliveries", length: ln(25)},
-			{path: "Deliveries[0].DeliveryID", want: eq("3267")},
-			{path: "Deliveries[0].DeliveryName", want: eq("NOTIFICATION: NEW DTD - NEW ELEMENTS IN XML")},
-			{path: "Deliveries[0].Files", length: ln(1)},
-			{path: "Deliveries[0].Files[0].FileID", want: eq("9431")},
-			{path: "Deliveries[0].Files[0].FileName", want: eq("20260603_NEW_ELEMENTS.docx")},
-			{path: "Deliveries[0].Files[0].FileSize", want: eq("17.7 kB")},
-			{path: "Deliveries[0].Files[0].FileChecksum", want: eq("8B428D49F3BB3C19C5FFD7035EDCE1824ED45149")},
+			{path: "id", want: eq("5")},
+			{path: "name", want: eq("14.11 EPO worldwide legal event data (INPADOC) - front file")},
+			{path: "description"},
+			{path: "deliveries", length: ln(25)},
+			{path: "deliveries[0].deliveryId", want: eq("3267")},
+			{path: "deliveries[0].deliveryName", want: eq("NOTIFICATION: NEW DTD - NEW ELEMENTS IN XML")},
+			{path: "deliveries[0].files", length: ln(1)},
+			{path: "deliveries[0].files[0].fileId", want: eq("9431")},
+			{path: "deliveries[0].files[0].fileName", want: eq("20260603_NEW_ELEMENTS.docx")},
+			{path: "deliveries[0].files[0].fileSize", want: eq("17.7 kB")},
+			{path: "deliveries[0].files[0].fileChecksum", want: eq("8B428D49F3BB3C19C5FFD7035EDCE1824ED45149")},
 		},
 	},
 	{
 		file: "03-GetLatestDelivery.json", kind: kindTypedJSON,
 		decodeTarget: &Delivery{},
 		checks: []check{
-			{path: "DeliveryID", want: eq("3262")},
-			{path: "DeliveryName", want: eq("14.11 INPADOC - EPO worldwide legal event data 2026/023")},
-			{path: "DeliveryPublicationDatetime", want: eq("2026-06-02T09:00:00+02:00")},
-			{path: "Files", length: ln(4)},
-			{path: "Files[0].FileID", want: eq("9416")},
-			{path: "Files[0].FileName", want: eq("legstat_xml_202623.zip")},
-			{path: "Files[0].FileSize", want: eq("216.6 MB")},
-			{path: "Files[0].FileChecksum", want: eq("F30378B303DA95087423E752F776ADD4DA5DB262")},
+			{path: "deliveryId", want: eq("3262")},
+			{path: "deliveryName", want: eq("14.11 INPADOC - EPO worldwide legal event data 2026/023")},
+			{path: "deliveryPublicationDatetime", want: eq("2026-06-02T09:00:00+02:00")},
+			{path: "files", length: ln(4)},
+			{path: "files[0].fileId", want: eq("9416")},
+			{path: "files[0].fileName", want: eq("legstat_xml_202623.zip")},
+			{path: "files[0].fileSize", want: eq("216.6 MB")},
+			{path: "files[0].fileChecksum", want: eq("F30378B303DA95087423E752F776ADD4DA5DB262")},
 		},
 	},
 	{
@@ -240,7 +240,7 @@ func normalize(v any) any {
 }
 
 // lookup walks a decoded JSON value (map[string]any / []any) by a dotted path
-// with optional [i] array indices, e.g. "Deliveries[0].Files[0].FileID". An empty
+// with optional [i] array indices, e.g. "deliveries[0].files[0].fileId". An empty
 // path returns the root. It returns the value at the path and whether it was
 // found.
 func lookup(root any, path string) (any, bool) {
@@ -0,0 +1,194 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFlexTime verifies the tolerant datetime decoding.
+func TestFlexTime(t *testing.T) {
+	utc := time.Date(2024, 10, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		set     bool
+		coerced bool
+		invalid bool
+	}{
+		{name: "RFC3339", input: `"2024-10-15T10:30:00Z"`, want: utc, set: true},
+		{name: "no zone", input: `"2024-10-15T10:30:00"`, want: utc, set: true, coerced: true},
+		{name: "space separated", input: `"2024-10-15 10:30:00"`, want: utc, set: true, coerced: true},
+		{name: "compact zone", input: `"2024-10-15T12:30:00+0200"`, want: utc, set: true, coerced: true},
+		{name: "date only", input: `"2024-10-15"`, want: time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC), set: true, coerced: true},
+		{name: "epoch millis", input: `1728988200000`, want: utc, set: true, coerced: true},
+		{name: "epoch seconds", input: `1728988200`, want: utc, set: true, coerced: true},
+		{name: "null", input: `null`},
+		{name: "empty string", input: `""`},
+		{name: "garbage", input: `"next tuesday"`, set: true, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ft flexTime
+			if err := json.Unmarshal([]byte(tt.input), &ft); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !ft.Time.Equal(tt.want) {
+				t.Errorf("time = %s, want %s", ft.Time, tt.want)
+			}
+			if ft.Set != tt.set || ft.Coerced != tt.coerced || ft.Invalid != tt.invalid {
+				t.Errorf("flags set=%v coerced=%v invalid=%v, want %v %v %v",
+					ft.Set, ft.Coerced, ft.Invalid, tt.set, tt.coerced, tt.invalid)
+			}
+		})
+	}
+}
+
+// driftedProduct is a product response with an unknown field on every level
+// and a non-RFC 3339 publication datetime.
+const driftedProduct = `{
+	"id": 3, "name": "x", "description": "y", "category": "new",
+	"deliveries": [{
+		"deliveryId": 1,
+		"deliveryName": "2024-10-15",
+		"deliveryPublicationDatetime": "2024-10-15 10:30:00",
+		"deliveryExpiryDatetime": null,
+		"deliveryType": "weekly",
+		"files": [{
+			"fileId": 2,
+			"fileName": "a.zip",
+			"fileSize": "1 kB",
+			"fileChecksum": "abc",
+			"filePublicationDatetime": "2024-10-15T10:30:00Z",
+			"fileSizeBytes": 1000
+		}]
+	}]
+}`
+
+func newDriftServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(driftedProduct))
+	}))
+}
+
+// TestTolerantDecoding verifies drifted responses decode without warnings by
+// default.
+func TestTolerantDecoding(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newDriftServer()
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	var warnings []SchemaWarning
+	client.config.OnSchemaWarning = func(w SchemaWarning) { warnings = append(warnings, w) }
+
+	product, err := client.GetProduct(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	want := time.Date(2024, 10, 15, 10, 30, 0, 0, time.UTC)
+	if got := product.Deliveries[0].DeliveryPublicationDatetime; !got.Equal(want) {
+		t.Errorf("publication datetime = %s, want %s", got, want)
+	}
+	if product.Deliveries[0].DeliveryExpiryDatetime != nil {
+		t.Errorf("expected nil expiry, got %s", product.Deliveries[0].DeliveryExpiryDatetime)
+	}
+	if product.Deliveries[0].Files[0].FileName != "a.zip" {
+		t.Errorf("unexpected file name %q", product.Deliveries[0].Files[0].FileName)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings in tolerant mode, got %v", warnings)
+	}
+}
+
+// TestStrictDecodingWarnings verifies strict mode reports every unknown field
+// and coerced datetime without failing the call.
+func TestStrictDecodingWarnings(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newDriftServer()
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	client.config.StrictDecoding = true
+	var mu sync.Mutex
+	paths := map[string]string{}
+	client.config.OnSchemaWarning = func(w SchemaWarning) {
+		mu.Lock()
+		defer mu.Unlock()
+		if w.Endpoint != "GetProduct" {
+			t.Errorf("unexpected endpoint %q", w.Endpoint)
+		}
+		paths[w.Path] = w.Message
+	}
+
+	if _, err := client.GetProduct(context.Background(), 3); err != nil {
+		t.Fatalf("GetProduct in strict mode: %v", err)
+	}
+	for _, path := range []string{
+		"category",
+		"deliveries[0].deliveryType",
+		"deliveries[0].files[0].fileSizeBytes",
+		"deliveries[0].deliveryPublicationDatetime",
+	} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("missing warning for %s (got %v)", path, paths)
+		}
+	}
+	if len(paths) != 4 {
+		t.Errorf("expected 4 warnings, got %d: %v", len(paths), paths)
+	}
+	if msg := paths["deliveries[0].deliveryPublicationDatetime"]; !strings.Contains(msg, "coerced") {
+		t.Errorf("expected coercion warning, got %q", msg)
+	}
+}
+
+// TestStrictDecodingLogsWarnings verifies strict-mode warnings go to
+// Config.Logger without an OnSchemaWarning callback.
+func TestStrictDecodingLogsWarnings(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newDriftServer()
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	client.config.StrictDecoding = true
+	var logs syncBuffer
+	client.config.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	if _, err := client.GetProduct(context.Background(), 3); err != nil {
+		t.Fatalf("GetProduct in strict mode: %v", err)
+	}
+	out := logs.String()
+	if n := strings.Count(out, `level=WARN msg="bdds: schema drift"`); n != 4 {
+		t.Errorf("expected 4 logged warnings, got %d:\n%s", n, out)
+	}
+	if !strings.Contains(out, `warning="GetProduct: deliveries[0].deliveryType: unknown field"`) {
+		t.Errorf("warning not logged:\n%s", out)
+	}
+}
+
+// TestStrictDecodingCleanResponse verifies a schema-conformant response
+// produces no warnings in strict mode.
+func TestStrictDecodingCleanResponse(t *testing.T) {
+	d := &catalogDecoder{endpoint: "ListProducts", strict: true}
+	var wire []wireProduct
+	if err := d.decode([]byte(`[{"id":1,"name":"x","description":"y"}]`), &wire); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(d.warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", d.warnings)
+	}
+	if err := d.decode([]byte("  "), &wire); err == nil {
+		t.Error("expected error for empty body")
+	}
+}
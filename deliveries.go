@@ -0,0 +1,172 @@
+package bdds
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GetDelivery returns a delivery of a product with its files, or a
+// *NotFoundError if the product has no such delivery. The API serves
+// deliveries only as part of their product, so this still fetches the
+// product's catalog. To resolve several deliveries of a product, call
+// GetProduct once and look them up with ProductWithDeliveries.Delivery.
+func (c *Client) GetDelivery(ctx context.Context, productID, deliveryID int, opts ...CallOption) (*Delivery, error) {
+	_, delivery, err := c.findDelivery(ctx, productID, deliveryID, opts...)
+	return delivery, err
+}
+
+// Delivery returns the delivery with the given ID, or nil if the product
+// does not list it.
+func (p *ProductWithDeliveries) Delivery(deliveryID int) *Delivery {
+	for _, d := range p.Deliveries {
+		if d.DeliveryID == deliveryID {
+			return d
+		}
+	}
+	return nil
+}
+
+// GetDeliveryByName returns the delivery of a product with the given name,
+// such as "2024-10-15", with its files, or a *NotFoundError. Names are
+// compared case-insensitively. Like GetDelivery, it fetches the product's
+// catalog.
+func (c *Client) GetDeliveryByName(ctx context.Context, productID int, name string, opts ...CallOption) (*Delivery, error) {
+	product, err := c.GetProduct(ctx, productID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if d := product.DeliveryByName(name); d != nil {
+		return d, nil
+	}
+	return nil, &NotFoundError{
+		Resource: "delivery",
+		ID:       fmt.Sprintf("%d/%s", productID, name),
+	}
+}
+
+// DeliveryByName returns the delivery with the given name, compared
+// case-insensitively, or nil if the product does not list it. Of several
+// deliveries of that name, the latest published is returned.
+func (p *ProductWithDeliveries) DeliveryByName(name string) *Delivery {
+	var found *Delivery
+	for _, d := range p.Deliveries {
+		if strings.EqualFold(d.DeliveryName, name) &&
+			(found == nil || d.DeliveryPublicationDatetime.After(found.DeliveryPublicationDatetime)) {
+			found = d
+		}
+	}
+	return found
+}
+
+// DeliveryFilter selects the deliveries returned by ListDeliveries. Unset
+// fields match everything; a delivery must match all set fields.
+type DeliveryFilter struct {
+	// From and To bound the delivery publication date, both inclusive.
+	From time.Time
+	To   time.Time
+	// NamePattern is a glob (as in path.Match) for the delivery name,
+	// e.g. "2024-*".
+	NamePattern string
+	// Limit caps the number of deliveries returned, the newest first
+	// (0: no limit).
+	Limit int
+}
+
+// match reports whether d passes the filter.
+func (f *DeliveryFilter) match(d *Delivery) bool {
+	published := d.DeliveryPublicationDatetime
+	if !f.From.IsZero() && published.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && published.After(f.To) {
+		return false
+	}
+	if f.NamePattern != "" {
+		if ok, _ := path.Match(f.NamePattern, d.DeliveryName); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ListDeliveries returns the deliveries of a product that match filter,
+// newest first; a nil filter matches all. The API has no delivery
+// endpoint, so the product's catalog is fetched and filtered locally.
+func (c *Client) ListDeliveries(ctx context.Context, productID int, filter *DeliveryFilter, opts ...CallOption) ([]*Delivery, error) {
+	var f DeliveryFilter
+	if filter != nil {
+		f = *filter
+	}
+	if _, err := path.Match(f.NamePattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid delivery name pattern %q: %w", f.NamePattern, err)
+	}
+	if f.Limit < 0 {
+		return nil, fmt.Errorf("invalid delivery limit %d", f.Limit)
+	}
+
+	product, err := c.GetProduct(ctx, productID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []*Delivery
+	for _, d := range product.Deliveries {
+		if f.match(d) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	slices.SortStableFunc(deliveries, func(a, b *Delivery) int {
+		return b.DeliveryPublicationDatetime.Compare(a.DeliveryPublicationDatetime)
+	})
+	if f.Limit > 0 && len(deliveries) > f.Limit {
+		deliveries = deliveries[:f.Limit]
+	}
+	return deliveries, nil
+}
+
+// GetDeliveriesSince returns the deliveries of a product published after t,
+// oldest first, so an incremental consumer can process them in order and
+// keep the last one's DeliveryPublicationDatetime as its next t. A zero t
+// returns all deliveries.
+func (c *Client) GetDeliveriesSince(ctx context.Context, productID int, t time.Time, opts ...CallOption) ([]*Delivery, error) {
+	product, err := c.GetProduct(ctx, productID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []*Delivery
+	for _, d := range product.Deliveries {
+		if d.DeliveryPublicationDatetime.After(t) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	slices.SortStableFunc(deliveries, func(a, b *Delivery) int {
+		return a.DeliveryPublicationDatetime.Compare(b.DeliveryPublicationDatetime)
+	})
+	return deliveries, nil
+}
+
+// GetExpiringDeliveries returns the deliveries of a product that expire
+// within the given duration from now, the first to expire first, so they can
+// be downloaded before EPO removes them. Deliveries without an expiry date
+// and those already expired are left out.
+func (c *Client) GetExpiringDeliveries(ctx context.Context, productID int, within time.Duration, opts ...CallOption) ([]*Delivery, error) {
+	product, err := c.GetProduct(ctx, productID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	deadline := now.Add(within)
+	var deliveries []*Delivery
+	for _, d := range product.Deliveries {
+		if expiry := d.DeliveryExpiryDatetime; expiry != nil && expiry.After(now) && !expiry.After(deadline) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	slices.SortStableFunc(deliveries, func(a, b *Delivery) int {
+		return a.DeliveryExpiryDatetime.Compare(*b.DeliveryExpiryDatetime)
+	})
+	return deliveries, nil
+}
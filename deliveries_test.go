@@ -0,0 +1,183 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListDeliveries(t *testing.T) {
+	week := func(n int) time.Time { return time.Date(2024, 10, 1+7*n, 10, 0, 0, 0, time.UTC) }
+	srv, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		{id: 1, name: "2024-10-01", published: week(0)},
+		{id: 3, name: "2024-10-15", published: week(2)},
+		{id: 2, name: "2024-10-08", published: week(1)},
+		{id: 9, name: "NOTIFICATION", published: week(1)},
+		{id: 4, name: "2024-10-22", published: week(3)},
+	})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		filter *DeliveryFilter
+		want   []int
+	}{
+		{nil, []int{4, 3, 2, 9, 1}},
+		{&DeliveryFilter{From: week(1), To: week(2)}, []int{3, 2, 9}},
+		{&DeliveryFilter{NamePattern: "2024-*"}, []int{4, 3, 2, 1}},
+		{&DeliveryFilter{NamePattern: "2024-*", Limit: 2}, []int{4, 3}},
+		{&DeliveryFilter{From: week(4)}, nil},
+	}
+	for _, tt := range tests {
+		deliveries, err := client.ListDeliveries(ctx, 3, tt.filter)
+		if err != nil {
+			t.Fatalf("ListDeliveries(%+v): %v", tt.filter, err)
+		}
+		var got []int
+		for _, d := range deliveries {
+			got = append(got, d.DeliveryID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ListDeliveries(%+v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	if _, err := client.ListDeliveries(ctx, 3, &DeliveryFilter{NamePattern: "["}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestGetDeliveriesSince(t *testing.T) {
+	week := func(n int) time.Time { return time.Date(2024, 10, 1+7*n, 10, 0, 0, 0, time.UTC) }
+	srv, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		{id: 3, name: "2024-10-15", published: week(2)},
+		{id: 1, name: "2024-10-01", published: week(0)},
+		{id: 4, name: "2024-10-22", published: week(3)},
+		{id: 2, name: "2024-10-08", published: week(1)},
+	})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		since time.Time
+		want  []int
+	}{
+		{time.Time{}, []int{1, 2, 3, 4}},
+		{week(1), []int{3, 4}}, // strictly after
+		{week(1).Add(-time.Second), []int{2, 3, 4}},
+		{week(3), nil},
+	} {
+		deliveries, err := client.GetDeliveriesSince(context.Background(), 3, tt.since)
+		if err != nil {
+			t.Fatalf("GetDeliveriesSince(%v): %v", tt.since, err)
+		}
+		var got []int
+		for _, d := range deliveries {
+			got = append(got, d.DeliveryID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GetDeliveriesSince(%v) = %v, want %v", tt.since, got, tt.want)
+		}
+	}
+}
+
+func TestGetExpiringDeliveries(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	at := func(d time.Duration) *time.Time { e := now.Add(d); return &e }
+	day := 24 * time.Hour
+	srv, _ := newMirrorServer(t, 14, []mirrorDelivery{
+		{id: 1, name: "expired", published: now.Add(-90 * day), expiry: at(-day)},
+		{id: 2, name: "in a week", published: now.Add(-60 * day), expiry: at(7 * day)},
+		{id: 3, name: "tomorrow", published: now.Add(-80 * day), expiry: at(day)},
+		{id: 4, name: "next year", published: now.Add(-30 * day), expiry: at(365 * day)},
+		{id: 5, name: "never", published: now.Add(-10 * day)},
+	})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		within time.Duration
+		want   []int
+	}{
+		{30 * day, []int{3, 2}},
+		{2 * day, []int{3}},
+		{time.Hour, nil},
+	} {
+		deliveries, err := client.GetExpiringDeliveries(context.Background(), 14, tt.within)
+		if err != nil {
+			t.Fatalf("GetExpiringDeliveries(%v): %v", tt.within, err)
+		}
+		var got []int
+		for _, d := range deliveries {
+			got = append(got, d.DeliveryID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GetExpiringDeliveries(%v) = %v, want %v", tt.within, got, tt.want)
+		}
+	}
+}
+
+func TestGetDelivery(t *testing.T) {
+	srv, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		{id: 1, name: "2024-10-01", published: time.Now(), files: []mirrorFile{{id: 11, name: "a.zip", content: "a"}}},
+		{id: 2, name: "2024-10-08", published: time.Now()},
+	})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := client.GetDelivery(context.Background(), 3, 1)
+	if err != nil {
+		t.Fatalf("GetDelivery: %v", err)
+	}
+	if d.DeliveryName != "2024-10-01" || len(d.Files) != 1 || d.Files[0].FileID != 11 {
+		t.Errorf("GetDelivery = %+v", d)
+	}
+	var notFound *NotFoundError
+	if _, err := client.GetDelivery(context.Background(), 3, 5); !errors.As(err, &notFound) {
+		t.Errorf("GetDelivery of a missing delivery = %v, want a NotFoundError", err)
+	}
+}
+
+func TestGetDeliveryByName(t *testing.T) {
+	now := time.Now()
+	srv, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		{id: 1, name: "2024-10-15", published: now.Add(-time.Hour)},
+		{id: 2, name: "2024-10-15", published: now},
+		{id: 3, name: "Notification", published: now},
+	})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for name, want := range map[string]int{"2024-10-15": 2, "NOTIFICATION": 3} {
+		d, err := client.GetDeliveryByName(ctx, 3, name)
+		if err != nil {
+			t.Fatalf("GetDeliveryByName(%q): %v", name, err)
+		}
+		if d.DeliveryID != want {
+			t.Errorf("GetDeliveryByName(%q) = delivery %d, want %d", name, d.DeliveryID, want)
+		}
+	}
+	var notFound *NotFoundError
+	if _, err := client.GetDeliveryByName(ctx, 3, "2024-10-22"); !errors.As(err, &notFound) {
+		t.Errorf("GetDeliveryByName of a missing delivery = %v, want a NotFoundError", err)
+	}
+}
@@ -0,0 +1,69 @@
+package bdds
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	// deliveryWeekPattern matches the "2026/023" year/week suffix of weekly
+	// delivery names.
+	deliveryWeekPattern = regexp.MustCompile(`\b(\d{4})/(\d{2,3})$`)
+	// deliveryDatePattern matches a date in a delivery name.
+	deliveryDatePattern = regexp.MustCompile(`\b(\d{4})-?(\d{2})-?(\d{2})\b`)
+)
+
+// Date returns the day the delivery belongs to, as midnight UTC, and its ISO
+// week number. Weekly deliveries are named after their publication week
+// ("... 2026/023"); the week is taken from the name and the date is the
+// publication day if it falls in that week, otherwise the Monday of the week.
+// Names with a date ("2024-10-15" or "20241015") give that date. Other
+// deliveries, such as notifications, use the publication day. The year of
+// the week is the ISO year of the date, which differs from its calendar year
+// around New Year. Date returns a zero time and week 0 if nothing is known.
+func (d *Delivery) Date() (time.Time, int) {
+	var published time.Time
+	if !d.DeliveryPublicationDatetime.IsZero() {
+		y, m, day := d.DeliveryPublicationDatetime.Date()
+		published = time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	if m := deliveryWeekPattern.FindStringSubmatch(d.DeliveryName); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		if week >= 1 && week <= 53 {
+			if !published.IsZero() {
+				if y, w := published.ISOWeek(); y == year && w == week {
+					return published, week
+				}
+			}
+			// Week 53 only exists in some years.
+			monday := isoWeekMonday(year, week)
+			if y, w := monday.ISOWeek(); y == year && w == week {
+				return monday, week
+			}
+		}
+	}
+
+	if m := deliveryDatePattern.FindStringSubmatch(d.DeliveryName); m != nil {
+		if date, err := time.Parse(time.DateOnly, m[1]+"-"+m[2]+"-"+m[3]); err == nil {
+			_, week := date.ISOWeek()
+			return date, week
+		}
+	}
+
+	if published.IsZero() {
+		return time.Time{}, 0
+	}
+	_, week := published.ISOWeek()
+	return published, week
+}
+
+// isoWeekMonday returns the Monday of ISO week week of year, as midnight UTC.
+// Week 1 is the week containing January 4th.
+func isoWeekMonday(year, week int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) + 6) % 7 // days since Monday
+	return jan4.AddDate(0, 0, (week-1)*7-offset)
+}
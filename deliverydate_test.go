@@ -0,0 +1,39 @@
+package bdds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeliveryDate(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+	for _, tt := range []struct {
+		name      string
+		published time.Time
+		want      string
+		week      int
+	}{
+		// Weekly names, published in the named week (2026/001 starts in 2025).
+		{"14.11 INPADOC - EPO worldwide legal event data 2026/001", time.Date(2025, 12, 30, 9, 0, 0, 0, cet), "2025-12-30", 1},
+		{"2026/023", time.Date(2026, 6, 2, 23, 30, 0, 0, cet), "2026-06-02", 23},
+		// Republished later: the named week wins.
+		{"2026/023", time.Date(2026, 7, 1, 9, 0, 0, 0, cet), "2026-06-01", 23},
+		{"2026/23", time.Time{}, "2026-06-01", 23},
+		{"2020/053", time.Time{}, "2020-12-28", 53},
+		// 2025 has no week 53: fall back to the publication day.
+		{"2025/053", time.Date(2026, 3, 18, 9, 0, 0, 0, cet), "2026-03-18", 12},
+		{"Backfile 2024-10-15", time.Date(2024, 10, 20, 9, 0, 0, 0, cet), "2024-10-15", 42},
+		{"PATSTAT 20241015", time.Time{}, "2024-10-15", 42},
+		{"NOTIFICATION READ ME", time.Date(2026, 3, 18, 17, 41, 0, 0, cet), "2026-03-18", 12},
+		{"NOTIFICATION READ ME", time.Time{}, "0001-01-01", 0},
+	} {
+		d := &Delivery{DeliveryName: tt.name, DeliveryPublicationDatetime: tt.published}
+		date, week := d.Date()
+		if got := date.Format(time.DateOnly); got != tt.want || week != tt.week {
+			t.Errorf("%q published %v: Date() = %s, week %d; want %s, week %d", tt.name, tt.published, got, week, tt.want, tt.week)
+		}
+		if date.Location() != time.UTC || date.Hour() != 0 {
+			t.Errorf("%q: Date() = %v, want midnight UTC", tt.name, date)
+		}
+	}
+}
@@ -111,10 +111,7 @@ func listProducts(ctx context.Context, client *bdds.Client) {
 	fmt.Println("----------------------------------------")
 
 	for _, p := range products {
-		fmt.Printf("ID: %d\n", p.ID)
-		fmt.Printf("Name: %s\n", p.Name)
-		fmt.Printf("Description: %s\n", p.Description)
-		fmt.Println("----------------------------------------")
+		fmt.Println(p.Summary())
 	}
 }
 
@@ -140,10 +137,8 @@ func getProductDetails(ctx context.Context, client *bdds.Client, reader *bufio.R
 	}
 
 	fmt.Println("\n=== Product Information ===")
-	fmt.Printf("ID: %d\n", product.ID)
-	fmt.Printf("Name: %s\n", product.Name)
-	fmt.Printf("Description: %s\n", product.Description)
-	fmt.Printf("\nDeliveries: %d\n", len(product.Deliveries))
+	fmt.Println(product.Summary())
+	fmt.Println(product.Description)
 	fmt.Println("----------------------------------------")
 
 	// Show first 10 deliveries
@@ -154,16 +149,7 @@ func getProductDetails(ctx context.Context, client *bdds.Client, reader *bufio.R
 
 	for i := 0; i < limit; i++ {
 		d := product.Deliveries[i]
-		fmt.Printf("\nDelivery #%d:\n", i+1)
-		fmt.Printf("  ID: %d\n", d.DeliveryID)
-		fmt.Printf("  Name: %s\n", d.DeliveryName)
-		fmt.Printf("  Published: %s\n", d.DeliveryPublicationDatetime.Format("2006-01-02 15:04:05"))
-		if d.DeliveryExpiryDatetime != nil {
-			fmt.Printf("  Expires: %s\n", d.DeliveryExpiryDatetime.Format("2006-01-02 15:04:05"))
-		} else {
-			fmt.Printf("  Expires: Never\n")
-		}
-		fmt.Printf("  Files: %d\n", len(d.Files))
+		fmt.Printf("\n%d. %s\n", i+1, d.Summary())
 
 		// Show first 3 files
 		fileLimit := len(d.Files)
@@ -171,8 +157,7 @@ func getProductDetails(ctx context.Context, client *bdds.Client, reader *bufio.R
 			fileLimit = 3
 		}
 		for j := 0; j < fileLimit; j++ {
-			f := d.Files[j]
-			fmt.Printf("    - %s (%s)\n", f.FileName, f.FileSize)
+			fmt.Printf("    - %s\n", d.Files[j].Summary())
 		}
 		if len(d.Files) > 3 {
 			fmt.Printf("    ... and %d more files\n", len(d.Files)-3)
@@ -205,9 +190,7 @@ func findProductByName(ctx context.Context, client *bdds.Client, reader *bufio.R
 	}
 
 	fmt.Println("\n=== Product Found ===")
-	fmt.Printf("ID: %d\n", product.ID)
-	fmt.Printf("Name: %s\n", product.Name)
-	fmt.Printf("Description: %s\n", product.Description)
+	fmt.Println(product.Summary())
 }
 
 func getLatestDelivery(ctx context.Context, client *bdds.Client, reader *bufio.Reader) {
@@ -232,24 +215,13 @@ func getLatestDelivery(ctx context.Context, client *bdds.Client, reader *bufio.R
 	}
 
 	fmt.Println("\n=== Latest Delivery ===")
-	fmt.Printf("ID: %d\n", delivery.DeliveryID)
-	fmt.Printf("Name: %s\n", delivery.DeliveryName)
-	fmt.Printf("Published: %s\n", delivery.DeliveryPublicationDatetime.Format("2006-01-02 15:04:05"))
-	if delivery.DeliveryExpiryDatetime != nil {
-		fmt.Printf("Expires: %s\n", delivery.DeliveryExpiryDatetime.Format("2006-01-02 15:04:05"))
-	} else {
-		fmt.Printf("Expires: Never\n")
-	}
-	fmt.Printf("\nFiles in this delivery: %d\n", len(delivery.Files))
+	fmt.Println(delivery.Summary())
 	fmt.Println("----------------------------------------")
 
 	// Show all files
 	for i, f := range delivery.Files {
-		fmt.Printf("%d. %s\n", i+1, f.FileName)
-		fmt.Printf("   Size: %s\n", f.FileSize)
+		fmt.Printf("%d. %s\n", i+1, f.Summary())
 		fmt.Printf("   Checksum: %s\n", f.FileChecksum)
-		fmt.Printf("   Published: %s\n", f.FilePublicationDatetime.Format("2006-01-02 15:04:05"))
-		fmt.Println()
 	}
 }
 
@@ -307,29 +279,20 @@ func downloadFile(ctx context.Context, client *bdds.Client, reader *bufio.Reader
 	defer func() { _ = file.Close() }()
 
 	startTime := time.Now()
-	var lastUpdate time.Time
 
 	err = client.DownloadFileWithProgress(ctx, productID, deliveryID, fileID, file,
-		func(bytesWritten, totalBytes int64) {
-			now := time.Now()
-			// Update every 500ms
-			if now.Sub(lastUpdate) > 500*time.Millisecond {
-				if totalBytes > 0 {
-					percent := float64(bytesWritten) * 100 / float64(totalBytes)
-					elapsed := now.Sub(startTime).Seconds()
-					speed := float64(bytesWritten) / elapsed / 1024 / 1024 // MB/s
-
-					fmt.Printf("\rProgress: %.1f%% | %.2f/%.2f MB | Speed: %.2f MB/s     ",
-						percent,
-						float64(bytesWritten)/1024/1024,
-						float64(totalBytes)/1024/1024,
-						speed)
-				} else {
-					fmt.Printf("\rDownloaded: %.2f MB     ", float64(bytesWritten)/1024/1024)
-				}
-				lastUpdate = now
+		bdds.ProgressFunc(func(p bdds.Progress) {
+			if p.TotalBytes > 0 {
+				fmt.Printf("\rProgress: %.1f%% | %.2f/%.2f MB | Speed: %.2f MB/s | ETA: %s     ",
+					p.Percent(),
+					float64(p.BytesTransferred)/1024/1024,
+					float64(p.TotalBytes)/1024/1024,
+					p.Speed/1024/1024,
+					p.ETA.Round(time.Second))
+			} else {
+				fmt.Printf("\rDownloaded: %.2f MB     ", float64(p.BytesTransferred)/1024/1024)
 			}
-		})
+		}, 500*time.Millisecond))
 
 	fmt.Println() // New line after progress
 
@@ -6,7 +6,7 @@ require github.com/patent-dev/epo-bdds v0.0.0
 
 require (
 	github.com/apapsch/go-jsonmerge/v2 v2.0.0 // indirect
-	github.com/google/uuid v1.5.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/oapi-codegen/runtime v1.1.2 // indirect
 )
 
@@ -0,0 +1,142 @@
+package bdds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// CatalogDiff is the difference between two catalog snapshots of a product,
+// as reported by DiffDeliveries.
+type CatalogDiff struct {
+	Added   []*Delivery       // deliveries only in the new snapshot
+	Removed []*Delivery       // deliveries only in the old snapshot
+	Expired []*Delivery       // deliveries still listed whose expiry has passed
+	Changed []*DeliveryChange // deliveries in both snapshots whose files differ
+}
+
+// Empty reports whether the snapshots list the same deliveries and files and
+// nothing has expired.
+func (d *CatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Expired) == 0 && len(d.Changed) == 0
+}
+
+// DeliveryChange lists the file differences of a delivery present in both
+// snapshots. Files are matched by ID.
+type DeliveryChange struct {
+	Delivery *Delivery // as in the new snapshot
+	Added    []*DeliveryFile
+	Removed  []*DeliveryFile
+	Changed  []FileChange // same file ID, different name, size or checksum
+}
+
+// FileChange is a file that was re-published with different content.
+type FileChange struct {
+	Old *DeliveryFile
+	New *DeliveryFile
+}
+
+// DiffDeliveries compares two catalog snapshots of a product, for example
+// the one saved by SaveCatalogSnapshot on the previous run and the current
+// GetProduct result. A nil old snapshot is treated as empty, so every
+// delivery of the current one is reported as added. Deliveries are matched by
+// ID and keep the order of their snapshot.
+func DiffDeliveries(old, current *ProductWithDeliveries) *CatalogDiff {
+	diff := &CatalogDiff{}
+	oldByID := make(map[int]*Delivery)
+	if old != nil {
+		for _, d := range old.Deliveries {
+			oldByID[d.DeliveryID] = d
+		}
+	}
+	newIDs := make(map[int]bool)
+	now := time.Now()
+	if current != nil {
+		for _, d := range current.Deliveries {
+			newIDs[d.DeliveryID] = true
+			if d.DeliveryExpiryDatetime != nil && !d.DeliveryExpiryDatetime.After(now) {
+				diff.Expired = append(diff.Expired, d)
+			}
+			prev, ok := oldByID[d.DeliveryID]
+			if !ok {
+				diff.Added = append(diff.Added, d)
+				continue
+			}
+			if change := diffFiles(prev, d); change != nil {
+				diff.Changed = append(diff.Changed, change)
+			}
+		}
+	}
+	if old != nil {
+		for _, d := range old.Deliveries {
+			if !newIDs[d.DeliveryID] {
+				diff.Removed = append(diff.Removed, d)
+			}
+		}
+	}
+	return diff
+}
+
+// diffFiles returns the file differences between two versions of a
+// delivery, or nil if there are none.
+func diffFiles(old, current *Delivery) *DeliveryChange {
+	change := &DeliveryChange{Delivery: current}
+	oldByID := make(map[int]*DeliveryFile, len(old.Files))
+	for _, f := range old.Files {
+		oldByID[f.FileID] = f
+	}
+	newIDs := make(map[int]bool, len(current.Files))
+	for _, f := range current.Files {
+		newIDs[f.FileID] = true
+		prev, ok := oldByID[f.FileID]
+		switch {
+		case !ok:
+			change.Added = append(change.Added, f)
+		case prev.FileName != f.FileName || prev.FileSize != f.FileSize || !strings.EqualFold(prev.FileChecksum, f.FileChecksum):
+			change.Changed = append(change.Changed, FileChange{Old: prev, New: f})
+		}
+	}
+	for _, f := range old.Files {
+		if !newIDs[f.FileID] {
+			change.Removed = append(change.Removed, f)
+		}
+	}
+	if len(change.Added) == 0 && len(change.Removed) == 0 && len(change.Changed) == 0 {
+		return nil
+	}
+	return change
+}
+
+// SaveCatalogSnapshot writes a product catalog, as returned by GetProduct,
+// to path as JSON. The file is replaced atomically.
+func SaveCatalogSnapshot(path string, product *ProductWithDeliveries) error {
+	data, err := json.MarshalIndent(product, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog snapshot: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write catalog snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadCatalogSnapshot reads a catalog written by SaveCatalogSnapshot. It
+// returns nil without an error if the file does not exist, which
+// DiffDeliveries treats as an empty catalog.
+func LoadCatalogSnapshot(path string) (*ProductWithDeliveries, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog snapshot: %w", err)
+	}
+	var product ProductWithDeliveries
+	if err := json.Unmarshal(data, &product); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog snapshot %s: %w", path, err)
+	}
+	return &product, nil
+}
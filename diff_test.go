@@ -0,0 +1,102 @@
+package bdds
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffDeliveries(t *testing.T) {
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	expired := time.Now().Add(-time.Hour)
+	file := func(id int, checksum string) *DeliveryFile {
+		return &DeliveryFile{FileID: id, FileName: "f.zip", FileSize: "1 MB", FileChecksum: checksum}
+	}
+	old := &ProductWithDeliveries{ID: 3, Deliveries: []*Delivery{
+		{DeliveryID: 1, DeliveryPublicationDatetime: published, Files: []*DeliveryFile{file(101, "AA")}},
+		{DeliveryID: 2, Files: []*DeliveryFile{file(201, "AA"), file(202, "BB")}},
+		{DeliveryID: 3, Files: []*DeliveryFile{file(301, "CC")}},
+		{DeliveryID: 4, Files: []*DeliveryFile{file(401, "DD")}},
+	}}
+	current := &ProductWithDeliveries{ID: 3, Deliveries: []*Delivery{
+		{DeliveryID: 1, DeliveryPublicationDatetime: published, Files: []*DeliveryFile{file(101, "aa")}},
+		{DeliveryID: 2, Files: []*DeliveryFile{file(201, "EE"), file(203, "FF")}},
+		{DeliveryID: 3, DeliveryExpiryDatetime: &expired, Files: []*DeliveryFile{file(301, "CC")}},
+		{DeliveryID: 5, Files: []*DeliveryFile{file(501, "GG")}},
+	}}
+
+	diff := DiffDeliveries(old, current)
+	ids := func(ds []*Delivery) []int {
+		var out []int
+		for _, d := range ds {
+			out = append(out, d.DeliveryID)
+		}
+		return out
+	}
+	if got := ids(diff.Added); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("Added = %v, want [5]", got)
+	}
+	if got := ids(diff.Removed); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("Removed = %v, want [4]", got)
+	}
+	if got := ids(diff.Expired); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("Expired = %v, want [3]", got)
+	}
+	// Delivery 1 only differs in checksum case.
+	if len(diff.Changed) != 1 {
+		t.Fatalf("expected 1 changed delivery, got %d", len(diff.Changed))
+	}
+	change := diff.Changed[0]
+	if change.Delivery.DeliveryID != 2 {
+		t.Errorf("changed delivery = %d, want 2", change.Delivery.DeliveryID)
+	}
+	if len(change.Changed) != 1 || change.Changed[0].Old.FileChecksum != "AA" || change.Changed[0].New.FileChecksum != "EE" {
+		t.Errorf("changed files = %+v", change.Changed)
+	}
+	if len(change.Added) != 1 || change.Added[0].FileID != 203 {
+		t.Errorf("added files = %+v", change.Added)
+	}
+	if len(change.Removed) != 1 || change.Removed[0].FileID != 202 {
+		t.Errorf("removed files = %+v", change.Removed)
+	}
+	if diff.Empty() {
+		t.Error("Empty() = true for a non-empty diff")
+	}
+	if d := DiffDeliveries(old, old); !d.Empty() {
+		t.Errorf("diff of a snapshot with itself = %+v", d)
+	}
+	if d := DiffDeliveries(nil, current); len(d.Added) != len(current.Deliveries) {
+		t.Errorf("diff against nil added %d deliveries, want %d", len(d.Added), len(current.Deliveries))
+	}
+}
+
+func TestCatalogSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	product, err := LoadCatalogSnapshot(path)
+	if err != nil || product != nil {
+		t.Fatalf("LoadCatalogSnapshot of a missing file = %v, %v", product, err)
+	}
+
+	expiry := time.Now().AddDate(0, 3, 0).Truncate(time.Second).UTC()
+	product = &ProductWithDeliveries{ID: 3, Name: "Test product", Deliveries: []*Delivery{{
+		DeliveryID:                  7,
+		DeliveryName:                "2024-10-15",
+		DeliveryPublicationDatetime: time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC),
+		DeliveryExpiryDatetime:      &expiry,
+		Files:                       []*DeliveryFile{{FileID: 701, FileName: "a.zip", FileSize: "1 MB", FileChecksum: "AA"}},
+	}}}
+	if err := SaveCatalogSnapshot(path, product); err != nil {
+		t.Fatalf("SaveCatalogSnapshot: %v", err)
+	}
+	loaded, err := LoadCatalogSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadCatalogSnapshot: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, product) {
+		t.Errorf("loaded snapshot = %+v, want %+v", loaded, product)
+	}
+	if d := DiffDeliveries(loaded, product); !d.Empty() {
+		t.Errorf("diff after round trip = %+v", d)
+	}
+}
@@ -0,0 +1,58 @@
+package bdds
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// checkDiskSpace fails with an InsufficientSpaceError when the file system
+// holding dir has less than required bytes available. dir need not exist yet;
+// its nearest existing ancestor is measured. Platforms without a free-space
+// query skip the check.
+func checkDiskSpace(dir string, required int64) error {
+	if required <= 0 {
+		return nil
+	}
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+
+	available, ok, err := availableSpace(path)
+	if err != nil || !ok {
+		return err
+	}
+	if available < required {
+		return &InsufficientSpaceError{Path: dir, Required: required, Available: available}
+	}
+	return nil
+}
+
+// requiredSpace sums the catalog sizes of the files to download. With
+// skipExisting, files already present with a matching size are not counted,
+// so re-running a mirror does not require room for all of it again.
+func requiredSpace(jobs []*FileResult, skipExisting bool) int64 {
+	var total int64
+	for _, job := range jobs {
+		if skipExisting {
+			if info, err := os.Stat(job.Path); err == nil && fileSizeMatches(info.Size(), job.File.FileSize) {
+				continue
+			}
+		}
+		total += parseFileSize(job.File.FileSize)
+	}
+	return total
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package bdds
+
+// availableSpace is not implemented on this platform; the disk space check is
+// skipped.
+func availableSpace(string) (int64, bool, error) {
+	return 0, false, nil
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd
+
+package bdds
+
+import "syscall"
+
+// availableSpace returns the bytes available to unprivileged users on the
+// file system holding path.
+func availableSpace(path string) (int64, bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, false, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), true, nil
+}
@@ -0,0 +1,53 @@
+package bdds
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCheckDiskSpace(t *testing.T) {
+	if _, ok, _ := availableSpace(t.TempDir()); !ok {
+		t.Skipf("free space query not supported on %s", runtime.GOOS)
+	}
+	dir := filepath.Join(t.TempDir(), "not", "created", "yet")
+
+	if err := checkDiskSpace(dir, 1); err != nil {
+		t.Errorf("1 byte should fit: %v", err)
+	}
+
+	jobs := []*FileResult{
+		{Path: filepath.Join(dir, "a"), File: &DeliveryFile{FileSize: "500 TB"}},
+		{Path: filepath.Join(dir, "b"), File: &DeliveryFile{FileSize: "600 TB"}},
+	}
+	err := checkDiskSpace(dir, requiredSpace(jobs, true))
+	var space *InsufficientSpaceError
+	if !errors.As(err, &space) {
+		t.Fatalf("expected *InsufficientSpaceError, got %v", err)
+	}
+	if space.Required != 1.1e15 || space.Available <= 0 || space.Path != dir {
+		t.Errorf("unexpected error fields: %+v", space)
+	}
+}
+
+// TestRequiredSpaceSkipExisting verifies files already present are not counted
+// when they will be skipped.
+func TestRequiredSpaceSkipExisting(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "present.zip")
+	if err := os.WriteFile(present, []byte("0123456789"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jobs := []*FileResult{
+		{Path: present, File: &DeliveryFile{FileSize: "10 B"}},
+		{Path: filepath.Join(dir, "missing.zip"), File: &DeliveryFile{FileSize: "2 kB"}},
+	}
+	if got := requiredSpace(jobs, false); got != 2010 {
+		t.Errorf("requiredSpace without skip = %d, want 2010", got)
+	}
+	if got := requiredSpace(jobs, true); got != 2000 {
+		t.Errorf("requiredSpace with skip = %d, want 2000", got)
+	}
+}
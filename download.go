@@ -0,0 +1,157 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// partialSuffix marks an incomplete download next to its final path.
+	partialSuffix = ".part"
+	// partialValidatorSuffix marks the validator of a partial download.
+	partialValidatorSuffix = partialSuffix + ".validator"
+)
+
+// resumableDestination is a download destination that keeps its content
+// across calls, e.g. a partial file. Kept content is only resumed behind an
+// If-Range with the validator of the response it came from.
+type resumableDestination interface {
+	resumeValidator() string
+	setResumeValidator(v string) error
+}
+
+// responseValidator returns the validator to resume a response's content
+// with: its ETag, unless weak, or else its Last-Modified date.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// DownloadFileToPath downloads a file to path atomically: the content is
+// written to "<path>.part", synced, and renamed over path only once the
+// download has completed, so a mirror never mistakes a half-written archive
+// for a complete one. If the download fails part-way, the .part file is kept
+// and a later download of the same path resumes from its last byte with a
+// Range request, provided the server identifies the file with an ETag or
+// Last-Modified date: a file that has changed since is downloaded afresh.
+// Missing parent directories are created. Use CleanPartials to remove
+// abandoned .part files.
+//
+// Concurrent downloads to the same path are not supported.
+func (c *Client) DownloadFileToPath(ctx context.Context, productID, deliveryID, fileID int, path string, opts ...CallOption) error {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+	_, err := c.downloadToPath(ctx, productID, deliveryID, fileID, path, "", nil)
+	return err
+}
+
+// downloadToPath implements DownloadFileToPath with an optional progress
+// callback and returns the number of bytes written.
+func (c *Client) downloadToPath(ctx context.Context, productID, deliveryID, fileID int, path, catalogSize string, progressFn func(bytesWritten, totalBytes int64)) (int64, error) {
+	part, err := openPartial(path)
+	if err != nil {
+		return 0, err
+	}
+	return c.downloadToSinkFile(ctx, productID, deliveryID, fileID, part, catalogSize, progressFn)
+}
+
+// downloadToSinkFile downloads a file into w and commits it, or aborts it on
+// failure. A partial file is resumed from its kept content if unchanged. When the catalog
+// size of the file is known, a download that falls short of it is rejected
+// with a TruncatedDownloadError instead of being committed.
+func (c *Client) downloadToSinkFile(ctx context.Context, productID, deliveryID, fileID int, w SinkFile, catalogSize string, progressFn func(bytesWritten, totalBytes int64)) (int64, error) {
+	var offset int64
+	part, isPart := w.(*partialFile)
+	if isPart {
+		offset = part.offset
+	}
+
+	n, err := c.downloadFrom(ctx, productID, deliveryID, fileID, w, offset, progressFn)
+	resumable := true
+	if err != nil {
+		var notFound *NotFoundError
+		resumable = !errors.As(err, &notFound)
+	} else if expected := parseFileSize(catalogSize); expected > n && !fileSizeMatches(n, catalogSize) {
+		// The transfer itself completed, so resuming it would not help.
+		err, resumable = &TruncatedDownloadError{Expected: expected, Received: n}, false
+	}
+	if err != nil {
+		if isPart && !resumable {
+			_ = part.discard()
+		} else {
+			_ = w.Abort()
+		}
+		return 0, err
+	}
+	if err := w.Commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// DownloadFileStream opens a file download and returns its body for the caller
+// to read, e.g. to feed a custom unzip or parse pipeline. Authentication and
+// retries apply until the response arrives; errors while reading the body are
+// returned by Read as is (a body cut short of its Content-Length fails with
+// io.ErrUnexpectedEOF). The caller must close the returned reader; a
+// WithTimeout deadline also covers reading it.
+func (c *Client) DownloadFileStream(ctx context.Context, productID, deliveryID, fileID int, opts ...CallOption) (io.ReadCloser, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	var body io.ReadCloser
+	err := c.retryableRequest(ctx, func() error {
+		resp, err := c.generatedClient.DownloadFile(ctx, productID, deliveryID, fileID)
+		if err != nil {
+			return err
+		}
+		if err := downloadResponseError(resp, productID, deliveryID, fileID); err != nil {
+			_ = resp.Body.Close()
+			return err
+		}
+		body = resp.Body
+		return nil
+	})
+	if err != nil {
+		cancel()
+		c.stats.recordFile(err)
+		return nil, err
+	}
+	c.logger().DebugContext(ctx, "bdds: download stream opened", "product", productID, "delivery", deliveryID, "file", fileID)
+	body = &streamStatsBody{ReadCloser: body, stats: c.stats}
+	return &cancelOnClose{ReadCloser: body, cancel: cancel}, nil
+}
+
+// CleanPartials removes the ".part" files of incomplete downloads below dir,
+// with their validators, and returns their paths. Run it when abandoned downloads should not be
+// resumed, e.g. before re-verifying a mirror.
+func CleanPartials(dir string) ([]string, error) {
+	var removed []string
+	err := filepath.WalkDir(dir, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !de.Type().IsRegular() {
+			return nil
+		}
+		switch {
+		case strings.HasSuffix(de.Name(), partialSuffix):
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed = append(removed, path)
+		case strings.HasSuffix(de.Name(), partialValidatorSuffix):
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
@@ -0,0 +1,361 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// assertNoTempFiles fails if dir contains leftover partial download files.
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, _ := filepath.Glob(filepath.Join(dir, "*"+partialSuffix))
+	if len(matches) != 0 {
+		t.Errorf("leftover temporary files: %v", matches)
+	}
+}
+
+// TestDownloadFileToPath verifies a successful download lands at the target
+// path, creating parent directories, without leftovers.
+func TestDownloadFileToPath(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{3: []byte("archive bytes")})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := filepath.Join(t.TempDir(), "2024-10-15")
+	path := filepath.Join(dir, "docdb.zip")
+	if err := client.DownloadFileToPath(context.Background(), 1, 2, 3, path); err != nil {
+		t.Fatalf("DownloadFileToPath: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "archive bytes" {
+		t.Errorf("content = %q, %v", got, err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// TestDownloadFileToPathFailureKeepsTarget verifies a failed download leaves an
+// existing target untouched and no empty partial file behind.
+func TestDownloadFileToPathFailureKeepsTarget(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docdb.zip")
+	if err := os.WriteFile(path, []byte("previous complete copy"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := client.DownloadFileToPath(context.Background(), 1, 2, 404, path)
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "previous complete copy" {
+		t.Errorf("existing target was modified: %q", got)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// TestDownloadTruncatedContentLength verifies a body shorter than its
+// Content-Length surfaces as a TruncatedDownloadError once retries run out.
+func TestDownloadTruncatedContentLength(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	content := []byte("a body that is always cut off after ten bytes")
+	apiServer, apiCalls := newPartialDownloadServer(t, content, 10, 100)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	var buf bytes.Buffer
+	err := client.DownloadFile(context.Background(), 1, 2, 3, &seekableBuffer{&buf})
+	var truncated *TruncatedDownloadError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("expected *TruncatedDownloadError, got %v", err)
+	}
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("%v does not match ErrTruncated", err)
+	}
+	if truncated.Expected != int64(len(content)) || truncated.Received != 10 {
+		t.Errorf("got %d of %d bytes, want 10 of %d", truncated.Received, truncated.Expected, len(content))
+	}
+	if c := atomic.LoadInt32(apiCalls); c != 4 {
+		t.Errorf("expected truncation to be retried (4 attempts), got %d", c)
+	}
+}
+
+// TestDownloadToPathShortOfCatalogSize verifies a download that completes at
+// the HTTP level but falls short of the catalog size is not moved into place.
+func TestDownloadToPathShortOfCatalogSize(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{3: []byte("only ten b")})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	path := filepath.Join(t.TempDir(), "file.zip")
+
+	_, err := client.downloadToPath(context.Background(), 1, 2, 3, path, "1.5 kB", nil)
+	var truncated *TruncatedDownloadError
+	if !errors.As(err, &truncated) || truncated.Expected != 1500 || truncated.Received != 10 {
+		t.Fatalf("expected truncation of 10/1500 bytes, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("truncated download was moved into place")
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+// seekableBuffer is an in-memory destination that supports rewinding.
+type seekableBuffer struct{ buf *bytes.Buffer }
+
+func (s *seekableBuffer) Write(p []byte) (int, error) { return s.buf.Write(p) }
+func (s *seekableBuffer) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		return 0, errors.New("only rewinding is supported")
+	}
+	s.buf.Reset()
+	return 0, nil
+}
+
+// TestDownloadFileToPathResumesPartial verifies an interrupted download keeps
+// its .part file and the next download of the path resumes from it.
+func TestDownloadFileToPathResumesPartial(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	content := []byte("archive content that arrives in two separate runs")
+	apiServer, ranges := newResumableDownloadServer(t, content, 10, true)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	client.config.MaxRetries = 0 // let the first run fail after the cut
+	path := filepath.Join(t.TempDir(), "docdb.zip")
+
+	if err := client.DownloadFileToPath(context.Background(), 1, 2, 3, path); err == nil {
+		t.Fatal("expected the interrupted first run to fail")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("incomplete download visible at the final path")
+	}
+	part, _ := os.ReadFile(path + partialSuffix)
+	if !bytes.Equal(part, content[:10]) {
+		t.Fatalf(".part holds %q, want the first 10 bytes", part)
+	}
+
+	if err := client.DownloadFileToPath(context.Background(), 1, 2, 3, path); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Errorf("content = %q, want %q", got, content)
+	}
+	if r := *ranges; len(r) != 2 || r[1] != "bytes=10-" {
+		t.Errorf("expected the second run to resume at byte 10, got ranges %q", r)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+// newValidatingServer serves content with etag and full Range support, and
+// records the Range header of every request.
+func newValidatingServer(content, etag string) (*httptest.Server, *[]string) {
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "file.zip", time.Time{}, strings.NewReader(content))
+	}))
+	return srv, &ranges
+}
+
+// writePartial leaves a partial download of path with content and, unless
+// empty, validator.
+func writePartial(t *testing.T, path, content, validator string) {
+	t.Helper()
+	if err := os.WriteFile(path+partialSuffix, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if validator != "" {
+		if err := os.WriteFile(path+partialValidatorSuffix, []byte(validator), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestDownloadFileToPathRestartsStalePartial verifies a kept .part is only
+// resumed while the file is unchanged: one from an older version of the
+// file, or of unknown origin, is downloaded afresh.
+func TestDownloadFileToPathRestartsStalePartial(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	const content = "NEW archive content, longer than the old one"
+
+	for _, tt := range []struct {
+		name, validator, wantRange string
+	}{
+		{"changed", `"old"`, "bytes=9-"},
+		{"unknown origin", "", ""},
+		{"unchanged", `"new"`, "bytes=9-"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			apiServer, ranges := newValidatingServer(content, `"new"`)
+			defer apiServer.Close()
+			client := newTestClient(t, apiServer.URL, authServer.URL)
+
+			path := filepath.Join(t.TempDir(), "docdb.zip")
+			kept := "OLD-short"
+			if tt.validator == `"new"` {
+				kept = content[:9]
+			}
+			writePartial(t, path, kept, tt.validator)
+
+			if err := client.DownloadFileToPath(context.Background(), 1, 2, 3, path); err != nil {
+				t.Fatalf("DownloadFileToPath: %v", err)
+			}
+			if got, _ := os.ReadFile(path); string(got) != content {
+				t.Errorf("content = %q, want %q", got, content)
+			}
+			if r := *ranges; len(r) != 1 || r[0] != tt.wantRange {
+				t.Errorf("ranges = %q, want [%q]", r, tt.wantRange)
+			}
+			for _, p := range []string{path + partialSuffix, path + partialValidatorSuffix} {
+				if _, err := os.Stat(p); !os.IsNotExist(err) {
+					t.Errorf("%s left behind", p)
+				}
+			}
+		})
+	}
+}
+
+// TestDownloadFileToPathRestartsOversizedPartial verifies a kept .part longer
+// than the file, which the server answers with 416, is replaced by a fresh
+// download instead of failing every later download of the path.
+func TestDownloadFileToPathRestartsOversizedPartial(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	const content = "short archive"
+	apiServer, ranges := newValidatingServer(content, `"v1"`)
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	path := filepath.Join(t.TempDir(), "docdb.zip")
+	writePartial(t, path, "a stale partial longer than the file", `"v1"`)
+
+	if err := client.DownloadFileToPath(context.Background(), 1, 2, 3, path); err != nil {
+		t.Fatalf("DownloadFileToPath: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != content {
+		t.Errorf("content = %q, want %q", got, content)
+	}
+	if r := *ranges; len(r) != 2 || r[0] != "bytes=36-" || r[1] != "" {
+		t.Errorf("ranges = %q, want a rejected resume and a full download", r)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestCleanPartials(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "2024-10-15")
+	if err := os.MkdirAll(sub, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.zip", "b.zip.part", "b.zip.part.validator", filepath.Join("2024-10-15", "c.zip.part")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removed, err := CleanPartials(dir)
+	if err != nil {
+		t.Fatalf("CleanPartials: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("removed %v, want the two .part files", removed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.zip")); err != nil {
+		t.Error("complete file was removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.zip.part.validator")); !os.IsNotExist(err) {
+		t.Error("validator of a removed partial was kept")
+	}
+}
+
+// TestDownloadFileStream verifies the stream retries until the response
+// arrives, returns the body as is, and maps a missing file to NotFoundError.
+func TestDownloadFileStream(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	var calls int32
+	files := newFileServer(t, map[int][]byte{3: []byte("streamed archive")})
+	defer files.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		files.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	body, err := client.DownloadFileStream(context.Background(), 1, 2, 3)
+	if err != nil {
+		t.Fatalf("DownloadFileStream: %v", err)
+	}
+	got, err := io.ReadAll(body)
+	_ = body.Close()
+	if err != nil || string(got) != "streamed archive" {
+		t.Errorf("content = %q, %v", got, err)
+	}
+	if c := atomic.LoadInt32(&calls); c != 2 {
+		t.Errorf("expected the 503 to be retried (2 requests), got %d", c)
+	}
+
+	_, err = client.DownloadFileStream(context.Background(), 1, 2, 404)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+// TestDownloadFileStreamTruncated verifies a body cut short mid-read fails
+// with io.ErrUnexpectedEOF instead of ending silently.
+func TestDownloadFileStreamTruncated(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer, _ := newPartialDownloadServer(t, []byte("a body that is cut off"), 5, 1)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	body, err := client.DownloadFileStream(context.Background(), 1, 2, 3)
+	if err != nil {
+		t.Fatalf("DownloadFileStream: %v", err)
+	}
+	defer func() { _ = body.Close() }()
+	if _, err := io.ReadAll(body); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package bdds
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// dumpHeaders are not dumped at all, as redacting them partially would
+// still leak session state.
+var dumpHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// dumpTransport writes the headers of each request and its response to
+// Config.DebugDump, and the bodies of catalog and OAuth responses with
+// Config.DebugDumpBodies.
+type dumpTransport struct {
+	base   http.RoundTripper
+	w      io.Writer
+	bodies bool
+	mu     sync.Mutex // serializes the dumps of concurrent requests
+}
+
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "> %s %s %s\n", req.Method, redactSecrets(req.URL.Redacted()), req.Proto)
+	dumpHeader(&b, "> ", req.Header)
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&b, "< error: %v\n\n", redactURLError(err))
+		t.write(b.String())
+		return nil, err
+	}
+	fmt.Fprintf(&b, "< %s %s\n", resp.Proto, resp.Status)
+	dumpHeader(&b, "< ", resp.Header)
+	if t.bodies && !downloadRequestPath.MatchString(req.URL.Path) {
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			fmt.Fprintf(&b, "< error reading body: %v\n\n", err)
+			t.write(b.String())
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		fmt.Fprintf(&b, "%s\n", redactSecrets(string(body)))
+	}
+	b.WriteString("\n")
+	t.write(b.String())
+	return resp, nil
+}
+
+func (t *dumpTransport) write(s string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = io.WriteString(t.w, s)
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *dumpTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// dumpHeader writes h sorted by name, one value per line, with credentials
+// redacted.
+func dumpHeader(b *strings.Builder, prefix string, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		for _, v := range h[key] {
+			if slices.Contains(dumpHeaders, http.CanonicalHeaderKey(key)) {
+				v = redacted
+			}
+			fmt.Fprintf(b, "%s%s: %s\n", prefix, key, redactSecrets(v))
+		}
+	}
+	b.WriteString(prefix + "\n")
+}
@@ -0,0 +1,64 @@
+package bdds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDebugDump verifies requests and responses are dumped with their
+// catalog and OAuth bodies and without credentials.
+func TestDebugDump(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc123")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"no subscription"}`))
+	}))
+	defer apiServer.Close()
+
+	var dump syncBuffer
+	client, err := NewClient(&Config{
+		Username:        "u",
+		Password:        "secret-password",
+		BaseURL:         apiServer.URL,
+		RetryDelay:      time.Millisecond,
+		DebugDump:       &dump,
+		DebugDumpBodies: true,
+		Transport: &testTransport{
+			authURL: authServer.URL + "/oauth2/aus3up3nz0N133c0V417/v1/token",
+			rt:      http.DefaultTransport,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.ListProducts(context.Background()); err == nil {
+		t.Fatal("ListProducts succeeded")
+	}
+
+	out := dump.String()
+	for _, want := range []string{
+		"> POST https://login.epo.org/oauth2/aus3up3nz0N133c0V417/v1/token",
+		`"access_token":"[REDACTED]"`,
+		"> GET " + apiServer.URL + "/bdds/bdds-bff-service/prod/api/products",
+		"> Authorization: [REDACTED]\n",
+		"< HTTP/1.1 403 Forbidden\n",
+		"< Set-Cookie: [REDACTED]\n",
+		`{"message":"no subscription"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dump lacks %q:\n%s", want, out)
+		}
+	}
+	for _, secret := range []string{"secret-password", "Bearer token-", "abc123", client.config.ClientID} {
+		if strings.Contains(out, secret) {
+			t.Errorf("dump leaks %q:\n%s", secret, out)
+		}
+	}
+}
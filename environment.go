@@ -0,0 +1,84 @@
+package bdds
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Environment is a BDDS deployment: the API and OAuth endpoints that belong
+// together. Config.Environment selects one by name.
+type Environment struct {
+	BaseURL     string
+	ServicePath string
+	APIVersion  string
+	TokenURL    string
+	RevokeURL   string
+	ClientID    string
+}
+
+// ProductionEnvironment names the public EPO BDDS service, the default.
+const ProductionEnvironment = "production"
+
+var production = Environment{
+	BaseURL:     "https://publication-bdds.apps.epo.org",
+	ServicePath: "/bdds/bdds-bff-service",
+	APIVersion:  "prod",
+	TokenURL:    "https://login.epo.org/oauth2/aus3up3nz0N133c0V417/v1/token",
+	RevokeURL:   "https://login.epo.org/oauth2/aus3up3nz0N133c0V417/v1/revoke",
+	ClientID:    "MG9hM3VwZG43YW41cE1JOE80MTc=",
+}
+
+var (
+	environmentsMu sync.RWMutex
+	environments   = map[string]Environment{ProductionEnvironment: production}
+)
+
+// RegisterEnvironment makes an environment, such as an EPO acceptance
+// instance, selectable by name. Empty fields are taken from production.
+func RegisterEnvironment(name string, env Environment) {
+	fill := func(field *string, def string) {
+		if *field == "" {
+			*field = def
+		}
+	}
+	fill(&env.BaseURL, production.BaseURL)
+	fill(&env.ServicePath, production.ServicePath)
+	fill(&env.APIVersion, production.APIVersion)
+	fill(&env.TokenURL, production.TokenURL)
+	fill(&env.RevokeURL, production.RevokeURL)
+	fill(&env.ClientID, production.ClientID)
+
+	environmentsMu.Lock()
+	defer environmentsMu.Unlock()
+	environments[name] = env
+}
+
+// LookupEnvironment returns a registered environment.
+func LookupEnvironment(name string) (Environment, bool) {
+	environmentsMu.RLock()
+	defer environmentsMu.RUnlock()
+	env, ok := environments[name]
+	return env, ok
+}
+
+// applyEnvironment sets the endpoint fields of cfg that are empty or at
+// their production defaults, as in DefaultConfig, to those of the named
+// environment.
+func applyEnvironment(cfg *Config, name string) error {
+	env, ok := LookupEnvironment(name)
+	if !ok {
+		return fmt.Errorf("unknown environment %q", name)
+	}
+	set := func(field *string, value, def string) {
+		if *field == "" || *field == def {
+			*field = value
+		}
+	}
+	set(&cfg.BaseURL, env.BaseURL, production.BaseURL)
+	set(&cfg.ServicePath, env.ServicePath, production.ServicePath)
+	set(&cfg.APIVersion, env.APIVersion, production.APIVersion)
+	set(&cfg.TokenURL, env.TokenURL, production.TokenURL)
+	set(&cfg.RevokeURL, env.RevokeURL, production.RevokeURL)
+	set(&cfg.ClientID, env.ClientID, production.ClientID)
+	return nil
+}
@@ -0,0 +1,46 @@
+package bdds
+
+import (
+	"testing"
+)
+
+func TestEnvironment(t *testing.T) {
+	RegisterEnvironment("acceptance-test", Environment{
+		BaseURL:    "https://bdds-acc.example.com",
+		APIVersion: "acc",
+		TokenURL:   "https://login-acc.example.com/v1/token",
+	})
+
+	env, ok := LookupEnvironment("acceptance-test")
+	if !ok || env.ServicePath != DefaultConfig().ServicePath || env.ClientID == "" {
+		t.Fatalf("registered environment = %+v, %v; want empty fields from production", env, ok)
+	}
+
+	for name, cfg := range map[string]*Config{
+		"zero config":    {Environment: "acceptance-test"},
+		"DefaultConfig":  func() *Config { c := DefaultConfig(); c.Environment = "acceptance-test"; return c }(),
+		"explicit field": {Environment: "acceptance-test", APIVersion: "acc2"},
+	} {
+		client, err := NewClient(cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		wantVersion := "acc"
+		if name == "explicit field" {
+			wantVersion = "acc2"
+		}
+		got := client.config
+		if got.BaseURL != "https://bdds-acc.example.com" || got.APIVersion != wantVersion ||
+			got.TokenURL != "https://login-acc.example.com/v1/token" || got.RevokeURL != DefaultConfig().RevokeURL {
+			t.Errorf("%s: endpoints = %s %s %s %s", name, got.BaseURL, got.APIVersion, got.TokenURL, got.RevokeURL)
+		}
+	}
+
+	if _, err := NewClient(&Config{Environment: "staging"}); err == nil {
+		t.Error("unknown environment accepted")
+	}
+	client, err := NewClient(&Config{Environment: ProductionEnvironment})
+	if err != nil || client.config.BaseURL != DefaultConfig().BaseURL {
+		t.Errorf("production environment: %v", err)
+	}
+}
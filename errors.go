@@ -1,46 +1,185 @@
 package bdds
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for the common failure classes. Errors returned by the
+// client match them with errors.Is, also when wrapped by the retry loop,
+// a bulk download or a circuit breaker; errors.As still yields the typed
+// error with its details.
+var (
+	// ErrNotFound matches a *NotFoundError and other 404 responses.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized matches an *AuthError (rejected credentials or
+	// token) and 403 responses for resources the account may not read.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRateLimited matches a *RateLimitError.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrTruncated matches a *TruncatedDownloadError.
+	ErrTruncated = errors.New("download truncated")
+)
 
 // AuthError represents an authentication error
 type AuthError struct {
 	StatusCode int
 	Message    string
+	RequestID  string // see RequestID
+
+	token string // the rejected access token, if any
 }
 
 func (e *AuthError) Error() string {
-	return fmt.Sprintf("authentication failed (status %d): %s", e.StatusCode, e.Message)
+	return fmt.Sprintf("authentication failed (status %d): %s", e.StatusCode, e.Message) + requestIDSuffix(e.RequestID)
+}
+
+// Is reports whether target is ErrUnauthorized.
+func (e *AuthError) Is(target error) bool { return target == ErrUnauthorized }
+
+// ConfigError reports an invalid Config field. Config.Validate returns one
+// for each problem it finds, joined with errors.Join.
+type ConfigError struct {
+	Field  string // e.g. "BaseURL" or "Accounts[1].Password"
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("config: %s: %s", e.Field, e.Reason)
 }
 
 // NotFoundError represents a resource not found error
 type NotFoundError struct {
-	Resource string
-	ID       string
+	Resource  string
+	ID        string
+	RequestID string // see RequestID
 }
 
 func (e *NotFoundError) Error() string {
-	return fmt.Sprintf("%s not found: %s", e.Resource, e.ID)
+	return fmt.Sprintf("%s not found: %s", e.Resource, e.ID) + requestIDSuffix(e.RequestID)
+}
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// AmbiguousMatchError reports a name that matches several resources, such
+// as a partial product name passed to GetProductByName.
+type AmbiguousMatchError struct {
+	Resource   string
+	Query      string
+	Candidates []string // names of the matching resources
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	quoted := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		quoted[i] = strconv.Quote(c)
+	}
+	return fmt.Sprintf("ambiguous %s name %q: matches %s", e.Resource, e.Query, strings.Join(quoted, ", "))
 }
 
 // RateLimitError represents a rate limit error
 type RateLimitError struct {
-	RetryAfter int // seconds
+	RetryAfter int    // seconds
+	RequestID  string // see RequestID
 }
 
 func (e *RateLimitError) Error() string {
-	return fmt.Sprintf("rate limited, retry after %d seconds", e.RetryAfter)
+	return fmt.Sprintf("rate limited, retry after %d seconds", e.RetryAfter) + requestIDSuffix(e.RequestID)
 }
 
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
 // statusError represents an unexpected HTTP status response. It carries the
 // status code so retry logic can distinguish transient (5xx) from permanent
 // (4xx) failures.
 type statusError struct {
 	StatusCode int
 	Body       string
+	RequestID  string
 }
 
 func (e *statusError) Error() string {
-	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body) + requestIDSuffix(e.RequestID)
+}
+
+func (e *statusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// requestIDSuffix formats the request ID of an error message.
+func requestIDSuffix(id string) string {
+	if id == "" {
+		return ""
+	}
+	return " (request ID " + id + ")"
+}
+
+// RetryExhaustedError reports a call that failed after more than one
+// attempt. It unwraps to the last attempt's error, so errors.Is and
+// errors.As see the typed error that ended the call; Errors lists all
+// attempts' errors to show why they failed.
+type RetryExhaustedError struct {
+	Attempts   int
+	Errors     []error // one per attempt, oldest first
+	LastStatus int     // HTTP status of the last attempt, 0 if no response arrived
+}
+
+func (e *RetryExhaustedError) Error() string {
+	msg := fmt.Sprintf("failed after %d attempts", e.Attempts)
+	if e.LastStatus != 0 {
+		msg += fmt.Sprintf(" (last status %d)", e.LastStatus)
+	}
+	return msg + ": " + e.Last().Error()
+}
+
+// Last returns the last attempt's error.
+func (e *RetryExhaustedError) Last() error {
+	if len(e.Errors) == 0 {
+		return errors.New("no attempts")
+	}
+	return e.Errors[len(e.Errors)-1]
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Last()
+}
+
+// retryExhausted returns the error of a call that failed after the given
+// attempts, or the single error of a call that made only one.
+func retryExhausted(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return &RetryExhaustedError{Attempts: len(errs), Errors: errs, LastStatus: errorStatus(errs[len(errs)-1])}
+}
+
+// errorStatus returns the HTTP status behind an error of the client, or 0.
+func errorStatus(err error) int {
+	var authErr *AuthError
+	var statusErr *statusError
+	switch {
+	case errors.As(err, &authErr):
+		return authErr.StatusCode
+	case errors.As(err, &statusErr):
+		return statusErr.StatusCode
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	}
+	return 0
 }
 
 // nonRetryableError marks a permanent failure so the retry loop stops
@@ -57,3 +196,106 @@ func (e *nonRetryableError) Error() string {
 func (e *nonRetryableError) Unwrap() error {
 	return e.err
 }
+
+// UnsupportedArchiveError is returned by DownloadAndExtract when the payload is
+// not an archive it can stream, or uses a feature that cannot be streamed.
+type UnsupportedArchiveError struct {
+	Reason string
+}
+
+func (e *UnsupportedArchiveError) Error() string {
+	return fmt.Sprintf("unsupported archive: %s", e.Reason)
+}
+
+// InsufficientSpaceError is returned by the pre-flight disk space check when a
+// download would not fit on the destination file system.
+type InsufficientSpaceError struct {
+	Path      string
+	Required  int64 // bytes, estimated from the catalog file sizes
+	Available int64 // bytes available to the process
+}
+
+func (e *InsufficientSpaceError) Error() string {
+	return fmt.Sprintf("insufficient disk space in %s: %d bytes required, %d available", e.Path, e.Required, e.Available)
+}
+
+// QuotaExceededError is returned by a Sync run that would grow its mirror
+// beyond its Quota.
+type QuotaExceededError struct {
+	Path     string
+	MaxBytes int64
+	Used     int64 // bytes in the mirror, after any eviction
+	Required int64 // bytes the run would add, estimated from the catalog
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("mirror quota exceeded in %s: %d bytes used, %d required, %d allowed", e.Path, e.Used, e.Required, e.MaxBytes)
+}
+
+// CertificatePinError reports a server certificate chain that contains none
+// of the pinned keys of its host. See
+// TLSOptions.Pins. It is not retried.
+type CertificatePinError struct {
+	Host string
+}
+
+func (e *CertificatePinError) Error() string {
+	return fmt.Sprintf("certificate of %s does not match any pinned key", e.Host)
+}
+
+// DownloadTimeoutError reports a file download that received no response
+// within Config.DownloadHeaderTimeout, or whose body delivered no data for
+// Config.DownloadIdleTimeout. Downloads retry it like a network error.
+type DownloadTimeoutError struct {
+	Stalled bool // the body stalled, rather than the response not arriving
+	Timeout time.Duration
+}
+
+func (e *DownloadTimeoutError) Error() string {
+	if e.Stalled {
+		return fmt.Sprintf("download stalled: no data received for %s", e.Timeout)
+	}
+	return fmt.Sprintf("download timed out: no response within %s", e.Timeout)
+}
+
+// TruncatedDownloadError reports a download that ended before all expected
+// bytes arrived, e.g. because a proxy cut the connection. Expected comes from
+// the Content-Length or Content-Range of the response, or is estimated from
+// the catalog file size. Downloads retry it like a network error.
+type TruncatedDownloadError struct {
+	Expected int64
+	Received int64
+	Err      error // underlying read error, if any
+}
+
+func (e *TruncatedDownloadError) Error() string {
+	msg := fmt.Sprintf("download truncated: received %d of %d bytes", e.Received, e.Expected)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *TruncatedDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrTruncated.
+func (e *TruncatedDownloadError) Is(target error) bool { return target == ErrTruncated }
+
+// CircuitOpenError is returned without contacting the server while the
+// client's circuit breaker is open after repeated server or authentication
+// failures. Err is the failure that last counted towards opening it.
+type CircuitOpenError struct {
+	Until    time.Time
+	Failures int
+	Err      error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open after %d consecutive failures, retry after %s: %v", e.Failures, e.Until.Format(time.RFC3339), e.Err)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,175 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSentinelErrors checks the unwrap chain from the client's methods,
+// through the retry loop, to the sentinel and typed errors.
+func TestSentinelErrors(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/file/401/download"):
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasSuffix(r.URL.Path, "/file/403/download"):
+			w.WriteHeader(http.StatusForbidden)
+		case strings.HasSuffix(r.URL.Path, "/file/404/download"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasSuffix(r.URL.Path, "/file/429/download"):
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+
+	_, err := client.GetProduct(ctx, 99)
+	var notFound *NotFoundError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &notFound) || notFound.Resource != "product" {
+		t.Errorf("GetProduct of a missing product: %v", err)
+	}
+
+	for _, tt := range []struct {
+		fileID   int
+		sentinel error
+		typed    any
+	}{
+		{401, ErrUnauthorized, new(*AuthError)},
+		{403, ErrUnauthorized, new(*statusError)},
+		{404, ErrNotFound, new(*NotFoundError)},
+		{429, ErrRateLimited, new(*RateLimitError)}, // wrapped by the retry loop
+	} {
+		err := client.DownloadFile(ctx, 1, 2, tt.fileID, &bytes.Buffer{})
+		if !errors.Is(err, tt.sentinel) {
+			t.Errorf("status %d: %v does not match %v", tt.fileID, err, tt.sentinel)
+		}
+		if !errors.As(err, tt.typed) {
+			t.Errorf("status %d: %v is not a %T", tt.fileID, err, tt.typed)
+		}
+		for _, other := range []error{ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrTruncated} {
+			if other != tt.sentinel && errors.Is(err, other) {
+				t.Errorf("status %d: %v also matches %v", tt.fileID, err, other)
+			}
+		}
+	}
+
+	// Bulk downloads keep each file's error.
+	results, err := client.DownloadFiles(ctx, []FileRef{{ProductID: 1, DeliveryID: 2, FileID: 404}}, t.TempDir(), nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("DownloadFiles error %v does not match ErrNotFound", err)
+	}
+	if len(results) != 1 || !errors.Is(results[0].Err, ErrNotFound) {
+		t.Errorf("DownloadFiles result error does not match ErrNotFound: %+v", results)
+	}
+}
+
+// TestRetryCancelKeepsLastError checks that a context cancelled while
+// waiting to retry reports both the cancellation and the failure retried.
+func TestRetryCancelKeepsLastError(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.ListProducts(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v does not match context.DeadlineExceeded", err)
+	}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("%v does not match ErrRateLimited", err)
+	}
+}
+
+func TestRetryExhaustedError(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	var calls atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	_, err := client.GetProduct(context.Background(), 3)
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("expected *RetryExhaustedError, got %T: %v", err, err)
+	}
+	if exhausted.Attempts != 3 || len(exhausted.Errors) != 3 || exhausted.LastStatus != http.StatusNotFound {
+		t.Errorf("Attempts = %d, %d errors, LastStatus = %d; want 3, 3, 404", exhausted.Attempts, len(exhausted.Errors), exhausted.LastStatus)
+	}
+	var first *statusError
+	if !errors.As(exhausted.Errors[0], &first) || first.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("first attempt error = %v, want status 503", exhausted.Errors[0])
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("%v does not match the last attempt's ErrNotFound", err)
+	}
+	if want := "failed after 3 attempts (last status 404): product not found: 3"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Error() = %q, want prefix %q", err.Error(), want)
+	}
+
+	// A call that fails on its first attempt returns that error unwrapped.
+	_, err = client.GetProduct(context.Background(), 3, WithNoRetry())
+	if errors.As(err, &exhausted) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("single attempt returned %T: %v", err, err)
+	}
+}
+
+// TestRetryStopsWhenCancelled checks that a request failing because its
+// context was cancelled is not retried, even without a retry delay.
+func TestRetryStopsWhenCancelled(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	client.config.RetryDelay = 0
+	client.config.MaxRetries = 100
+	var retries atomic.Int32
+	client.config.OnRetry = func(int, error, time.Duration) { retries.Add(1) }
+
+	_, err := client.ListProducts(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("%v does not match context.Canceled", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("%d requests after cancellation, want 1", n)
+	}
+	if n := retries.Load(); n != 0 {
+		t.Errorf("OnRetry called %d times after cancellation", n)
+	}
+	if s := client.Stats(); s.Retries != 0 {
+		t.Errorf("Stats().Retries = %d, want 0", s.Retries)
+	}
+}
@@ -1,6 +1,7 @@
 package bdds_test
 
 import (
+	"context"
 	"fmt"
 
 	bdds "github.com/patent-dev/epo-bdds"
@@ -20,3 +21,31 @@ func ExampleNewClient() {
 	fmt.Println(client != nil)
 	// Output: true
 }
+
+// fakeBDDS serves a fixed catalog. Embedding BDDS satisfies the interface;
+// methods the test does not implement panic if called.
+type fakeBDDS struct {
+	bdds.BDDS
+	products []*bdds.Product
+}
+
+func (f *fakeBDDS) ListProducts(context.Context, ...bdds.CallOption) ([]*bdds.Product, error) {
+	return f.products, nil
+}
+
+// countProducts is code under test that takes the BDDS interface rather
+// than a *bdds.Client.
+func countProducts(ctx context.Context, client bdds.BDDS) (int, error) {
+	products, err := client.ListProducts(ctx)
+	return len(products), err
+}
+
+func ExampleBDDS() {
+	fake := &fakeBDDS{products: []*bdds.Product{
+		{ID: bdds.ProductDocDBFrontFile, Name: "EP DocDB front file"},
+		{ID: bdds.ProductDocDBBackFile, Name: "EP DocDB back file"},
+	}}
+	n, err := countProducts(context.Background(), fake)
+	fmt.Println(n, err)
+	// Output: 2 <nil>
+}
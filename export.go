@@ -0,0 +1,154 @@
+package bdds
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportFormat is a file format of Catalog.Export.
+type ExportFormat string
+
+// Formats of Catalog.Export.
+const (
+	ExportCSV  ExportFormat = "csv"  // a header row, then one row per file
+	ExportJSON ExportFormat = "json" // an array of one object per file
+)
+
+// exportColumns are the columns of an export, in CSV order. They double as
+// the JSON object keys.
+var exportColumns = []string{
+	"product_id", "product_name",
+	"delivery_id", "delivery_name", "delivery_published", "delivery_expires",
+	"file_id", "file_name", "file_size", "file_size_bytes", "file_checksum", "file_published",
+}
+
+// exportRow is one row of an export.
+type exportRow struct {
+	ProductID         int    `json:"product_id"`
+	ProductName       string `json:"product_name"`
+	DeliveryID        int    `json:"delivery_id,omitempty"`
+	DeliveryName      string `json:"delivery_name,omitempty"`
+	DeliveryPublished string `json:"delivery_published,omitempty"`
+	DeliveryExpires   string `json:"delivery_expires,omitempty"`
+	FileID            int    `json:"file_id,omitempty"`
+	FileName          string `json:"file_name,omitempty"`
+	FileSize          string `json:"file_size,omitempty"`
+	FileSizeBytes     int64  `json:"file_size_bytes,omitempty"`
+	FileChecksum      string `json:"file_checksum,omitempty"`
+	FilePublished     string `json:"file_published,omitempty"`
+}
+
+func (r *exportRow) csv() []string {
+	id := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	size := ""
+	if r.FileSizeBytes > 0 {
+		size = strconv.FormatInt(r.FileSizeBytes, 10)
+	}
+	return []string{
+		strconv.Itoa(r.ProductID), r.ProductName,
+		id(r.DeliveryID), r.DeliveryName, r.DeliveryPublished, r.DeliveryExpires,
+		id(r.FileID), r.FileName, r.FileSize, size, r.FileChecksum, r.FilePublished,
+	}
+}
+
+// exportTime formats a catalog date for an export: RFC 3339 in UTC, or
+// empty if unset.
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// rows flattens the catalog into one row per file, in catalog order. A
+// delivery without files, or a product without deliveries, gets a row of
+// its own with the missing columns empty.
+func (c *Catalog) rows() []*exportRow {
+	var rows []*exportRow
+	for _, p := range c.Products {
+		if len(p.Deliveries) == 0 {
+			rows = append(rows, &exportRow{ProductID: p.ID, ProductName: p.Name})
+		}
+		for _, d := range p.Deliveries {
+			delivery := exportRow{
+				ProductID:         p.ID,
+				ProductName:       p.Name,
+				DeliveryID:        d.DeliveryID,
+				DeliveryName:      d.DeliveryName,
+				DeliveryPublished: exportTime(d.DeliveryPublicationDatetime),
+			}
+			if d.DeliveryExpiryDatetime != nil {
+				delivery.DeliveryExpires = exportTime(*d.DeliveryExpiryDatetime)
+			}
+			if len(d.Files) == 0 {
+				rows = append(rows, &delivery)
+			}
+			for _, f := range d.Files {
+				row := delivery
+				row.FileID = f.FileID
+				row.FileName = f.FileName
+				row.FileSize = f.FileSize
+				row.FileSizeBytes = parseFileSize(f.FileSize)
+				row.FileChecksum = f.FileChecksum
+				row.FilePublished = exportTime(f.FilePublicationDatetime)
+				rows = append(rows, &row)
+			}
+		}
+	}
+	return rows
+}
+
+// Export writes the catalog as a flat listing of its files, with their
+// product and delivery, for spreadsheets and data inventories. Sizes are
+// given as in the catalog ("1.2 GB") and in bytes, dates in RFC 3339 (UTC).
+// Unlike Write, the listing cannot be read back with LoadCatalog.
+func (c *Catalog) Export(w io.Writer, format ExportFormat) error {
+	rows := c.rows()
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write(exportColumns)
+		for _, r := range rows {
+			_ = cw.Write(r.csv())
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to export catalog: %w", err)
+		}
+	case ExportJSON:
+		if rows == nil {
+			rows = []*exportRow{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rows); err != nil {
+			return fmt.Errorf("failed to export catalog: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown catalog export format %q", format)
+	}
+	return nil
+}
+
+// ExportCatalog fetches the catalog (see FetchCatalog) and writes it to w
+// as a flat listing in the given format; see Catalog.Export.
+func (c *Client) ExportCatalog(ctx context.Context, w io.Writer, format ExportFormat) error {
+	if format != ExportCSV && format != ExportJSON {
+		return fmt.Errorf("unknown catalog export format %q", format)
+	}
+	catalog, err := c.FetchCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	return catalog.Export(w, format)
+}
@@ -0,0 +1,104 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCatalogExport(t *testing.T) {
+	published := time.Date(2024, 10, 15, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	expiry := published.AddDate(1, 0, 0)
+	catalog := &Catalog{Products: []*ProductWithDeliveries{
+		{ID: 3, Name: "EP DocDB, front file", Deliveries: []*Delivery{
+			{DeliveryID: 7, DeliveryName: "2024-10-15", DeliveryPublicationDatetime: published, DeliveryExpiryDatetime: &expiry, Files: []*DeliveryFile{
+				{FileID: 71, FileName: "a.zip", FileSize: "1 KB", FileChecksum: "AA", FilePublicationDatetime: published},
+				{FileID: 72, FileName: "b.zip", FileSize: "2 KB", FileChecksum: "BB", FilePublicationDatetime: published},
+			}},
+			{DeliveryID: 8, DeliveryName: "NOTIFICATION", DeliveryPublicationDatetime: published},
+		}},
+		{ID: 17, Name: "PATSTAT Global"},
+	}}
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := catalog.Export(&buf, ExportCSV); err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		if len(records) != 5 || !slices.Equal(records[0], exportColumns) {
+			t.Fatalf("got %d records, header %q", len(records), records[0])
+		}
+		want := []string{"3", "EP DocDB, front file", "7", "2024-10-15", "2024-10-15T10:00:00Z", "2025-10-15T10:00:00Z",
+			"71", "a.zip", "1 KB", "1000", "AA", "2024-10-15T10:00:00Z"}
+		if !slices.Equal(records[1], want) {
+			t.Errorf("file row = %q, want %q", records[1], want)
+		}
+		if r := records[3]; r[2] != "8" || r[6] != "" || r[9] != "" {
+			t.Errorf("delivery without files = %q", r)
+		}
+		if r := records[4]; r[0] != "17" || r[2] != "" {
+			t.Errorf("product without deliveries = %q", r)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := catalog.Export(&buf, ExportJSON); err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		var rows []map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(rows) != 4 {
+			t.Fatalf("got %d rows, want 4", len(rows))
+		}
+		if r := rows[1]; r["file_id"] != 72.0 || r["file_size_bytes"] != 2000.0 || r["delivery_expires"] != "2025-10-15T10:00:00Z" {
+			t.Errorf("file row = %v", r)
+		}
+		for key := range rows[0] {
+			if !slices.Contains(exportColumns, key) {
+				t.Errorf("JSON key %q is not an export column", key)
+			}
+		}
+
+		buf.Reset()
+		if err := (&Catalog{}).Export(&buf, ExportJSON); err != nil || buf.String() != "[]\n" {
+			t.Errorf("empty catalog exported as %q, %v", buf.String(), err)
+		}
+	})
+
+	if err := catalog.Export(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestExportCatalog(t *testing.T) {
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	srv, _ := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", published, 2)})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := client.ExportCatalog(context.Background(), &buf, ExportCSV); err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("got %d records, %v; want header and 2 files", len(records), err)
+	}
+	if err := client.ExportCatalog(context.Background(), &buf, "xml"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
@@ -0,0 +1,320 @@
+package bdds
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DownloadAndExtract downloads a ZIP, gzip or tar.gz file and extracts it into
+// destDir while it streams, without storing the archive on disk first. It
+// returns the paths of the extracted files.
+//
+// The format is detected from the content. A plain gzip file is written under
+// its original name (from the gzip header or the download's file name, without
+// the .gz suffix). Entries that would escape destDir are rejected. ZIP entries
+// are verified against their CRC-32.
+//
+// A failed request is retried until extraction has started; an error during
+// extraction is returned as is and may leave the files extracted so far.
+func (c *Client) DownloadAndExtract(ctx context.Context, productID, deliveryID, fileID int, destDir string) ([]string, error) {
+	if err := os.MkdirAll(destDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	var paths []string
+	err := c.retryableRequest(ctx, func() error {
+		resp, err := c.generatedClient.DownloadFile(ctx, productID, deliveryID, fileID)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if err := downloadResponseError(resp, productID, deliveryID, fileID); err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("file-%d", fileID)
+		if info := fileInfoFromHeader(resp.Header, resp.ContentLength); info.FileName != "" {
+			name = info.FileName
+		}
+
+		br := bufio.NewReaderSize(resp.Body, 64<<10)
+		if _, err := br.Peek(1); err != nil && err != io.EOF {
+			return err // nothing consumed yet, safe to retry
+		}
+		paths, err = extractStream(br, destDir, name)
+		if err != nil {
+			return &nonRetryableError{err: err}
+		}
+		return nil
+	})
+	c.stats.recordFile(err)
+	return paths, err
+}
+
+// tarMagicOffset is where the "ustar" magic sits in a tar header block.
+const tarMagicOffset = 257
+
+// extractStream detects the archive format of r and extracts it into destDir.
+func extractStream(br *bufio.Reader, destDir, name string) ([]string, error) {
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		return extractZipStream(br, destDir)
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gz.Close() }()
+		inner := bufio.NewReaderSize(gz, 64<<10)
+		if isTar(inner) {
+			return extractTarStream(inner, destDir)
+		}
+		if gz.Name != "" {
+			name = gz.Name
+		}
+		target, err := safeJoin(destDir, strings.TrimSuffix(filepath.Base(name), ".gz"))
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileFrom(target, inner); err != nil {
+			return nil, err
+		}
+		return []string{target}, nil
+	case isTar(br):
+		return extractTarStream(br, destDir)
+	}
+	return nil, &UnsupportedArchiveError{Reason: "content is not a ZIP, gzip or tar archive"}
+}
+
+func isTar(br *bufio.Reader) bool {
+	block, _ := br.Peek(tarMagicOffset + 5)
+	return len(block) == tarMagicOffset+5 && string(block[tarMagicOffset:]) == "ustar"
+}
+
+// extractTarStream extracts regular files and directories from a tar stream.
+// Links and special files are skipped.
+func extractTarStream(r io.Reader, destDir string) ([]string, error) {
+	tr := tar.NewReader(r)
+	var paths []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return paths, nil
+		}
+		if err != nil {
+			return paths, err
+		}
+		target, err := safeJoin(destDir, hdr.Name)
+		if err != nil {
+			return paths, err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o750); err != nil {
+				return paths, err
+			}
+		case tar.TypeReg:
+			if err := writeFileFrom(target, tr); err != nil {
+				return paths, err
+			}
+			paths = append(paths, target)
+		}
+	}
+}
+
+// ZIP record signatures and flags used by the streaming reader.
+const (
+	zipLocalHeaderSig    = 0x04034b50
+	zipCentralDirSig     = 0x02014b50
+	zipEndOfCentralSig   = 0x06054b50
+	zipDataDescriptorSig = 0x08074b50
+	zipFlagEncrypted     = 0x1
+	zipFlagDescriptor    = 0x8
+	zipZip64ExtraID      = 0x0001
+)
+
+// extractZipStream extracts a ZIP archive by walking its local file headers in
+// stream order, so the central directory at the end is never needed. Entries
+// whose sizes are deferred to a data descriptor must be deflated, since only
+// the deflate stream itself marks where their data ends.
+func extractZipStream(br *bufio.Reader, destDir string) ([]string, error) {
+	var paths []string
+	for {
+		var sig uint32
+		if err := binary.Read(br, binary.LittleEndian, &sig); err != nil {
+			return paths, fmt.Errorf("failed to read zip record: %w", err)
+		}
+		switch sig {
+		case zipLocalHeaderSig:
+		case zipCentralDirSig, zipEndOfCentralSig:
+			// All entries are done; the rest is the directory.
+			_, _ = io.Copy(io.Discard, br)
+			return paths, nil
+		default:
+			return paths, fmt.Errorf("invalid zip record signature %#08x", sig)
+		}
+
+		var hdr struct {
+			Version, Flags, Method, ModTime, ModDate uint16
+			CRC32, CompressedSize, Size              uint32
+			NameLen, ExtraLen                        uint16
+		}
+		if err := binary.Read(br, binary.LittleEndian, &hdr); err != nil {
+			return paths, fmt.Errorf("failed to read zip header: %w", err)
+		}
+		nameExtra := make([]byte, int(hdr.NameLen)+int(hdr.ExtraLen))
+		if _, err := io.ReadFull(br, nameExtra); err != nil {
+			return paths, fmt.Errorf("failed to read zip header: %w", err)
+		}
+		name := string(nameExtra[:hdr.NameLen])
+		compressed, size := int64(hdr.CompressedSize), int64(hdr.Size)
+		zip64 := parseZip64Extra(nameExtra[hdr.NameLen:], &size, &compressed)
+
+		if hdr.Flags&zipFlagEncrypted != 0 {
+			return paths, &UnsupportedArchiveError{Reason: fmt.Sprintf("entry %q is encrypted", name)}
+		}
+		deferred := hdr.Flags&zipFlagDescriptor != 0
+
+		var (
+			data    io.Reader
+			limited *io.LimitedReader // entry data with a known size
+		)
+		if !deferred {
+			limited = &io.LimitedReader{R: br, N: compressed}
+		}
+		switch hdr.Method {
+		case 0: // stored
+			if deferred {
+				return paths, &UnsupportedArchiveError{Reason: fmt.Sprintf("stored entry %q has no size in its local header", name)}
+			}
+			data = limited
+		case 8: // deflated
+			if deferred {
+				// br is an io.ByteReader, so flate reads exactly to the end
+				// of the deflate stream and no further.
+				data = flate.NewReader(br)
+			} else {
+				data = flate.NewReader(limited)
+			}
+		default:
+			return paths, &UnsupportedArchiveError{Reason: fmt.Sprintf("entry %q uses compression method %d", name, hdr.Method)}
+		}
+
+		target, err := safeJoin(destDir, name)
+		if err != nil {
+			return paths, err
+		}
+		crc := crc32.NewIEEE()
+		counted := &countingWriter{w: crc}
+		if strings.HasSuffix(name, "/") {
+			err = os.MkdirAll(target, 0o750)
+		} else {
+			err = writeFileFrom(target, io.TeeReader(data, counted))
+			paths = append(paths, target)
+		}
+		if err != nil {
+			return paths, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		if limited != nil {
+			// Skip anything the decompressor left unread.
+			if _, err := io.Copy(io.Discard, limited); err != nil {
+				return paths, err
+			}
+		}
+
+		wantCRC, wantSize := hdr.CRC32, size
+		if deferred {
+			wantCRC, wantSize, err = readDataDescriptor(br, zip64)
+			if err != nil {
+				return paths, fmt.Errorf("failed to read data descriptor of %s: %w", name, err)
+			}
+		}
+		if counted.n != wantSize || crc.Sum32() != wantCRC {
+			return paths, fmt.Errorf("zip entry %s is corrupt: got %d bytes (crc %08x), want %d bytes (crc %08x)", name, counted.n, crc.Sum32(), wantSize, wantCRC)
+		}
+	}
+}
+
+// parseZip64Extra replaces 0xFFFFFFFF sizes with their 64-bit values from the
+// ZIP64 extra field and reports whether that field was present.
+func parseZip64Extra(extra []byte, size, compressed *int64) bool {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		n := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if n > len(extra) {
+			return false
+		}
+		if id == zipZip64ExtraID {
+			field := extra[:n]
+			if *size == 0xFFFFFFFF && len(field) >= 8 {
+				*size = int64(binary.LittleEndian.Uint64(field))
+				field = field[8:]
+			}
+			if *compressed == 0xFFFFFFFF && len(field) >= 8 {
+				*compressed = int64(binary.LittleEndian.Uint64(field))
+			}
+			return true
+		}
+		extra = extra[n:]
+	}
+	return false
+}
+
+// readDataDescriptor reads the descriptor following deferred-size entry data:
+// an optional signature, the CRC-32, then 32-bit or 64-bit sizes. Writers use
+// 64-bit sizes for ZIP64 entries, which a stream cannot always know in advance,
+// so the width is confirmed by the record signature that must follow.
+func readDataDescriptor(br *bufio.Reader, zip64 bool) (crc uint32, size int64, err error) {
+	peek, err := br.Peek(4)
+	if err != nil {
+		return 0, 0, err
+	}
+	if binary.LittleEndian.Uint32(peek) == zipDataDescriptorSig {
+		_, _ = br.Discard(4)
+	}
+	if err := binary.Read(br, binary.LittleEndian, &crc); err != nil {
+		return 0, 0, err
+	}
+	next, _ := br.Peek(20)
+	followedBySig := func(off int) bool {
+		if len(next) < off+4 {
+			return false
+		}
+		switch binary.LittleEndian.Uint32(next[off:]) {
+		case zipLocalHeaderSig, zipCentralDirSig, zipEndOfCentralSig:
+			return true
+		}
+		return false
+	}
+	if followedBySig(16) && !followedBySig(8) {
+		zip64 = true
+	} else if followedBySig(8) {
+		zip64 = false
+	}
+	if zip64 {
+		var sizes [2]uint64
+		if err := binary.Read(br, binary.LittleEndian, &sizes); err != nil {
+			return 0, 0, err
+		}
+		return crc, int64(sizes[1]), nil
+	}
+	var sizes [2]uint32
+	if err := binary.Read(br, binary.LittleEndian, &sizes); err != nil {
+		return 0, 0, err
+	}
+	return crc, int64(sizes[1]), nil
+}
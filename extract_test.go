@@ -0,0 +1,149 @@
+package bdds
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// storedZipBytes builds a ZIP whose entries are stored uncompressed with their
+// sizes in the local header (no data descriptor).
+func storedZipBytes(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE([]byte(content)),
+		CompressedSize64:   uint64(len(content)),
+		UncompressedSize64: uint64(len(content)),
+	})
+	if err != nil {
+		t.Fatalf("zip create: %v", err)
+	}
+	_, _ = w.Write([]byte(content))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("tar header: %v", err)
+		}
+		_, _ = tw.Write([]byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestDownloadAndExtract verifies each supported format is extracted while
+// streaming.
+func TestDownloadAndExtract(t *testing.T) {
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	gz.Name = "docdb.xml.gz"
+	_, _ = gz.Write([]byte("<docdb/>"))
+	_ = gz.Close()
+
+	tests := []struct {
+		name    string
+		payload []byte
+		want    map[string]string
+	}{
+		{"zip deflated", zipBytes(t, map[string]string{"a.xml": strings.Repeat("a", 5000), "sub/b.xml": "b"}),
+			map[string]string{"a.xml": strings.Repeat("a", 5000), "sub/b.xml": "b"}},
+		{"zip stored", storedZipBytes(t, "c.xml", "stored content"), map[string]string{"c.xml": "stored content"}},
+		{"tar.gz", tarGzBytes(t, map[string]string{"d/e.xml": "tarred"}), map[string]string{"d/e.xml": "tarred"}},
+		{"gzip", gzBuf.Bytes(), map[string]string{"docdb.xml": "<docdb/>"}},
+	}
+
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	contents := make(map[int][]byte)
+	for i, tt := range tests {
+		contents[i+1] = tt.payload
+	}
+	apiServer := newFileServer(t, contents)
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths, err := client.DownloadAndExtract(context.Background(), 1, 1, i+1, dir)
+			if err != nil {
+				t.Fatalf("DownloadAndExtract: %v", err)
+			}
+			if len(paths) != len(tt.want) {
+				t.Errorf("extracted %v, want %d files", paths, len(tt.want))
+			}
+			for name, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil || string(got) != want {
+					t.Errorf("%s = %q (%v), want %q", name, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+// TestDownloadAndExtractUnsupported verifies non-archive content is a typed
+// error.
+func TestDownloadAndExtractUnsupported(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{1: []byte("plain text, not an archive")})
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	_, err := client.DownloadAndExtract(context.Background(), 1, 1, 1, t.TempDir())
+	var unsupported *UnsupportedArchiveError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected *UnsupportedArchiveError, got %v", err)
+	}
+}
+
+// TestExtractZipStreamRejectsTraversal verifies entries escaping the
+// destination are refused.
+func TestExtractZipStreamRejectsTraversal(t *testing.T) {
+	payload := zipBytes(t, map[string]string{"../evil.txt": "x"})
+	dir := t.TempDir()
+	if _, err := extractStream(bufio.NewReader(bytes.NewReader(payload)), dir, "x.zip"); err == nil {
+		t.Fatal("expected traversal entry to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil.txt")); err == nil {
+		t.Error("traversal entry was written outside the destination")
+	}
+}
+
+// TestExtractZipStreamDetectsCorruption verifies a CRC mismatch is reported.
+func TestExtractZipStreamDetectsCorruption(t *testing.T) {
+	payload := storedZipBytes(t, "c.xml", "stored content")
+	i := bytes.Index(payload, []byte("stored content"))
+	payload[i] = 'S'
+	if _, err := extractStream(bufio.NewReader(bytes.NewReader(payload)), t.TempDir(), "x.zip"); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("expected corruption error, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package bdds
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/patent-dev/epo-bdds/generated"
+)
+
+// FileInfo describes a downloadable file as reported by the download server,
+// which is more precise than the catalog's human-readable size.
+type FileInfo struct {
+	Size         int64     `json:"size"`                  // exact size in bytes, -1 if the server does not say
+	ContentType  string    `json:"contentType"`           // e.g. "application/zip"
+	LastModified time.Time `json:"lastModified,omitzero"` // zero if not reported
+	ETag         string    `json:"etag,omitempty"`
+	FileName     string    `json:"fileName,omitempty"` // from Content-Disposition, if present
+	AcceptRanges bool      `json:"acceptRanges"`       // server supports byte-range requests
+}
+
+// withMethod returns a request editor that replaces the request method.
+func withMethod(method string) generated.RequestEditorFn {
+	return func(_ context.Context, req *http.Request) error {
+		req.Method = method
+		return nil
+	}
+}
+
+// GetFileInfo returns the size, content type and modification time of a file
+// without downloading it. It sends a HEAD request and, if the server rejects
+// HEAD or omits the size, falls back to a GET for the first byte and reads the
+// size from its Content-Range.
+func (c *Client) GetFileInfo(ctx context.Context, productID, deliveryID, fileID int, opts ...CallOption) (*FileInfo, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+	var info *FileInfo
+	err := c.retryableRequest(ctx, func() error {
+		head, err := c.generatedClient.DownloadFile(ctx, productID, deliveryID, fileID, withMethod(http.MethodHead))
+		if err != nil {
+			return err
+		}
+		_ = head.Body.Close()
+
+		if head.StatusCode != http.StatusMethodNotAllowed && head.StatusCode != http.StatusNotImplemented {
+			if err := downloadResponseError(head, productID, deliveryID, fileID); err != nil {
+				return err
+			}
+			info = fileInfoFromHeader(head.Header, head.ContentLength)
+			if info.Size >= 0 {
+				return nil
+			}
+		}
+
+		resp, err := c.generatedClient.DownloadFile(ctx, productID, deliveryID, fileID, withRange(0, 0))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		switch resp.StatusCode {
+		case http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+			// 416 is what an empty file answers to bytes=0-0.
+			info = fileInfoFromHeader(resp.Header, -1)
+			info.AcceptRanges = true
+			if cr, ok := parseContentRange(resp.Header.Get("Content-Range")); ok {
+				info.Size = cr.Total
+			}
+			return nil
+		}
+		if err := downloadResponseError(resp, productID, deliveryID, fileID); err != nil {
+			return err
+		}
+		// A 200 ignores the range; its length is the full size.
+		info = fileInfoFromHeader(resp.Header, resp.ContentLength)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func fileInfoFromHeader(h http.Header, size int64) *FileInfo {
+	info := &FileInfo{
+		Size:         size,
+		ContentType:  h.Get("Content-Type"),
+		ETag:         h.Get("ETag"),
+		AcceptRanges: strings.EqualFold(h.Get("Accept-Ranges"), "bytes"),
+	}
+	if t, err := http.ParseTime(h.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	if _, params, err := mime.ParseMediaType(h.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		info.FileName = filepath.Base(params["filename"])
+	}
+	return info
+}
@@ -0,0 +1,66 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetFileInfo(t *testing.T) {
+	content := strings.Repeat("x", 12345)
+	modified := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		allowHead  bool
+		wantMethod string // method of the request that supplied the size
+	}{
+		{"HEAD", true, http.MethodHead},
+		{"ranged GET fallback", false, http.MethodGet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authServer, _ := newAuthServer(3600)
+			defer authServer.Close()
+			var methods []string
+			apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+				if r.Method == http.MethodHead && !tt.allowHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				if r.URL.Path != "/bdds/bdds-bff-service/prod/api/products/1/delivery/2/file/3/download" {
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/zip")
+				w.Header().Set("Content-Disposition", `attachment; filename="docdb_backfile.zip"`)
+				http.ServeContent(w, r, "", modified, strings.NewReader(content))
+			}))
+			defer apiServer.Close()
+
+			client := newTestClient(t, apiServer.URL, authServer.URL)
+			info, err := client.GetFileInfo(context.Background(), 1, 2, 3)
+			if err != nil {
+				t.Fatalf("GetFileInfo: %v", err)
+			}
+			if info.Size != int64(len(content)) || info.ContentType != "application/zip" ||
+				!info.LastModified.Equal(modified) || info.FileName != "docdb_backfile.zip" || !info.AcceptRanges {
+				t.Errorf("unexpected info: %+v", info)
+			}
+			if last := methods[len(methods)-1]; last != tt.wantMethod {
+				t.Errorf("size came from %s, want %s (requests: %v)", last, tt.wantMethod, methods)
+			}
+
+			_, err = client.GetFileInfo(context.Background(), 1, 2, 99)
+			var notFound *NotFoundError
+			if !errors.As(err, &notFound) {
+				t.Errorf("expected NotFoundError for unknown file, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package bdds
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+)
+
+// FileFilter declaratively selects the files of bulk downloads and Sync runs
+// (see DownloadOptions.Filter). Unset fields match everything; a file must
+// match all set fields.
+type FileFilter struct {
+	// PublishedFrom and PublishedBefore bound the delivery publication
+	// date: at or after PublishedFrom, before PublishedBefore.
+	PublishedFrom   time.Time
+	PublishedBefore time.Time
+	// DeliveryName is a glob (as in path.Match) for the delivery name.
+	DeliveryName string
+	// FileName is a glob for the file name, e.g. "*_EN_*.zip".
+	FileName string
+	// FilePattern is a regular expression the file name must match.
+	FilePattern *regexp.Regexp
+	// MaxSize excludes files whose catalog size is larger (0: no limit).
+	MaxSize int64
+}
+
+// Match reports whether a file of a delivery passes the filter. Invalid
+// glob patterns match nothing.
+func (f *FileFilter) Match(d *Delivery, file *DeliveryFile) bool {
+	if f == nil {
+		return true
+	}
+	published := d.DeliveryPublicationDatetime
+	if !f.PublishedFrom.IsZero() && published.Before(f.PublishedFrom) {
+		return false
+	}
+	if !f.PublishedBefore.IsZero() && !published.Before(f.PublishedBefore) {
+		return false
+	}
+	if f.DeliveryName != "" {
+		if ok, _ := path.Match(f.DeliveryName, d.DeliveryName); !ok {
+			return false
+		}
+	}
+	if f.FileName != "" {
+		if ok, _ := path.Match(f.FileName, file.FileName); !ok {
+			return false
+		}
+	}
+	if f.FilePattern != nil && !f.FilePattern.MatchString(file.FileName) {
+		return false
+	}
+	if f.MaxSize > 0 && parseFileSize(file.FileSize) > f.MaxSize {
+		return false
+	}
+	return true
+}
+
+// FileMatch is a file found by FindFiles. Its FileRef can be passed to
+// DownloadFiles, and its IDs to the single-file download methods.
+type FileMatch struct {
+	FileRef
+	Delivery *Delivery     `json:"delivery"`
+	File     *DeliveryFile `json:"file"`
+}
+
+// FindFiles returns the files of a product's deliveries that pass filter,
+// e.g. &FileFilter{FileName: "*CPC*"} or a FilePattern regular expression,
+// newest delivery first and in catalog order within a delivery. A nil
+// filter matches every file. It fetches the product's catalog.
+func (c *Client) FindFiles(ctx context.Context, productID int, filter *FileFilter, opts ...CallOption) ([]*FileMatch, error) {
+	if filter != nil {
+		if err := filter.validate(); err != nil {
+			return nil, err
+		}
+	}
+	deliveries, err := c.ListDeliveries(ctx, productID, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*FileMatch
+	for _, d := range deliveries {
+		for _, f := range d.Files {
+			if filter.Match(d, f) {
+				matches = append(matches, &FileMatch{
+					FileRef:  FileRef{ProductID: productID, DeliveryID: d.DeliveryID, FileID: f.FileID},
+					Delivery: d,
+					File:     f,
+				})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// validate reports invalid glob patterns.
+func (f *FileFilter) validate() error {
+	for _, pattern := range []string{f.DeliveryName, f.FileName} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
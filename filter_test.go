@@ -0,0 +1,125 @@
+package bdds
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFileFilterMatch(t *testing.T) {
+	d := &Delivery{DeliveryName: "EP full-text 2023-W05", DeliveryPublicationDatetime: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)}
+	en := &DeliveryFile{FileName: "EPRTBJV2023000005_EN_001.zip", FileSize: "1.5 GB"}
+	de := &DeliveryFile{FileName: "EPRTBJV2023000005_DE_001.zip", FileSize: "200 MB"}
+
+	tests := []struct {
+		name   string
+		filter *FileFilter
+		file   *DeliveryFile
+		want   bool
+	}{
+		{"nil filter", nil, en, true},
+		{"empty filter", &FileFilter{}, en, true},
+		{"published from", &FileFilter{PublishedFrom: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}, en, true},
+		{"published too early", &FileFilter{PublishedFrom: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}, en, false},
+		{"published before", &FileFilter{PublishedBefore: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)}, en, false},
+		{"delivery glob", &FileFilter{DeliveryName: "EP full-text 2023-*"}, en, true},
+		{"delivery glob mismatch", &FileFilter{DeliveryName: "DOCDB*"}, en, false},
+		{"file glob", &FileFilter{FileName: "*_EN_*.zip"}, en, true},
+		{"file glob mismatch", &FileFilter{FileName: "*_EN_*.zip"}, de, false},
+		{"file regexp", &FileFilter{FilePattern: regexp.MustCompile(`_(EN|FR)_\d+\.zip$`)}, de, false},
+		{"max size", &FileFilter{MaxSize: 500 << 20}, de, true},
+		{"too large", &FileFilter{MaxSize: 500 << 20}, en, false},
+		{"invalid glob", &FileFilter{FileName: "["}, en, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(d, tt.file); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDownloadProductFilter verifies a filter limits which files are
+// downloaded and reported, and an invalid pattern fails up front.
+func TestDownloadProductFilter(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", published, 3),
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 3),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	results, err := client.DownloadProduct(context.Background(), 3, t.TempDir(), &DownloadOptions{
+		Filter: &FileFilter{PublishedFrom: published.AddDate(0, 0, 1), FileName: "*_0[12].zip"},
+	})
+	if err != nil {
+		t.Fatalf("DownloadProduct: %v", err)
+	}
+	if len(results) != 2 || results[0].File.FileID != 801 || results[1].File.FileID != 802 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+
+	_, err = client.DownloadProduct(context.Background(), 3, t.TempDir(), &DownloadOptions{
+		Filter: &FileFilter{DeliveryName: "[2024"},
+	})
+	if err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
+
+func TestFindFiles(t *testing.T) {
+	now := time.Now()
+	srv, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		{id: 1, name: "2024-10-08", published: now.Add(-7 * 24 * time.Hour), files: []mirrorFile{
+			{id: 11, name: "DOC_CPC_01.zip", content: "a"},
+			{id: 12, name: "DOC_IPC_01.zip", content: "b"},
+		}},
+		{id: 2, name: "2024-10-15", published: now, files: []mirrorFile{
+			{id: 21, name: "DOC_CPC_02.zip", content: "c"},
+			{id: 22, name: "DOC_CPC_02.xml", content: "d"},
+		}},
+	})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		filter *FileFilter
+		want   []int
+	}{
+		{&FileFilter{FileName: "*CPC*"}, []int{21, 22, 11}},
+		{&FileFilter{FileName: "DOC_*_01.zip"}, []int{11, 12}},
+		{&FileFilter{FilePattern: regexp.MustCompile(`^DOC_CPC_\d+\.zip$`)}, []int{21, 11}},
+		{nil, []int{21, 22, 11, 12}},
+	}
+	for _, tt := range tests {
+		matches, err := client.FindFiles(ctx, 3, tt.filter)
+		if err != nil {
+			t.Fatalf("FindFiles(%+v): %v", tt.filter, err)
+		}
+		var got []int
+		for _, m := range matches {
+			if m.ProductID != 3 || m.DeliveryID != m.Delivery.DeliveryID || m.FileID != m.File.FileID {
+				t.Errorf("inconsistent match %+v", m)
+			}
+			got = append(got, m.FileID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("FindFiles(%+v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	if _, err := client.FindFiles(ctx, 3, &FileFilter{FileName: "["}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
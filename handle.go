@@ -0,0 +1,238 @@
+package bdds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// DownloadState is the lifecycle state of a Download.
+type DownloadState int
+
+const (
+	DownloadRunning DownloadState = iota
+	DownloadPaused
+	DownloadCompleted
+	DownloadFailed
+	DownloadCancelled
+)
+
+func (s DownloadState) String() string {
+	switch s {
+	case DownloadRunning:
+		return "running"
+	case DownloadPaused:
+		return "paused"
+	case DownloadCompleted:
+		return "completed"
+	case DownloadFailed:
+		return "failed"
+	case DownloadCancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("DownloadState(%d)", int(s))
+}
+
+// Download is a handle to a file download running in the background, started
+// with StartDownload. The content accumulates in "<path>.part" and is renamed
+// to path on completion. Pausing closes the connection but keeps the partial
+// file, and resuming continues from its last byte with a Range request.
+type Download struct {
+	client                        *Client
+	productID, deliveryID, fileID int
+	path                          string
+	written                       atomic.Int64
+
+	mu    sync.Mutex
+	state DownloadState
+	stop  context.CancelFunc // cancels the running attempt, nil between attempts
+	wake  chan struct{}      // signalled by Resume and Cancel
+	done  chan struct{}
+	err   error
+}
+
+// StartDownload starts downloading a file to path in the background and
+// returns its handle. Cancelling ctx stops the download like a failure: the
+// partial file is kept, so a later StartDownload for the same path resumes it.
+func (c *Client) StartDownload(ctx context.Context, productID, deliveryID, fileID int, path string) *Download {
+	d := &Download{
+		client:     c,
+		productID:  productID,
+		deliveryID: deliveryID,
+		fileID:     fileID,
+		path:       path,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	go d.run(ctx)
+	return d
+}
+
+// Pause stops the transfer, keeping the bytes downloaded so far. It has no
+// effect unless the download is running.
+func (d *Download) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state != DownloadRunning {
+		return
+	}
+	d.state = DownloadPaused
+	if d.stop != nil {
+		d.stop()
+	}
+}
+
+// Resume continues a paused download from its last byte.
+func (d *Download) Resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state != DownloadPaused {
+		return
+	}
+	d.state = DownloadRunning
+	d.signal()
+}
+
+// Cancel stops a running or paused download and removes the partial file.
+// Wait then returns context.Canceled.
+func (d *Download) Cancel() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state != DownloadRunning && d.state != DownloadPaused {
+		return
+	}
+	d.state = DownloadCancelled
+	if d.stop != nil {
+		d.stop()
+	}
+	d.signal()
+}
+
+// signal wakes the run loop. The caller must hold mu.
+func (d *Download) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Wait blocks until the download has completed, failed or been cancelled and
+// returns its error.
+func (d *Download) Wait() error {
+	<-d.done
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+// Done returns a channel that is closed when the download has finished.
+func (d *Download) Done() <-chan struct{} {
+	return d.done
+}
+
+// State returns the current state.
+func (d *Download) State() DownloadState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state
+}
+
+// BytesWritten returns the number of bytes of the file downloaded so far,
+// including bytes from before a pause.
+func (d *Download) BytesWritten() int64 {
+	return d.written.Load()
+}
+
+// Path returns the final destination path.
+func (d *Download) Path() string {
+	return d.path
+}
+
+func (d *Download) partPath() string {
+	return d.path + partialSuffix
+}
+
+func (d *Download) run(ctx context.Context) {
+	defer close(d.done)
+	for {
+		// Block while paused.
+		d.mu.Lock()
+		for d.state == DownloadPaused && ctx.Err() == nil {
+			d.mu.Unlock()
+			select {
+			case <-d.wake:
+			case <-ctx.Done():
+			}
+			d.mu.Lock()
+		}
+		if d.state == DownloadCancelled {
+			d.mu.Unlock()
+			d.finish(DownloadCancelled, context.Canceled)
+			return
+		}
+		if ctx.Err() != nil {
+			d.mu.Unlock()
+			d.finish(DownloadFailed, ctx.Err())
+			return
+		}
+		attemptCtx, stop := context.WithCancel(ctx)
+		d.stop = stop
+		d.mu.Unlock()
+
+		err := d.attempt(attemptCtx)
+		stop()
+
+		d.mu.Lock()
+		d.stop = nil
+		state := d.state
+		d.mu.Unlock()
+
+		switch {
+		case err == nil:
+			d.finish(DownloadCompleted, nil)
+			return
+		case state == DownloadPaused:
+			continue
+		case state == DownloadCancelled:
+			d.finish(DownloadCancelled, context.Canceled)
+			return
+		default:
+			d.finish(DownloadFailed, err)
+			return
+		}
+	}
+}
+
+// attempt downloads the remainder of the file into the partial file and moves
+// it into place once complete.
+func (d *Download) attempt(ctx context.Context) error {
+	if info, err := os.Stat(d.partPath()); err == nil {
+		d.written.Store(info.Size())
+	}
+	_, err := d.client.downloadToPath(ctx, d.productID, d.deliveryID, d.fileID, d.path, "", func(bytesWritten, _ int64) {
+		d.written.Store(bytesWritten)
+	})
+	if err != nil {
+		// Progress is reported as bytes are read; the file is authoritative.
+		var size int64
+		if info, serr := os.Stat(d.partPath()); serr == nil {
+			size = info.Size()
+		}
+		d.written.Store(size)
+	}
+	return err
+}
+
+// finish records the final state. A cancelled download removes its partial
+// file.
+func (d *Download) finish(state DownloadState, err error) {
+	if state == DownloadCancelled {
+		_ = removePartial(d.path)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.state = state
+	d.err = err
+}
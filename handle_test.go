@@ -0,0 +1,147 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newSlowRangeServer serves content in small, delayed chunks, honouring
+// "bytes=N-" ranges, and records the start offset of every request.
+func newSlowRangeServer(t *testing.T, content []byte) (*httptest.Server, func() []int64) {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		starts []int64
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		var start int64
+		if rng := r.Header.Get("Range"); rng != "" {
+			start, _ = strconv.ParseInt(rng[len("bytes="):len(rng)-1], 10, 64)
+			w.Header().Set("Content-Range", "bytes "+strconv.FormatInt(start, 10)+"-"+strconv.Itoa(len(content)-1)+"/"+strconv.Itoa(len(content)))
+			w.Header().Set("Content-Length", strconv.FormatInt(int64(len(content))-start, 10))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		}
+		mu.Lock()
+		starts = append(starts, start)
+		mu.Unlock()
+
+		flusher := w.(http.Flusher)
+		for off := start; off < int64(len(content)); off += 64 {
+			end := min(off+64, int64(len(content)))
+			if _, err := w.Write(content[off:end]); err != nil {
+				return
+			}
+			flusher.Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}))
+	return srv, func() []int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int64(nil), starts...)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+// TestDownloadPauseResume verifies a paused download keeps its partial file
+// and resumes from the last byte with a Range request.
+func TestDownloadPauseResume(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64) // 1 KiB
+	apiServer, starts := newSlowRangeServer(t, content)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	path := filepath.Join(t.TempDir(), "file.zip")
+
+	d := client.StartDownload(context.Background(), 1, 2, 3, path)
+	waitFor(t, func() bool { return d.BytesWritten() >= 128 })
+	d.Pause()
+	waitFor(t, func() bool { return len(starts()) == 1 })
+	time.Sleep(20 * time.Millisecond)
+
+	if d.State() != DownloadPaused {
+		t.Fatalf("state = %s, want paused", d.State())
+	}
+	paused := d.BytesWritten()
+	info, err := os.Stat(path + ".part")
+	if err != nil {
+		t.Fatalf("expected partial file while paused: %v", err)
+	}
+	if info.Size() != paused {
+		t.Errorf("partial file has %d bytes, BytesWritten reports %d", info.Size(), paused)
+	}
+
+	d.Resume()
+	if err := d.Wait(); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if d.State() != DownloadCompleted {
+		t.Errorf("state = %s, want completed", d.State())
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Errorf("content mismatch after resume (%d bytes, want %d)", len(got), len(content))
+	}
+	if s := starts(); len(s) != 2 || s[1] != paused {
+		t.Errorf("request offsets = %v, want [0 %d]", s, paused)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Error("partial file left behind after completion")
+	}
+}
+
+// TestDownloadCancel verifies cancelling removes the partial file.
+func TestDownloadCancel(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer, _ := newSlowRangeServer(t, bytes.Repeat([]byte("x"), 4096))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	path := filepath.Join(t.TempDir(), "file.zip")
+
+	d := client.StartDownload(context.Background(), 1, 2, 3, path)
+	waitFor(t, func() bool { return d.BytesWritten() > 0 })
+	d.Pause()
+	d.Cancel()
+	if err := d.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	if d.State() != DownloadCancelled {
+		t.Errorf("state = %s, want cancelled", d.State())
+	}
+	for _, p := range []string{path, path + ".part"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after cancel", filepath.Base(p))
+		}
+	}
+}
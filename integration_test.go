@@ -3,9 +3,13 @@
 package bdds_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,9 +19,9 @@ import (
 	bdds "github.com/patent-dev/epo-bdds"
 )
 
-// This file holds one live integration test per EPO BDDS convenience method (all
-// 6), each named TestIntegration<MethodName> so it maps 1:1 to the Client methods
-// in client.go (verified by scripts/check-integration-coverage.sh).
+// This file holds one live integration test per EPO BDDS convenience method,
+// each named TestIntegration<MethodName> so it maps 1:1 to the Client methods
+// of the root package (verified by scripts/check-integration-coverage.sh).
 // Run with: go test -tags=integration -count=1 ./...
 //
 // Every test PASSES or SKIPS, never FAILS on a documented condition:
@@ -212,6 +216,54 @@ func firstAccessibleProduct(ctx context.Context, t *testing.T, client *bdds.Clie
 	return 0
 }
 
+// --- Client lifecycle -----------------------------------------------------
+
+func TestIntegrationClose(t *testing.T) {
+	testClient(t) // skips without credentials
+	client, err := bdds.NewClient(&bdds.Config{
+		Username:               os.Getenv("EPO_BDDS_USERNAME"),
+		Password:               os.Getenv("EPO_BDDS_PASSWORD"),
+		BackgroundTokenRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	ctx := testContext(t, time.Minute)
+
+	_, err = client.ListProducts(ctx)
+	skipExpected(t, err)
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestIntegrationClone(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, time.Minute)
+
+	clone, err := client.Clone(&bdds.ClientOverrides{UserAgent: "epo-bdds-integration-clone", Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	products, err := clone.ListProducts(ctx)
+	skipExpected(t, err)
+	if len(products) == 0 {
+		t.Error("clone listed no products")
+	}
+}
+
+func TestIntegrationPing(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	result, err := client.Ping(ctx)
+	skipExpected(t, err)
+	if !result.Reachable || !result.Authenticated {
+		t.Fatalf("Ping = %+v", result)
+	}
+	t.Logf("latency: %v", result.Latency)
+}
+
 // --- Metadata endpoints ---------------------------------------------------
 
 func TestIntegrationListProducts(t *testing.T) {
@@ -263,6 +315,62 @@ func TestIntegrationGetProductByName(t *testing.T) {
 	}
 }
 
+// TestIntegrationProductConstants keeps the well-known product IDs in step
+// with the live catalog.
+func TestIntegrationProductConstants(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	products, err := client.ListProducts(ctx)
+	skipExpected(t, err)
+	names := make(map[int]string, len(products))
+	for _, p := range products {
+		names[p.ID] = p.Name
+	}
+	for id, want := range map[int]string{
+		bdds.ProductDocDBFrontFile:      "docdb front file",
+		bdds.ProductEPFullTextFrontFile: "full-text",
+		bdds.ProductDocDBBackFile:       "docdb back file",
+		bdds.ProductPATSTATGlobal:       "patstat global",
+		bdds.ProductPATSTATEPRegister:   "patstat ep register",
+	} {
+		name, ok := names[id]
+		if !ok {
+			t.Logf("product %d (%s) not in this account's catalog", id, want)
+			continue
+		}
+		if !strings.Contains(strings.ToLower(name), want) {
+			t.Errorf("product %d is %q, want a name containing %q", id, name, want)
+		}
+	}
+}
+
+func TestIntegrationSearchProducts(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	products, err := client.ListProducts(ctx)
+	skipExpected(t, err)
+	if len(products) == 0 {
+		t.Skip("no products accessible to this account")
+	}
+	want := products[0]
+	matches, err := client.SearchProducts(ctx, want.Name)
+	skipExpected(t, err)
+	if len(matches) == 0 {
+		t.Fatalf("SearchProducts(%q) found nothing", want.Name)
+	}
+	for i, m := range matches {
+		if i > 0 && m.Score > matches[i-1].Score {
+			t.Fatalf("SearchProducts results not ranked: %d after %d", m.Score, matches[i-1].Score)
+		}
+		if m.ID == want.ID {
+			return
+		}
+	}
+	t.Fatalf("SearchProducts(%q) misses product %d", want.Name, want.ID)
+}
+
 func TestIntegrationGetLatestDelivery(t *testing.T) {
 	client := testClient(t)
 	ctx := testContext(t, 30*time.Second)
@@ -278,6 +386,257 @@ func TestIntegrationGetLatestDelivery(t *testing.T) {
 	}
 }
 
+func TestIntegrationGetDelivery(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	latest, err := client.GetLatestDelivery(ctx, id)
+	skipExpected(t, err)
+	delivery, err := client.GetDelivery(ctx, id, latest.DeliveryID)
+	skipExpected(t, err)
+	if delivery.DeliveryName != latest.DeliveryName {
+		t.Fatalf("GetDelivery returned %q, want %q", delivery.DeliveryName, latest.DeliveryName)
+	}
+}
+
+func TestIntegrationGetDeliveryByName(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	latest, err := client.GetLatestDelivery(ctx, id)
+	skipExpected(t, err)
+	delivery, err := client.GetDeliveryByName(ctx, id, latest.DeliveryName)
+	skipExpected(t, err)
+	if !strings.EqualFold(delivery.DeliveryName, latest.DeliveryName) {
+		t.Fatalf("GetDeliveryByName returned %q, want %q", delivery.DeliveryName, latest.DeliveryName)
+	}
+}
+
+func TestIntegrationFindFiles(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	matches, err := client.FindFiles(ctx, id, &bdds.FileFilter{FileName: "*"})
+	skipExpected(t, err)
+	for _, m := range matches {
+		if m.ProductID != id || m.File == nil || m.Delivery == nil {
+			t.Fatalf("inconsistent match %+v", m)
+		}
+	}
+	t.Logf("%d files", len(matches))
+}
+
+func TestIntegrationDeliveries(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	for d, err := range client.Deliveries(ctx, id) {
+		skipExpected(t, err)
+		if d.DeliveryName == "" {
+			t.Fatalf("delivery has empty DeliveryName: %+v", d)
+		}
+		break
+	}
+}
+
+func TestIntegrationListDeliveries(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	deliveries, err := client.ListDeliveries(ctx, id, &bdds.DeliveryFilter{Limit: 2})
+	skipExpected(t, err)
+	if len(deliveries) == 0 || len(deliveries) > 2 {
+		t.Fatalf("ListDeliveries returned %d deliveries, want 1 or 2", len(deliveries))
+	}
+	if len(deliveries) == 2 && deliveries[1].DeliveryPublicationDatetime.After(deliveries[0].DeliveryPublicationDatetime) {
+		t.Error("deliveries not newest first")
+	}
+}
+
+func TestIntegrationGetDeliveriesSince(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	latest, err := client.ListDeliveries(ctx, id, &bdds.DeliveryFilter{Limit: 2})
+	skipExpected(t, err)
+	if len(latest) < 2 {
+		t.Skip("product has fewer than two deliveries")
+	}
+	since := latest[1].DeliveryPublicationDatetime
+	deliveries, err := client.GetDeliveriesSince(ctx, id, since)
+	skipExpected(t, err)
+	for i, d := range deliveries {
+		if !d.DeliveryPublicationDatetime.After(since) {
+			t.Errorf("delivery %d published %v, not after %v", d.DeliveryID, d.DeliveryPublicationDatetime, since)
+		}
+		if i > 0 && d.DeliveryPublicationDatetime.Before(deliveries[i-1].DeliveryPublicationDatetime) {
+			t.Error("deliveries not oldest first")
+		}
+	}
+	if !slices.ContainsFunc(deliveries, func(d *bdds.Delivery) bool { return d.DeliveryID == latest[0].DeliveryID }) {
+		t.Errorf("latest delivery %d missing", latest[0].DeliveryID)
+	}
+}
+
+func TestIntegrationGetExpiringDeliveries(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 30*time.Second)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	within := 90 * 24 * time.Hour
+	deliveries, err := client.GetExpiringDeliveries(ctx, id, within)
+	skipExpected(t, err)
+	deadline := time.Now().Add(within)
+	for i, d := range deliveries {
+		expiry := d.DeliveryExpiryDatetime
+		if expiry == nil || expiry.After(deadline) {
+			t.Errorf("delivery %d expires %v, want within %v", d.DeliveryID, expiry, within)
+			continue
+		}
+		if i > 0 && expiry.Before(*deliveries[i-1].DeliveryExpiryDatetime) {
+			t.Error("deliveries not ordered by expiry")
+		}
+	}
+	t.Logf("%d deliveries of product %d expire within %v", len(deliveries), id, within)
+}
+
+func TestIntegrationGetProductOverview(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, time.Minute)
+
+	id := firstAccessibleProduct(ctx, t, client)
+	overview, err := client.GetProductOverview(ctx, id)
+	skipExpected(t, err)
+	product, err := client.GetProduct(ctx, id)
+	skipExpected(t, err)
+	if overview.ID != product.ID || len(overview.Deliveries) != len(product.Deliveries) {
+		t.Fatalf("overview has %d deliveries, product %d", len(overview.Deliveries), len(product.Deliveries))
+	}
+	files := 0
+	for i, d := range product.Deliveries {
+		files += len(d.Files)
+		if got := overview.Deliveries[i]; got.DeliveryID != d.DeliveryID || got.FileCount != len(d.Files) {
+			t.Errorf("delivery %d: overview %d with %d files, want %d files", d.DeliveryID, got.DeliveryID, got.FileCount, len(d.Files))
+		}
+	}
+	if overview.FileCount != files {
+		t.Errorf("FileCount = %d, want %d", overview.FileCount, files)
+	}
+}
+
+func TestIntegrationListAccessibleProducts(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	products, err := client.ListAccessibleProducts(ctx)
+	skipExpected(t, err)
+	if len(products) == 0 {
+		t.Fatal("ListAccessibleProducts returned no products")
+	}
+	seen := map[int]bool{}
+	accessible := 0
+	for _, p := range products {
+		if seen[p.ID] {
+			t.Errorf("product %d listed twice", p.ID)
+		}
+		seen[p.ID] = true
+		if p.Accessible() {
+			accessible++
+		}
+	}
+	t.Logf("%d of %d products accessible", accessible, len(products))
+}
+
+func TestIntegrationFreeProducts(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	free, err := client.FreeProducts(ctx)
+	skipExpected(t, err)
+	if len(free) == 0 {
+		t.Fatal("FreeProducts returned no products")
+	}
+	var ids []int
+	for id, ok := range free {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	t.Logf("%d of %d products free: %v", len(ids), len(free), ids)
+}
+
+func TestIntegrationProbeAccess(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	access, err := client.ProbeAccess(ctx)
+	skipExpected(t, err)
+	if len(access) == 0 {
+		t.Fatal("ProbeAccess returned no products")
+	}
+	granted := 0
+	for _, status := range access {
+		if status == bdds.AccessGranted {
+			granted++
+		}
+	}
+	t.Logf("%d of %d products accessible", granted, len(access))
+}
+
+func TestIntegrationFetchCatalog(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	catalog, err := client.FetchCatalog(ctx)
+	skipExpected(t, err)
+	if len(catalog.Products) == 0 {
+		t.Fatal("FetchCatalog returned no accessible products")
+	}
+	t.Logf("catalog has %d accessible products", len(catalog.Products))
+}
+
+func TestIntegrationSaveCatalog(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	var buf bytes.Buffer
+	skipExpected(t, client.SaveCatalog(ctx, &buf))
+	catalog, err := bdds.LoadCatalog(&buf)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	for _, p := range catalog.Products {
+		if catalog.Product(p.ID) != p {
+			t.Errorf("Product(%d) does not find the product", p.ID)
+		}
+	}
+}
+
+func TestIntegrationExportCatalog(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	var buf bytes.Buffer
+	skipExpected(t, client.ExportCatalog(ctx, &buf, bdds.ExportJSON))
+	var rows []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("ExportCatalog wrote invalid JSON: %v", err)
+	}
+	for _, r := range rows {
+		if r["product_id"] == nil || r["product_name"] == nil {
+			t.Fatalf("row without product: %v", r)
+		}
+	}
+	t.Logf("exported %d rows", len(rows))
+}
+
 // --- Streaming endpoints --------------------------------------------------
 
 func TestIntegrationDownloadFile(t *testing.T) {
@@ -317,3 +676,194 @@ func TestIntegrationDownloadFileWithProgress(t *testing.T) {
 		t.Fatal("progressFn was never called")
 	}
 }
+
+func TestIntegrationStats(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	var w countingWriter
+	skipExpected(t, client.DownloadFile(ctx, productID, deliveryID, fileID, &w))
+	stats := client.Stats()
+	if stats.FilesDownloaded != 1 || stats.BytesReceived < w.n {
+		t.Fatalf("Stats = %+v after downloading %d bytes", stats, w.n)
+	}
+	t.Logf("throughput: %.0f bytes/s", stats.Throughput())
+}
+
+func TestIntegrationResetStats(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	skipExpected(t, client.DownloadFile(ctx, productID, deliveryID, fileID, &countingWriter{}))
+	if stats := client.ResetStats(); stats.FilesDownloaded != 1 {
+		t.Fatalf("ResetStats = %+v", stats)
+	}
+	if stats := client.Stats(); stats != (bdds.Stats{}) {
+		t.Fatalf("Stats after reset = %+v", stats)
+	}
+}
+
+func TestIntegrationDownloadVolumes(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, size := smallestFile(ctx, t, client)
+	set := &bdds.VolumeSet{
+		Name:    "volume.bin",
+		Volumes: []*bdds.DeliveryFile{{FileID: fileID, FileName: "volume.bin", FileSize: size}},
+	}
+
+	result, err := client.DownloadVolumes(ctx, productID, deliveryID, set, t.TempDir(), nil)
+	skipExpected(t, err)
+	if result.Bytes == 0 || len(result.Paths) != 1 {
+		t.Fatalf("DownloadVolumes result = %+v", result)
+	}
+}
+
+func TestIntegrationDownloadFileParallel(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, size := smallestFile(ctx, t, client)
+	t.Logf("downloading smallest accessible file in segments: product %d delivery %d file %d (%s)",
+		productID, deliveryID, fileID, size)
+
+	f, err := os.Create(t.TempDir() + "/parallel.bin")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	n, err := client.DownloadFileParallel(ctx, productID, deliveryID, fileID, f, &bdds.ParallelOptions{MinSegmentSize: 64 << 10})
+	skipExpected(t, err)
+	if n == 0 {
+		t.Fatal("DownloadFileParallel wrote 0 bytes")
+	}
+}
+
+func TestIntegrationDownloadFileToPath(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	path := t.TempDir() + "/file.bin"
+
+	err := client.DownloadFileToPath(ctx, productID, deliveryID, fileID, path)
+	skipExpected(t, err)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Fatal("DownloadFileToPath wrote 0 bytes")
+	}
+}
+
+func TestIntegrationDownloadAndExtract(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	dir := t.TempDir()
+
+	paths, err := client.DownloadAndExtract(ctx, productID, deliveryID, fileID, dir)
+	var unsupported *bdds.UnsupportedArchiveError
+	if errors.As(err, &unsupported) {
+		t.Skipf("smallest file is not a streamable archive: %v", err)
+	}
+	skipExpected(t, err)
+	if len(paths) == 0 {
+		t.Fatal("DownloadAndExtract extracted no files")
+	}
+}
+
+func TestIntegrationStartDownload(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	path := t.TempDir() + "/file.bin"
+
+	d := client.StartDownload(ctx, productID, deliveryID, fileID, path)
+	skipExpected(t, d.Wait())
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size() != d.BytesWritten() {
+		t.Errorf("file has %d bytes, BytesWritten reports %d", info.Size(), d.BytesWritten())
+	}
+}
+
+func TestIntegrationGetFileInfo(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	info, err := client.GetFileInfo(ctx, productID, deliveryID, fileID)
+	skipExpected(t, err)
+	if info.Size <= 0 {
+		t.Errorf("expected a positive size, got %+v", info)
+	}
+	t.Logf("size=%d type=%q modified=%s name=%q", info.Size, info.ContentType, info.LastModified, info.FileName)
+}
+
+func TestIntegrationDownloadFileStream(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, 2*time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	body, err := client.DownloadFileStream(ctx, productID, deliveryID, fileID)
+	skipExpected(t, err)
+	defer func() { _ = body.Close() }()
+	n, err := io.Copy(io.Discard, body)
+	if err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	if n == 0 {
+		t.Error("expected a non-empty body")
+	}
+}
+
+func TestIntegrationDownloadFileRange(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, time.Minute)
+
+	productID, deliveryID, fileID, _ := smallestFile(ctx, t, client)
+	var buf strings.Builder
+	n, err := client.DownloadFileRange(ctx, productID, deliveryID, fileID, 0, 4, &buf)
+	skipExpected(t, err)
+	if n != 4 || buf.Len() != 4 {
+		t.Errorf("expected 4 bytes, got %d (%q)", n, buf.String())
+	}
+	t.Logf("leading bytes: %q", buf.String())
+}
+
+func TestIntegrationWatchProduct(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, time.Minute)
+
+	productID := firstAccessibleProduct(ctx, t, client)
+	// Only the baseline poll runs before the deadline; it must not fail.
+	watchCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	for e := range client.WatchProduct(watchCtx, productID, time.Hour) {
+		skipExpected(t, e.Err)
+		t.Logf("new delivery during the test: %+v", e.Delivery)
+	}
+}
+
+func TestIntegrationPrune(t *testing.T) {
+	client := testClient(t)
+	ctx := testContext(t, time.Minute)
+
+	productID := firstAccessibleProduct(ctx, t, client)
+	// An empty mirror has nothing to prune, but the catalog is consulted.
+	report, err := client.Prune(ctx, productID, t.TempDir(), nil)
+	skipExpected(t, err)
+	if len(report.Removed) != 0 {
+		t.Errorf("pruned deliveries from an empty mirror: %+v", report.Removed)
+	}
+}
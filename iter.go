@@ -0,0 +1,50 @@
+package bdds
+
+import (
+	"context"
+	"iter"
+)
+
+// AllFiles yields every file of the product with its delivery, in catalog
+// order.
+//
+//	for d, f := range product.AllFiles() {
+//		fmt.Println(d.DeliveryName, f.FileName)
+//	}
+func (p *ProductWithDeliveries) AllFiles() iter.Seq2[*Delivery, *DeliveryFile] {
+	return func(yield func(*Delivery, *DeliveryFile) bool) {
+		for _, d := range p.Deliveries {
+			for _, f := range d.Files {
+				if !yield(d, f) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Deliveries yields the deliveries of a product in catalog order. The
+// product's catalog is fetched when iteration starts, as the API serves it
+// in one response; a failure is yielded as the only error. Unlike
+// ListDeliveries it sorts and copies nothing, and a loop may stop early.
+//
+//	for d, err := range client.Deliveries(ctx, 3) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) Deliveries(ctx context.Context, productID int, opts ...CallOption) iter.Seq2[*Delivery, error] {
+	return func(yield func(*Delivery, error) bool) {
+		product, err := c.GetProduct(ctx, productID, opts...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, d := range product.Deliveries {
+			if !yield(d, nil) {
+				return
+			}
+		}
+	}
+}
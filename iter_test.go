@@ -0,0 +1,58 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllFiles(t *testing.T) {
+	p := &ProductWithDeliveries{Deliveries: []*Delivery{
+		{DeliveryID: 1, Files: []*DeliveryFile{{FileID: 11}, {FileID: 12}}},
+		{DeliveryID: 2},
+		{DeliveryID: 3, Files: []*DeliveryFile{{FileID: 31}}},
+	}}
+	var got []int
+	for d, f := range p.AllFiles() {
+		got = append(got, d.DeliveryID*100+f.FileID)
+	}
+	if len(got) != 3 || got[0] != 111 || got[1] != 112 || got[2] != 331 {
+		t.Errorf("AllFiles yielded %v", got)
+	}
+	for range p.AllFiles() {
+		break // must not panic
+	}
+}
+
+func TestDeliveriesIterator(t *testing.T) {
+	srv, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		{id: 1, name: "a", published: time.Now()},
+		{id: 2, name: "b", published: time.Now()},
+	})
+	defer srv.Close()
+	client, err := NewClient(&Config{BaseURL: srv.URL, MaxRetries: 1, RetryDelay: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var ids []int
+	for d, err := range client.Deliveries(ctx, 3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, d.DeliveryID)
+		break
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("first delivery %v", ids)
+	}
+
+	var notFound *NotFoundError
+	for d, err := range client.Deliveries(ctx, 4) {
+		if d != nil || !errors.As(err, &notFound) {
+			t.Errorf("missing product yielded %v, %v", d, err)
+		}
+	}
+}
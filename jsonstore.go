@@ -0,0 +1,139 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JSONSyncStore is a SyncStore in a single JSON file, for simple deployments.
+// The records are held in memory and the whole file is rewritten with an
+// atomic rename on every Put, so a crash or a concurrent cron run never
+// leaves a truncated state file behind.
+type JSONSyncStore struct {
+	path string
+
+	mu       sync.Mutex
+	records  map[FileRef]*SyncRecord
+	lastRuns map[int]time.Time
+}
+
+// jsonSyncState is the persisted form of a JSONSyncStore.
+type jsonSyncState struct {
+	Files    []*SyncRecord     `json:"files"`
+	LastRuns map[int]time.Time `json:"lastRuns,omitempty"` // by product ID
+}
+
+// NewJSONSyncStore opens the store persisted at path, starting empty if the
+// file does not exist.
+func NewJSONSyncStore(path string) (*JSONSyncStore, error) {
+	s := &JSONSyncStore{path: path, records: make(map[FileRef]*SyncRecord), lastRuns: make(map[int]time.Time)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+	var state jsonSyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse sync state %s: %w", path, err)
+	}
+	for _, r := range state.Files {
+		s.records[r.Ref()] = r
+	}
+	for productID, t := range state.LastRuns {
+		s.lastRuns[productID] = t
+	}
+	return s, nil
+}
+
+// Get implements SyncStore.
+func (s *JSONSyncStore) Get(_ context.Context, ref FileRef) (*SyncRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[ref]; ok {
+		copied := *r
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// Put implements SyncStore. The record is only kept once the file has been
+// written.
+func (s *JSONSyncStore) Put(_ context.Context, record *SyncRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *record
+	files := []*SyncRecord{&copied}
+	for ref, r := range s.records {
+		if ref != record.Ref() {
+			files = append(files, r)
+		}
+	}
+	if err := s.saveLocked(files, s.lastRuns); err != nil {
+		return err
+	}
+	s.records[record.Ref()] = &copied
+	return nil
+}
+
+// LastRun implements SyncStore.
+func (s *JSONSyncStore) LastRun(_ context.Context, productID int) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastRuns[productID], nil
+}
+
+// SetLastRun implements SyncStore.
+func (s *JSONSyncStore) SetLastRun(_ context.Context, productID int, started time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lastRuns := map[int]time.Time{productID: started}
+	for id, t := range s.lastRuns {
+		if id != productID {
+			lastRuns[id] = t
+		}
+	}
+	files := make([]*SyncRecord, 0, len(s.records))
+	for _, r := range s.records {
+		files = append(files, r)
+	}
+	if err := s.saveLocked(files, lastRuns); err != nil {
+		return err
+	}
+	s.lastRuns = lastRuns
+	return nil
+}
+
+// saveLocked writes the given state to the file.
+func (s *JSONSyncStore) saveLocked(files []*SyncRecord, lastRuns map[int]time.Time) error {
+	sortSyncRecords(files)
+	data, err := json.MarshalIndent(jsonSyncState{Files: files, LastRuns: lastRuns}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to persist sync state: %w", err)
+	}
+	return nil
+}
+
+// Records returns all records ordered by product, delivery and file ID.
+func (s *JSONSyncStore) Records() []*SyncRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]*SyncRecord, 0, len(s.records))
+	for _, r := range s.records {
+		copied := *r
+		records = append(records, &copied)
+	}
+	sortSyncRecords(records)
+	return records
+}
@@ -0,0 +1,72 @@
+package bdds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestJSONSyncStore verifies records survive a reopen and that a record whose
+// write failed is not kept.
+func TestJSONSyncStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	store, err := NewJSONSyncStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONSyncStore: %v", err)
+	}
+	downloaded := time.Date(2024, 10, 15, 10, 30, 0, 0, time.UTC)
+	for _, id := range []int{702, 701} {
+		if err := store.Put(ctx, &SyncRecord{ProductID: 3, DeliveryID: 7, FileID: id, Checksum: "AB", DownloadedAt: downloaded}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	if err := store.Put(ctx, &SyncRecord{ProductID: 3, DeliveryID: 7, FileID: 701, Checksum: "CD", DownloadedAt: downloaded}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := store.SetLastRun(ctx, 3, downloaded); err != nil {
+		t.Fatalf("SetLastRun: %v", err)
+	}
+
+	reopened, err := NewJSONSyncStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	records := reopened.Records()
+	if len(records) != 2 || records[0].FileID != 701 || records[0].Checksum != "CD" ||
+		records[1].FileID != 702 || !records[1].DownloadedAt.Equal(downloaded) {
+		t.Errorf("unexpected records after reopen: %+v, %+v", records[0], records[1])
+	}
+
+	if last, _ := reopened.LastRun(ctx, 3); !last.Equal(downloaded) {
+		t.Errorf("LastRun after reopen = %v, want %v", last, downloaded)
+	}
+
+	// Remove the directory so the atomic write fails.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Put(ctx, &SyncRecord{ProductID: 3, DeliveryID: 7, FileID: 703}); err == nil {
+		t.Fatal("expected Put to fail without its directory")
+	}
+	if r, _ := reopened.Get(ctx, FileRef{ProductID: 3, DeliveryID: 7, FileID: 703}); r != nil {
+		t.Errorf("record kept despite failed write: %+v", r)
+	}
+}
+
+// TestJSONSyncStoreCorrupt verifies a state file that is not valid JSON is
+// reported instead of being silently replaced.
+func TestJSONSyncStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"files": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewJSONSyncStore(path); err == nil {
+		t.Fatal("expected an error for a corrupt state file")
+	}
+}
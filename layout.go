@@ -0,0 +1,83 @@
+package bdds
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Default layouts of the bulk download methods (see DownloadOptions.Layout).
+const (
+	deliveryLayout = "{file_name}"
+	productLayout  = "{delivery_name}/{file_name}"
+	filesLayout    = "{product_id}/{delivery_name}/{file_name}"
+)
+
+var layoutPlaceholder = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// layoutFile is what a layout template is expanded for.
+type layoutFile struct {
+	productID   int
+	productName string
+	delivery    *Delivery
+	file        *DeliveryFile
+}
+
+// layoutFields are the placeholders of layout templates.
+var layoutFields = map[string]func(layoutFile) string{
+	"{product_id}":   func(l layoutFile) string { return strconv.Itoa(l.productID) },
+	"{product_name}": func(l layoutFile) string { return sanitizePathElement(l.productName, strconv.Itoa(l.productID)) },
+	"{delivery_id}":  func(l layoutFile) string { return strconv.Itoa(l.delivery.DeliveryID) },
+	"{delivery_name}": func(l layoutFile) string {
+		return sanitizePathElement(l.delivery.DeliveryName, strconv.Itoa(l.delivery.DeliveryID))
+	},
+	"{delivery_date}": func(l layoutFile) string { return l.delivery.DeliveryPublicationDatetime.Format("2006-01-02") },
+	"{file_id}":       func(l layoutFile) string { return strconv.Itoa(l.file.FileID) },
+	"{file_name}": func(l layoutFile) string {
+		return sanitizePathElement(filepath.Base(l.file.FileName), strconv.Itoa(l.file.FileID))
+	},
+}
+
+// expandLayout returns the path of a file below the destination directory.
+// Unknown placeholders are left as is; validateLayout rejects them.
+func expandLayout(layout string, l layoutFile) string {
+	expanded := layoutPlaceholder.ReplaceAllStringFunc(layout, func(p string) string {
+		if field, ok := layoutFields[p]; ok {
+			return field(l)
+		}
+		return p
+	})
+	return filepath.FromSlash(expanded)
+}
+
+// validateLayout rejects unknown placeholders and paths leaving the
+// destination directory.
+func validateLayout(layout string) error {
+	for _, p := range layoutPlaceholder.FindAllString(layout, -1) {
+		if _, ok := layoutFields[p]; !ok {
+			return fmt.Errorf("unknown placeholder %s in layout %q", p, layout)
+		}
+	}
+	if !filepath.IsLocal(filepath.FromSlash(layout)) {
+		return fmt.Errorf("layout %q must be a relative path inside the destination", layout)
+	}
+	return nil
+}
+
+// sanitizePathElement makes s safe as a single path element by replacing
+// characters unsafe in file names, or returns fallback when nothing usable is
+// left.
+func sanitizePathElement(s, fallback string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if name == "" || name == "." || name == ".." {
+		return fallback
+	}
+	return name
+}
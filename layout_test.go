@@ -0,0 +1,92 @@
+package bdds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandLayout(t *testing.T) {
+	l := layoutFile{
+		productID:   3,
+		productName: "EP DocDB: front file",
+		delivery: &Delivery{
+			DeliveryID:                  7,
+			DeliveryName:                "Week 42/2024",
+			DeliveryPublicationDatetime: time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC),
+		},
+		file: &DeliveryFile{FileID: 701, FileName: "sub/20241015_01.zip"},
+	}
+	tests := map[string]string{
+		"{file_name}": "20241015_01.zip",
+		"{product_name}/{delivery_date}/{file_name}":    "EP DocDB_ front file/2024-10-15/20241015_01.zip",
+		"{product_id}/{delivery_id}/{file_id}.zip":      "3/7/701.zip",
+		"{delivery_name}/{file_name}":                   "Week 42_2024/20241015_01.zip",
+		"bdds-{product_id}/{delivery_date}_{file_name}": "bdds-3/2024-10-15_20241015_01.zip",
+	}
+	for layout, want := range tests {
+		if got := expandLayout(layout, l); got != filepath.FromSlash(want) {
+			t.Errorf("expandLayout(%q) = %q, want %q", layout, got, want)
+		}
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	for _, layout := range []string{"{file_name}", "{product_id}/{delivery_name}/{file_name}", "flat/{file_id}"} {
+		if err := validateLayout(layout); err != nil {
+			t.Errorf("validateLayout(%q): %v", layout, err)
+		}
+	}
+	for _, layout := range []string{"{product}/{file_name}", "/abs/{file_name}", "../{file_name}"} {
+		if err := validateLayout(layout); err == nil {
+			t.Errorf("validateLayout(%q) accepted an invalid layout", layout)
+		}
+	}
+}
+
+func TestDownloadProductLayout(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	oct15 := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, stats := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "Week 42", oct15, 1),
+		weeklyDelivery(8, "Week 43", oct15.AddDate(0, 0, 7), 1),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	results, err := client.DownloadProduct(context.Background(), 3, dir, &DownloadOptions{
+		Layout: "{product_name}/{delivery_date}/{file_name}",
+	})
+	if err != nil {
+		t.Fatalf("DownloadProduct: %v", err)
+	}
+	want := []string{
+		filepath.Join("Test product", "2024-10-15", "Week 42_01.zip"),
+		filepath.Join("Test product", "2024-10-22", "Week 43_01.zip"),
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(results))
+	}
+	for i, r := range results {
+		if r.Path != filepath.Join(dir, want[i]) {
+			t.Errorf("result %d path = %s, want %s", i, r.Path, want[i])
+		}
+		if _, err := os.Stat(r.Path); err != nil {
+			t.Errorf("missing %s: %v", want[i], err)
+		}
+	}
+
+	_, err = client.DownloadProduct(context.Background(), 3, dir, &DownloadOptions{Layout: "{date}/{file_name}"})
+	if err == nil || !strings.Contains(err.Error(), "{date}") {
+		t.Errorf("expected an unknown placeholder error, got %v", err)
+	}
+	if stats.downloads != 2 {
+		t.Errorf("expected 2 downloads, got %d", stats.downloads)
+	}
+}
@@ -0,0 +1,81 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// lifetime ends when the client is closed. Clones share the lifetime of
+// the client they were cloned from.
+type lifetime struct {
+	ctx        context.Context // cancelled by Close
+	cancel     context.CancelFunc
+	goroutines sync.WaitGroup // background goroutines Close waits for
+}
+
+func newLifetime() *lifetime {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifetime{ctx: ctx, cancel: cancel}
+}
+
+// goroutine runs fn in a goroutine that Close waits for. The context passed
+// to fn is ctx, cancelled when the client is closed.
+func (c *Client) goroutine(ctx context.Context, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.lifetime.ctx, cancel)
+	c.lifetime.goroutines.Add(1)
+	go func() {
+		defer c.lifetime.goroutines.Done()
+		defer cancel()
+		defer stop()
+		fn(ctx)
+	}()
+}
+
+// Close shuts the client down for a graceful shutdown of its process. It
+// stops its background goroutines (the token refresh and WatchProduct
+// polls, whose channels are closed) and waits for them until ctx is done,
+// revokes the client's access tokens at Config.RevokeURL so they are not
+// left live after a short job, and closes idle connections. Tokens shared
+// through Config.TokenCache are not revoked, as other processes may be
+// using them; the password grant issues no refresh tokens.
+//
+// The sync stores of the package write every change through, so there is
+// nothing to flush; a SyncStore on a caller's database is the caller's to
+// close. The client must not be used afterwards; calls after the first
+// return nil. Closing a clone (see Client.Clone) does nothing.
+func (c *Client) Close(ctx context.Context) error {
+	if c.clonedFrom != nil {
+		return nil
+	}
+	var err error
+	c.closeOnce.Do(func() {
+		c.lifetime.cancel()
+		stopped := make(chan struct{})
+		go func() {
+			c.lifetime.goroutines.Wait()
+			close(stopped)
+		}()
+		if c.stopRefresh != nil {
+			c.stopRefresh()
+			<-c.refreshDone
+		}
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+
+		if c.config.TokenCache == nil {
+			var errs []error
+			for _, a := range c.allAccounts() {
+				errs = append(errs, c.revokeToken(ctx, a))
+			}
+			err = errors.Join(errs...)
+		}
+		c.httpClient.CloseIdleConnections()
+	})
+	return err
+}
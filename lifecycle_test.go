@@ -0,0 +1,55 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestCloseStopsWatches verifies Close ends the channels of running watches
+// and that a watch started after Close ends at once.
+func TestCloseStopsWatches(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": 3, "name": "Test product", "description": "test", "deliveries": []interface{}{},
+		})
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	drained := func(events <-chan DeliveryEvent) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+			case <-timeout:
+				t.Fatal("watch channel not closed")
+			}
+		}
+	}
+
+	events := client.WatchProduct(context.Background(), 3, time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		drained(events)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	<-done
+
+	drained(client.WatchProduct(context.Background(), 3, time.Millisecond))
+}
@@ -0,0 +1,57 @@
+package bdds
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// limitedTransport admits at most cap(sem) requests at a time. A slot is held
+// from sending the request until its response body is closed, so long-running
+// downloads count for their full duration.
+type limitedTransport struct {
+	base http.RoundTripper
+	sem  chan struct{}
+}
+
+func newLimitedTransport(base http.RoundTripper, limit int) *limitedTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &limitedTransport{base: base, sem: make(chan struct{}, limit)}
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := func() { <-t.sem }
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *limitedTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// releasingBody frees its request slot when closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
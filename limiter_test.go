@@ -0,0 +1,66 @@
+package bdds
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestMaxConcurrentRequests verifies concurrent downloads never exceed the
+// configured number of requests in flight.
+func TestMaxConcurrentRequests(t *testing.T) {
+	var inFlight, peak int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(&Config{BaseURL: apiServer.URL, MaxConcurrentRequests: 2})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := client.DownloadFile(context.Background(), 1, 2, id, io.Discard); err != nil {
+				t.Errorf("DownloadFile %d: %v", id, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if p := atomic.LoadInt32(&peak); p != 2 {
+		t.Errorf("peak concurrent requests = %d, want 2", p)
+	}
+}
+
+// TestLimitedTransportHonoursContext verifies a request waiting for a slot
+// gives up when its context ends.
+func TestLimitedTransportHonoursContext(t *testing.T) {
+	lt := newLimitedTransport(nil, 1)
+	lt.sem <- struct{}{} // occupy the only slot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid", nil)
+	if _, err := lt.RoundTrip(req); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
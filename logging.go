@@ -0,0 +1,52 @@
+package bdds
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// logger returns Config.Logger, or a logger that discards everything.
+func (c *Client) logger() *slog.Logger {
+	if c.config != nil && c.config.Logger != nil {
+		return c.config.Logger
+	}
+	return discardLogger
+}
+
+// loggingTransport logs each request sent, including retries and the OAuth
+// calls, with its outcome and duration.
+type loggingTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	attrs := []any{"method", req.Method, "url", logURL(req.URL), "request_id", req.Header.Get(RequestIDHeader),
+		"duration", time.Since(start)}
+	if err != nil {
+		t.logger.DebugContext(req.Context(), "bdds: request failed", append(attrs, "error", redactURLError(err))...)
+		return nil, err
+	}
+	t.logger.DebugContext(req.Context(), "bdds: request", append(attrs, "status", resp.StatusCode)...)
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *loggingTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// logURL formats u without credentials and query, which may carry secrets.
+func logURL(u *url.URL) string {
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
@@ -0,0 +1,99 @@
+package bdds
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Manifest is a machine-readable provenance record of a bulk download, as
+// written by WriteManifest.
+type Manifest struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Files       []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry records one file of a bulk download.
+type ManifestEntry struct {
+	ProductID       int       `json:"productId"`
+	DeliveryID      int       `json:"deliveryId"`
+	FileID          int       `json:"fileId"`
+	FileName        string    `json:"fileName"`
+	CatalogSize     string    `json:"catalogSize,omitempty"`     // human-readable size from the catalog
+	CatalogChecksum string    `json:"catalogChecksum,omitempty"` // checksum published in the catalog
+	Checksum        string    `json:"checksum,omitempty"`        // checksum computed from the local file
+	ChecksumType    string    `json:"checksumType,omitempty"`    // "sha1", "md5" or "sha256"
+	Size            int64     `json:"size"`                      // local file size in bytes
+	Path            string    `json:"path,omitempty"`
+	PublishedAt     time.Time `json:"publishedAt,omitzero"`
+	CompletedAt     time.Time `json:"completedAt,omitzero"`
+	Skipped         bool      `json:"skipped,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// WriteManifest writes a JSON manifest of bulk download results to path,
+// atomically. The checksum of every downloaded or skipped file is recomputed
+// from disk with the algorithm of its catalog checksum (SHA-1 when the
+// catalog has none), so the manifest records what is actually stored.
+func WriteManifest(path string, results []*FileResult) error {
+	m := Manifest{GeneratedAt: time.Now().UTC(), Files: make([]ManifestEntry, 0, len(results))}
+	for _, r := range results {
+		entry := ManifestEntry{
+			ProductID:       r.ProductID,
+			DeliveryID:      r.DeliveryID,
+			FileID:          r.File.FileID,
+			FileName:        r.File.FileName,
+			CatalogSize:     r.File.FileSize,
+			CatalogChecksum: r.File.FileChecksum,
+			Path:            r.Path,
+			PublishedAt:     r.File.FilePublicationDatetime,
+			CompletedAt:     r.CompletedAt,
+			Skipped:         r.Skipped,
+		}
+		if r.Err != nil {
+			entry.Error = r.Err.Error()
+			m.Files = append(m.Files, entry)
+			continue
+		}
+
+		info, err := os.Stat(r.Path)
+		if err != nil {
+			return fmt.Errorf("manifest: %w", err)
+		}
+		entry.Size = info.Size()
+		h, kind := newChecksumHash(r.File.FileChecksum)
+		if h == nil {
+			h, kind = sha1.New(), "sha1"
+		}
+		sum, err := fileChecksum(r.Path, h)
+		if err != nil {
+			return fmt.Errorf("manifest: %w", err)
+		}
+		entry.Checksum, entry.ChecksumType = strings.ToUpper(sum), kind
+		m.Files = append(m.Files, entry)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// writeRequestedManifest writes the manifest requested in opts, if any.
+func writeRequestedManifest(results []*FileResult, opts *DownloadOptions) error {
+	if opts == nil || opts.ManifestPath == "" || opts.DryRun {
+		return nil
+	}
+	return WriteManifest(opts.ManifestPath, results)
+}
@@ -0,0 +1,46 @@
+package bdds
+
+import "net/http"
+
+// Middleware wraps the transport that sends the client's requests, e.g. to
+// add headers, sign requests, cache responses or record metrics. It sees
+// every request, including retries and the OAuth calls, which can be told
+// apart by their URL.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper, e.g. in a
+// Middleware.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// middlewareTransport is a transport wrapped in Config.Middleware. It keeps
+// the transport to close the idle connections of, which the middleware
+// usually hides.
+type middlewareTransport struct {
+	http.RoundTripper
+	inner http.RoundTripper
+}
+
+// withMiddleware wraps rt in middleware, the first outermost.
+func withMiddleware(rt http.RoundTripper, middleware []Middleware) http.RoundTripper {
+	if len(middleware) == 0 {
+		return rt
+	}
+	inner := rt
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		rt = middleware[i](rt)
+	}
+	return &middlewareTransport{RoundTripper: rt, inner: inner}
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport.
+func (t *middlewareTransport) CloseIdleConnections() {
+	closeIdleConnections(t.inner)
+}
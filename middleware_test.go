@@ -0,0 +1,90 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMiddleware verifies middleware wraps the API and OAuth requests, the
+// first outermost, and that the idle connections of the wrapped transport
+// are still closed.
+func TestMiddleware(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		trace []string
+	)
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		trace = append(trace, "auth "+strings.Join(r.Header.Values("X-Trace"), ","))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		trace = append(trace, "api "+strings.Join(r.Header.Values("X-Trace"), ","))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]interface{}{})
+	}))
+	defer apiServer.Close()
+
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.Header.Add("X-Trace", name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	inner := &closeCountingTransport{}
+	client, err := NewClient(&Config{
+		Username:   "u",
+		Password:   "p",
+		BaseURL:    apiServer.URL,
+		TokenURL:   authServer.URL,
+		RevokeURL:  authServer.URL,
+		RetryDelay: time.Millisecond,
+		Transport:  inner,
+		Middleware: []Middleware{tag("outer"), tag("inner")},
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	mu.Lock()
+	got := strings.Join(trace, "; ")
+	mu.Unlock()
+	if !strings.HasPrefix(got, "auth outer,inner; api outer,inner") {
+		t.Errorf("requests = %q", got)
+	}
+	if inner.closed == 0 {
+		t.Error("idle connections of the wrapped transport not closed")
+	}
+}
+
+type closeCountingTransport struct {
+	closed int
+}
+
+func (t *closeCountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (t *closeCountingTransport) CloseIdleConnections() {
+	t.closed++
+}
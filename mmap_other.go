@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package bdds
+
+import "os"
+
+func mapFile(*os.File, int64) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+func unmapFile([]byte) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package bdds
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// foldedLetters maps accented Latin letters to their base letters. The
+// module has no dependency on golang.org/x/text, and product names only use
+// Latin script.
+var foldedLetters = func() map[rune]string {
+	m := make(map[rune]string)
+	for base, accented := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđ", "e": "èéêëēĕėęě",
+		"g": "ĝğġģ", "h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ",
+		"l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏő", "r": "ŕŗř",
+		"s": "śŝşšș", "t": "ţťŧț", "u": "ùúûüũūŭůűų", "w": "ŵ", "y": "ýÿŷ",
+		"z": "źżž", "ae": "æ", "oe": "œ", "ss": "ß",
+	} {
+		for _, r := range accented {
+			m[r] = base
+		}
+	}
+	return m
+}()
+
+// normalizeName reduces a name to lower-case words without diacritics, so
+// "EP full-text data – front file" and "ep full text data - front file"
+// compare equal. Dashes and other punctuation separate words.
+func normalizeName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case foldedLetters[r] != "":
+			b.WriteString(foldedLetters[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// matchProducts finds the products that name refers to, trying in turn a
+// case-insensitive match, a match of normalized names (see normalizeName)
+// and a partial match, where every word of name occurs in the product's
+// name. It returns the matches of the first kind that finds any.
+func matchProducts(products []*Product, name string) []*Product {
+	products = uniqueProducts(products)
+	for _, p := range products {
+		if strings.EqualFold(p.Name, name) {
+			return []*Product{p}
+		}
+	}
+	query := normalizeName(name)
+	if query == "" {
+		return nil
+	}
+	var normalized, partial []*Product
+	for _, p := range products {
+		candidate := normalizeName(p.Name)
+		if candidate == query {
+			normalized = append(normalized, p)
+			continue
+		}
+		if containsWords(candidate, strings.Fields(query)) {
+			partial = append(partial, p)
+		}
+	}
+	if len(normalized) > 0 {
+		return normalized
+	}
+	return partial
+}
+
+// uniqueProducts returns products without repeated IDs, keeping the first
+// entry of each. The catalog lists some products in more than one section.
+func uniqueProducts(products []*Product) []*Product {
+	seen := make(map[int]bool, len(products))
+	unique := make([]*Product, 0, len(products))
+	for _, p := range products {
+		if !seen[p.ID] {
+			seen[p.ID] = true
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
+
+// containsWords reports whether each of words occurs in s, in any order.
+func containsWords(s string, words []string) bool {
+	return !slices.ContainsFunc(words, func(w string) bool { return !strings.Contains(s, w) })
+}
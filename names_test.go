@@ -0,0 +1,110 @@
+package bdds
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	for in, want := range map[string]string{
+		"EP full-text data – front file": "ep full text data front file",
+		"  Données   Brevets—Europe ":    "donnees brevets europe",
+		"EP DocDB (front file)":          "ep docdb front file",
+		"Straße":                         "strasse",
+	} {
+		if got := normalizeName(in); got != want {
+			t.Errorf("normalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMatchProducts(t *testing.T) {
+	products := []*Product{
+		{ID: 3, Name: "EP DocDB front file"},
+		{ID: 4, Name: "EP full-text data – front file"},
+		{ID: 14, Name: "EP DocDB back file"},
+		{ID: 32, Name: "EP DocDB"},
+	}
+	ids := func(ps []*Product) []int {
+		var out []int
+		for _, p := range ps {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+	for _, tt := range []struct {
+		name string
+		want []int
+	}{
+		{"ep docdb front file", []int{3}},
+		{"EP full-text data - front file", []int{4}},
+		{"EP Full Text Data Front File", []int{4}},
+		{"full text", []int{4}},
+		{"docdb back", []int{14}},
+		{"EP DocDB", []int{32}}, // exact beats partial
+		{"front file", []int{3, 4}},
+		{"docdb", []int{3, 14, 32}},
+		{"ep – docdb", []int{32}}, // normalized beats partial
+		{"DOCDB FRONT", []int{3}},
+		{"unknown", nil},
+		{" – ", nil},
+	} {
+		if got := ids(matchProducts(products, tt.name)); !slices.Equal(got, tt.want) {
+			t.Errorf("matchProducts(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAmbiguousMatchError(t *testing.T) {
+	var err error = &AmbiguousMatchError{Resource: "product", Query: "front file", Candidates: []string{"A", "B"}}
+	var ambiguous *AmbiguousMatchError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("errors.As failed for %v", err)
+	}
+	if want := `ambiguous product name "front file": matches "A", "B"`; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+// recordedProducts returns the products of the recorded ListProducts
+// response, which lists some products twice.
+func recordedProducts(t *testing.T) []*Product {
+	t.Helper()
+	data, err := exampleFS.ReadFile(examplesDir + "/01-ListProducts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var example struct {
+		Response struct {
+			Body []*Product `json:"body"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(data, &example); err != nil {
+		t.Fatal(err)
+	}
+	return example.Response.Body
+}
+
+// TestMatchProductsRepeatedRows verifies a product listed twice in the
+// catalog matches once instead of being ambiguous with itself.
+func TestMatchProductsRepeatedRows(t *testing.T) {
+	products := recordedProducts(t)
+	name := "14.11 EPO worldwide legal event data (INPADOC) back file"
+	var rows int
+	for _, p := range products {
+		if p.ID == 11 {
+			rows++
+		}
+	}
+	if rows < 2 {
+		t.Fatalf("fixture lists product 11 %d times, want a repeated row", rows)
+	}
+	if got := matchProducts(products, name); len(got) != 1 || got[0].ID != 11 {
+		t.Errorf("matchProducts(%q) = %v, want product 11 once", name, got)
+	}
+	if got := matchProducts(products, "inpadoc"); len(got) != 2 || got[0].ID != 5 || got[1].ID != 11 {
+		t.Errorf("matchProducts(inpadoc) = %v, want products 5 and 11", got)
+	}
+}
@@ -0,0 +1,197 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// NotificationKind is the kind of a Notification.
+type NotificationKind int
+
+const (
+	// NewDeliveryNotification: a sync run found a delivery with files to fetch.
+	NewDeliveryNotification NotificationKind = iota + 1
+	// RunSummaryNotification: a sync run completed without errors.
+	RunSummaryNotification
+	// RunFailedNotification: a sync run completed with errors.
+	RunFailedNotification
+)
+
+func (k NotificationKind) String() string {
+	switch k {
+	case NewDeliveryNotification:
+		return "new delivery"
+	case RunSummaryNotification:
+		return "run summary"
+	case RunFailedNotification:
+		return "run failed"
+	}
+	return fmt.Sprintf("NotificationKind(%d)", int(k))
+}
+
+// Notification is an operator alert about a sync run, with a ready-made
+// title and plain-text body.
+type Notification struct {
+	Kind      NotificationKind
+	Title     string
+	Text      string
+	ProductID int              // NewDeliveryNotification
+	Delivery  *Delivery        // NewDeliveryNotification
+	Summary   []ProductSummary // run notifications
+	Failures  []*FileResult    // RunFailedNotification
+	Err       error            // RunFailedNotification
+}
+
+// Notifier delivers notifications, e.g. to a chat channel or a mailbox.
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// NotifierFunc adapts a function to a Notifier.
+type NotifierFunc func(ctx context.Context, n *Notification) error
+
+// Notify calls f.
+func (f NotifierFunc) Notify(ctx context.Context, n *Notification) error {
+	return f(ctx, n)
+}
+
+// notifyTimeout bounds a single notification sent from a Sync subscriber.
+const notifyTimeout = 30 * time.Second
+
+// NotifySync returns a Sync subscriber (see Sync.Subscribe) that sends a
+// notification for every delivery discovered and one summary per run,
+// listing the failed files if there were any. onError, if set, receives the
+// errors of the notifier; notifications are best effort otherwise.
+//
+//	s.Subscribe(bdds.NotifySync(&bdds.SlackNotifier{WebhookURL: url}, nil))
+func NotifySync(n Notifier, onError func(error)) func(SyncEvent) {
+	return func(e SyncEvent) {
+		var note *Notification
+		switch e.Type {
+		case DeliveryDiscovered:
+			note = &Notification{
+				Kind:      NewDeliveryNotification,
+				Title:     fmt.Sprintf("New delivery %s of product %d", e.Delivery.DeliveryName, e.ProductID),
+				Text:      fmt.Sprintf("Published %s with %d files.", e.Delivery.DeliveryPublicationDatetime.UTC().Format(time.RFC3339), len(e.Delivery.Files)),
+				ProductID: e.ProductID,
+				Delivery:  e.Delivery,
+			}
+		case RunCompleted:
+			note = runNotification(e.Results, e.Err)
+		default:
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := n.Notify(ctx, note); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// runNotification summarizes a completed sync run.
+func runNotification(results []*FileResult, err error) *Notification {
+	note := &Notification{Kind: RunSummaryNotification, Title: "Sync run completed", Summary: Summarize(results), Err: err}
+	var text strings.Builder
+	for _, s := range note.Summary {
+		fmt.Fprintf(&text, "Product %d: %d downloaded, %d skipped, %d failed, %d bytes\n",
+			s.ProductID, s.Downloaded, s.Skipped, s.Failed, s.Bytes)
+	}
+	if err != nil {
+		note.Kind, note.Title = RunFailedNotification, "Sync run failed"
+		for _, r := range results {
+			if r.Err != nil {
+				note.Failures = append(note.Failures, r)
+				fmt.Fprintf(&text, "%s: %v\n", r.File.FileName, r.Err)
+			}
+		}
+		if len(note.Failures) == 0 {
+			fmt.Fprintf(&text, "%v\n", err)
+		}
+	}
+	if text.Len() == 0 {
+		text.WriteString("Nothing to download.\n")
+	}
+	note.Text = strings.TrimSuffix(text.String(), "\n")
+	return note
+}
+
+// SlackNotifier posts notifications to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	// HTTPClient sends the requests (default: a client with a 30s timeout).
+	HTTPClient *http.Client
+}
+
+// Notify implements Notifier.
+func (s *SlackNotifier) Notify(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(map[string]string{"text": "*" + n.Title + "*\n" + n.Text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return redactURLError(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Slack notification: %w", redactURLError(err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to post Slack notification: %s: %s", resp.Status, strings.TrimSpace(redactSecrets(string(msg))))
+	}
+	return nil
+}
+
+// EmailNotifier sends notifications as plain-text email over SMTP.
+type EmailNotifier struct {
+	Addr string    // SMTP server, host:port
+	Auth smtp.Auth // nil for an unauthenticated relay
+	From string
+	To   []string
+	// SubjectPrefix is prepended to the subject (default: "[bdds] ").
+	SubjectPrefix string
+}
+
+// Notify implements Notifier. The context is only checked before sending;
+// net/smtp does not support cancellation.
+func (e *EmailNotifier) Notify(ctx context.Context, n *Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	prefix := e.SubjectPrefix
+	if prefix == "" {
+		prefix = "[bdds] "
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerSafe(prefix+n.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Text, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	if err := smtp.SendMail(e.Addr, e.Auth, e.From, e.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send notification email: %w", err)
+	}
+	return nil
+}
+
+// headerSafe strips line breaks that would end a mail header early.
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
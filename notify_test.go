@@ -0,0 +1,149 @@
+package bdds
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifySync(t *testing.T) {
+	var notes []*Notification
+	notify := NotifySync(NotifierFunc(func(_ context.Context, n *Notification) error {
+		notes = append(notes, n)
+		return errors.New("unreachable")
+	}), func(err error) {
+		if err.Error() != "unreachable" {
+			t.Errorf("unexpected notifier error: %v", err)
+		}
+	})
+
+	d := &Delivery{DeliveryID: 7, DeliveryName: "2024-10-15", Files: []*DeliveryFile{{FileID: 701}}}
+	failed := &FileResult{ProductID: 3, DeliveryID: 7, File: &DeliveryFile{FileID: 702, FileName: "b.zip"}, Err: errors.New("boom")}
+	notify(SyncEvent{Type: DeliveryDiscovered, ProductID: 3, Delivery: d})
+	notify(SyncEvent{Type: FileStarted, ProductID: 3, File: failed})
+	notify(SyncEvent{Type: FileFailed, ProductID: 3, File: failed, Err: failed.Err})
+	notify(SyncEvent{Type: RunCompleted, Results: []*FileResult{
+		{ProductID: 3, DeliveryID: 7, File: &DeliveryFile{FileID: 701, FileName: "a.zip"}, Bytes: 10},
+		failed,
+	}, Err: failed.Err})
+	notify(SyncEvent{Type: RunCompleted})
+
+	if len(notes) != 3 {
+		t.Fatalf("got %d notifications, want 3", len(notes))
+	}
+	if n := notes[0]; n.Kind != NewDeliveryNotification || n.Delivery != d || !strings.Contains(n.Title, "2024-10-15") {
+		t.Errorf("new delivery notification = %+v", n)
+	}
+	if n := notes[1]; n.Kind != RunFailedNotification || len(n.Failures) != 1 || !strings.Contains(n.Text, "b.zip: boom") ||
+		!strings.Contains(n.Text, "Product 3: 1 downloaded, 0 skipped, 1 failed, 10 bytes") {
+		t.Errorf("failed run notification = %+v", n)
+	}
+	if n := notes[2]; n.Kind != RunSummaryNotification || n.Text != "Nothing to download." {
+		t.Errorf("empty run notification = %+v", n)
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if strings.Contains(got["text"], "fail") {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	s := &SlackNotifier{WebhookURL: srv.URL}
+	if err := s.Notify(context.Background(), &Notification{Title: "Sync run completed", Text: "Product 3: ok"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got["text"] != "*Sync run completed*\nProduct 3: ok" {
+		t.Errorf("text = %q", got["text"])
+	}
+	err := s.Notify(context.Background(), &Notification{Title: "fail"})
+	if err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("expected the Slack error, got %v", err)
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	received := make(chan string, 1)
+	go serveFakeSMTP(ln, received)
+
+	e := &EmailNotifier{Addr: ln.Addr().String(), From: "bdds@example.com", To: []string{"ops@example.com"}}
+	note := &Notification{Title: "Sync run failed\nBcc: x@example.com", Text: "a.zip: boom\nb.zip: boom"}
+	if err := e.Notify(context.Background(), note); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	select {
+	case msg := <-received:
+		for _, want := range []string{
+			"To: ops@example.com\r\n",
+			"Subject: [bdds] Sync run failed Bcc: x@example.com\r\n",
+			"\r\n\r\na.zip: boom\r\nb.zip: boom\r\n",
+		} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("message lacks %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+// serveFakeSMTP accepts one SMTP session and sends the message data to
+// received.
+func serveFakeSMTP(ln net.Listener, received chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			received <- data.String()
+			reply("250 ok")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
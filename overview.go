@@ -0,0 +1,93 @@
+package bdds
+
+import (
+	"context"
+	"time"
+)
+
+// ProductOverview describes a product and its deliveries without their
+// files; see GetProductOverview.
+type ProductOverview struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// LatestPublication is when the newest delivery was published, zero if
+	// there are none.
+	LatestPublication time.Time `json:"latestPublication,omitzero"`
+	// FileCount is the number of files of all deliveries.
+	FileCount  int                 `json:"fileCount"`
+	Deliveries []*DeliveryOverview `json:"deliveries"`
+}
+
+// DeliveryOverview describes a delivery without its files.
+type DeliveryOverview struct {
+	DeliveryID                  int        `json:"deliveryId"`
+	DeliveryName                string     `json:"deliveryName"`
+	DeliveryPublicationDatetime time.Time  `json:"deliveryPublicationDatetime"`
+	DeliveryExpiryDatetime      *time.Time `json:"deliveryExpiryDatetime"`
+	FileCount                   int        `json:"fileCount"`
+}
+
+// wireDeliveryOverview decodes a delivery counting its files: elements of
+// an empty struct take no memory.
+type wireDeliveryOverview struct {
+	DeliveryID                  int        `json:"deliveryId"`
+	DeliveryName                string     `json:"deliveryName"`
+	DeliveryPublicationDatetime flexTime   `json:"deliveryPublicationDatetime"`
+	DeliveryExpiryDatetime      flexTime   `json:"deliveryExpiryDatetime"`
+	Files                       []struct{} `json:"files"`
+}
+
+type wireProductOverview struct {
+	ID          int                    `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Deliveries  []wireDeliveryOverview `json:"deliveries"`
+}
+
+// GetProductOverview returns a product with an overview of each delivery
+// instead of its files, for products with years of history whose file
+// lists are not needed, e.g. to show delivery counts and dates. The API has
+// no endpoint for this, so the full catalog is still transferred (and cached
+// like GetProduct's), but the files are not decoded into memory.
+func (c *Client) GetProductOverview(ctx context.Context, productID int, opts ...CallOption) (*ProductOverview, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+	var result *ProductOverview
+	err := c.fetchProduct(ctx, productID, func(body []byte) error {
+		// Not strict: the files' fields are deliberately left undecoded.
+		// GetProduct reports schema drift.
+		dec := &catalogDecoder{endpoint: "GetProductOverview"}
+		var wire wireProductOverview
+		if err := dec.decode(body, &wire); err != nil {
+			return err
+		}
+		result = productOverview(&wire)
+		return nil
+	})
+	return result, err
+}
+
+func productOverview(p *wireProductOverview) *ProductOverview {
+	result := &ProductOverview{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Deliveries:  make([]*DeliveryOverview, len(p.Deliveries)),
+	}
+	for i, wd := range p.Deliveries {
+		d := &DeliveryOverview{
+			DeliveryID:                  wd.DeliveryID,
+			DeliveryName:                wd.DeliveryName,
+			DeliveryPublicationDatetime: wd.DeliveryPublicationDatetime.Time,
+			DeliveryExpiryDatetime:      wd.DeliveryExpiryDatetime.ptr(),
+			FileCount:                   len(wd.Files),
+		}
+		result.Deliveries[i] = d
+		result.FileCount += d.FileCount
+		if d.DeliveryPublicationDatetime.After(result.LatestPublication) {
+			result.LatestPublication = d.DeliveryPublicationDatetime
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package bdds
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetProductOverview(t *testing.T) {
+	week := func(n int) time.Time { return time.Date(2024, 10, 1+7*n, 10, 0, 0, 0, time.UTC) }
+	expiry := week(52)
+	srv, _ := newMirrorServer(t, 14, []mirrorDelivery{
+		{id: 1, name: "2024-10-01", published: week(0), expiry: &expiry, files: []mirrorFile{
+			{id: 11, name: "a.zip", content: "a"}, {id: 12, name: "b.zip", content: "b"},
+		}},
+		{id: 3, name: "2024-10-15", published: week(2), files: []mirrorFile{{id: 31, name: "c.zip", content: "c"}}},
+		{id: 2, name: "2024-10-08", published: week(1)},
+	})
+	defer srv.Close()
+	var requests int32
+	client, err := NewClient(&Config{
+		BaseURL:      srv.URL,
+		CatalogCache: NewMemoryCatalogCache(),
+		Middleware: []Middleware{func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&requests, 1)
+				return next.RoundTrip(r)
+			})
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	overview, err := client.GetProductOverview(ctx, 14)
+	if err != nil {
+		t.Fatalf("GetProductOverview: %v", err)
+	}
+	if overview.ID != 14 || overview.Name != "Test product" {
+		t.Errorf("product = %d %q", overview.ID, overview.Name)
+	}
+	if len(overview.Deliveries) != 3 || overview.FileCount != 3 {
+		t.Fatalf("%d deliveries with %d files, want 3 with 3", len(overview.Deliveries), overview.FileCount)
+	}
+	if !overview.LatestPublication.Equal(week(2)) {
+		t.Errorf("LatestPublication = %v, want %v", overview.LatestPublication, week(2))
+	}
+	first := overview.Deliveries[0]
+	if first.DeliveryID != 1 || first.FileCount != 2 || first.DeliveryExpiryDatetime == nil || !first.DeliveryExpiryDatetime.Equal(expiry) {
+		t.Errorf("first delivery = %+v", first)
+	}
+	if d := overview.Deliveries[2]; d.FileCount != 0 || d.DeliveryExpiryDatetime != nil {
+		t.Errorf("empty delivery = %+v", d)
+	}
+
+	// The overview and the full product share the cached catalog.
+	if _, err := client.GetProduct(ctx, 14); err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("%d catalog requests, want 1", n)
+	}
+
+	if _, err := client.GetProductOverview(ctx, 99); err == nil {
+		t.Error("unknown product accepted")
+	}
+}
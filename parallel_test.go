@@ -0,0 +1,270 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRangeServer serves content with full Range support via http.ServeContent.
+// The first ranged request for a non-zero start drops the connection halfway
+// through when failOnce is set, to exercise per-segment resume.
+func newRangeServer(t *testing.T, content []byte, failOnce bool) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	var failed int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rng := r.Header.Get("Range")
+		if failOnce && rng != "" && !strings.HasPrefix(rng, "bytes=0-") && atomic.CompareAndSwapInt32(&failed, 0, 1) {
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Error("response writer does not support hijacking")
+				return
+			}
+			conn, buf, err := hj.Hijack()
+			if err != nil {
+				t.Errorf("hijack: %v", err)
+				return
+			}
+			var start, end int
+			_, _ = parseRangeRequest(rng, &start, &end)
+			_, _ = buf.WriteString("HTTP/1.1 206 Partial Content\r\nContent-Range: bytes " +
+				strconv.Itoa(start) + "-" + strconv.Itoa(end) + "/" + strconv.Itoa(len(content)) +
+				"\r\nContent-Length: " + strconv.Itoa(end-start+1) + "\r\n\r\n")
+			_, _ = buf.Write(content[start : start+(end-start+1)/2])
+			_ = buf.Flush()
+			_ = conn.Close()
+			return
+		}
+		http.ServeContent(w, r, "file.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	return srv, &requests
+}
+
+// parseRangeRequest parses a "bytes=start-end" request header.
+func parseRangeRequest(rng string, start, end *int) (int, error) {
+	spec := strings.TrimPrefix(rng, "bytes=")
+	first, last, _ := strings.Cut(spec, "-")
+	var err error
+	if *start, err = strconv.Atoi(first); err != nil {
+		return 0, err
+	}
+	if *end, err = strconv.Atoi(last); err != nil {
+		return 1, err
+	}
+	return 2, nil
+}
+
+func testContent(n int) []byte {
+	content := make([]byte, n)
+	for i := range content {
+		content[i] = byte('a' + i%26)
+	}
+	return content
+}
+
+// TestDownloadFileParallel verifies a file is reassembled from concurrent
+// segments, including one that fails midway and resumes.
+func TestDownloadFileParallel(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	content := testContent(1000)
+	apiServer, requests := newRangeServer(t, content, true)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	path := filepath.Join(t.TempDir(), "file.zip")
+	sf, err := CreateSegmentFile(path, int64(len(content)), nil)
+	if err != nil {
+		t.Fatalf("CreateSegmentFile: %v", err)
+	}
+	var lastWritten, lastTotal int64
+	n, err := client.DownloadFileParallel(context.Background(), 1, 2, 3, sf, &ParallelOptions{
+		Parallelism:    4,
+		MinSegmentSize: 100,
+		Progress:       func(written, total int64) { lastWritten, lastTotal = written, total },
+	})
+	if err != nil {
+		t.Fatalf("DownloadFileParallel: %v", err)
+	}
+	if err := sf.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("size = %d, want %d", n, len(content))
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Error("reassembled content differs from source")
+	}
+	if lastWritten != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("final progress = %d/%d, want %d", lastWritten, lastTotal, len(content))
+	}
+	// probe + 4 segments + 1 resumed segment
+	if c := atomic.LoadInt32(requests); c != 6 {
+		t.Errorf("expected 6 requests, got %d", c)
+	}
+}
+
+// TestDownloadFileParallelNoRangeSupport verifies a server that ignores Range
+// is handled with a single streamed download.
+func TestDownloadFileParallelNoRangeSupport(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	content := testContent(500)
+	var requests int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write(content)
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "file.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	n, err := client.DownloadFileParallel(context.Background(), 1, 2, 3, f, &ParallelOptions{MinSegmentSize: 10})
+	if err != nil {
+		t.Fatalf("DownloadFileParallel: %v", err)
+	}
+	got, _ := os.ReadFile(f.Name())
+	if n != int64(len(content)) || !bytes.Equal(got, content) {
+		t.Errorf("fallback download wrote %d bytes, content equal = %v", n, bytes.Equal(got, content))
+	}
+	if c := atomic.LoadInt32(&requests); c != 1 {
+		t.Errorf("expected a single request, got %d", c)
+	}
+}
+
+// TestPlanSegments verifies segment planning covers the file exactly.
+func TestPlanSegments(t *testing.T) {
+	tests := []struct {
+		size        int64
+		parallelism int
+		minSize     int64
+		want        int
+	}{
+		{size: 1000, parallelism: 4, minSize: 100, want: 4},
+		{size: 1000, parallelism: 4, minSize: 600, want: 2},
+		{size: 10, parallelism: 8, minSize: 100, want: 1},
+		{size: 1001, parallelism: 3, minSize: 1, want: 3},
+	}
+	for _, tt := range tests {
+		segs := planSegments(tt.size, tt.parallelism, tt.minSize)
+		if len(segs) != tt.want {
+			t.Errorf("planSegments(%d, %d, %d) = %d segments, want %d", tt.size, tt.parallelism, tt.minSize, len(segs), tt.want)
+		}
+		var next int64
+		for _, s := range segs {
+			if s.start != next || s.end < s.start {
+				t.Errorf("planSegments(%d): non-contiguous segment %+v", tt.size, s)
+			}
+			next = s.end + 1
+		}
+		if next != tt.size {
+			t.Errorf("planSegments(%d): segments end at %d", tt.size, next)
+		}
+	}
+}
+
+// TestParseContentRange verifies Content-Range parsing.
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		in   string
+		want contentRange
+		ok   bool
+	}{
+		{in: "bytes 0-0/1234", want: contentRange{0, 0, 1234}, ok: true},
+		{in: "bytes 100-199/*", want: contentRange{100, 199, -1}, ok: true},
+		{in: "bytes */0", want: contentRange{-1, -1, 0}, ok: true},
+		{in: "bytes 5-1/10"},
+		{in: "items 0-1/2"},
+		{in: ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseContentRange(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseContentRange(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestDownloadFileRange verifies ranged reads, including a resumed range, a
+// read to the end of the file, a range clipped at the end, and a server that
+// ignores Range.
+func TestDownloadFileRange(t *testing.T) {
+	content := testContent(1000)
+	tests := []struct {
+		name           string
+		offset, length int64
+		failOnce       bool
+		ignoreRange    bool
+		want           []byte
+	}{
+		{"resumed range", 100, 200, true, false, content[100:300]},
+		{"to end", 900, -1, false, false, content[900:]},
+		{"clipped at end", 950, 200, false, false, content[950:]},
+		{"range ignored", 100, 200, false, true, content[100:300]},
+		{"range ignored to end", 990, -1, false, true, content[990:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authServer, _ := newAuthServer(3600)
+			defer authServer.Close()
+			var apiServer *httptest.Server
+			if tt.ignoreRange {
+				apiServer = newFileServer(t, map[int][]byte{3: content})
+			} else {
+				apiServer, _ = newRangeServer(t, content, tt.failOnce)
+			}
+			defer apiServer.Close()
+
+			client := newTestClient(t, apiServer.URL, authServer.URL)
+
+			var buf bytes.Buffer
+			n, err := client.DownloadFileRange(context.Background(), 1, 2, 3, tt.offset, tt.length, &buf)
+			if err != nil {
+				t.Fatalf("DownloadFileRange: %v", err)
+			}
+			if n != int64(len(tt.want)) || !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("got %d bytes %q, want %q", n, buf.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+// TestDownloadFileRangeBeyondEnd verifies an offset past the end of the file
+// fails without retrying.
+func TestDownloadFileRangeBeyondEnd(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer, requests := newRangeServer(t, testContent(100), false)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	_, err := client.DownloadFileRange(context.Background(), 1, 2, 3, 500, 10, io.Discard)
+	if err == nil {
+		t.Fatal("expected an error for an offset beyond the end of the file")
+	}
+	if c := atomic.LoadInt32(requests); c != 1 {
+		t.Errorf("expected 1 request, got %d", c)
+	}
+}
@@ -0,0 +1,67 @@
+package bdds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PingResult is the outcome of Client.Ping.
+type PingResult struct {
+	// Reachable reports whether the API answered.
+	Reachable bool
+	// Authenticated reports whether the client's credentials were accepted.
+	// It is false for a client without credentials, which can still read
+	// free products.
+	Authenticated bool
+	// StatusCode is the status of the API response, 0 if there was none.
+	StatusCode int
+	// Latency is the duration of the API request, without a token grant.
+	Latency time.Duration
+}
+
+// Ping checks that the API is reachable and the client's credentials are
+// accepted, e.g. for the readiness probe of a sync service. It obtains a
+// token if the client has credentials and no valid one, then sends a HEAD
+// request for the product list (a GET if the server rejects HEAD). It does
+// not retry and is not stopped by an open circuit breaker.
+//
+// The result describes the checks made; the error, if any, is the first
+// that failed.
+func (c *Client) Ping(ctx context.Context, opts ...CallOption) (*PingResult, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+	result := &PingResult{}
+	if a := c.account; a.username != "" && a.password != "" {
+		if _, err := c.ensureValidToken(ctx, a); err != nil {
+			return result, fmt.Errorf("authentication failed: %w", err)
+		}
+		result.Authenticated = true
+	}
+
+	start := time.Now()
+	resp, err := c.generatedClient.ListProducts(ctx, withMethod(http.MethodHead))
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		_ = resp.Body.Close()
+		resp, err = c.generatedClient.ListProducts(ctx)
+	}
+	result.Latency = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return result, err
+	}
+	result.Reachable = true
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		err := responseError(resp, body)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			result.Authenticated = false
+		}
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	var methods []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	result, err := client.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if !result.Reachable || !result.Authenticated || result.StatusCode != http.StatusOK || result.Latency <= 0 {
+		t.Errorf("Ping = %+v", result)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodGet {
+		t.Errorf("methods = %v, want HEAD then GET", methods)
+	}
+
+	apiServer.Close()
+	result, err = client.Ping(context.Background())
+	if err == nil || result.Reachable || !result.Authenticated {
+		t.Errorf("Ping of a closed server = %+v, %v", result, err)
+	}
+}
+
+func TestPingRejectedCredentials(t *testing.T) {
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("API called with rejected credentials")
+	}))
+	defer apiServer.Close()
+
+	result, err := newTestClient(t, apiServer.URL, authServer.URL).Ping(context.Background())
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || result.Authenticated || result.Reachable {
+		t.Errorf("Ping = %+v, %v", result, err)
+	}
+}
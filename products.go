@@ -0,0 +1,12 @@
+package bdds
+
+// IDs of well-known BDDS products, for the productID parameters of the
+// client's methods. They are checked against the live catalog by the
+// integration tests; SearchProducts finds the others.
+const (
+	ProductDocDBFrontFile      = 3  // EP DocDB front file: weekly bibliographic data
+	ProductEPFullTextFrontFile = 4  // EP full-text data - front file
+	ProductDocDBBackFile       = 14 // EP DocDB back file: bibliographic backlog
+	ProductPATSTATGlobal       = 17 // PATSTAT Global: patent statistics database
+	ProductPATSTATEPRegister   = 18 // PATSTAT EP Register: EP register data
+)
@@ -0,0 +1,301 @@
+package bdds
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Profile is a named client and mirror configuration read from a profile
+// file by LoadProfile, so that programs and CLIs built on the library share
+// one configuration format.
+type Profile struct {
+	Name      string
+	Config    *Config     // client configuration, with credentials resolved
+	MirrorDir string      // local mirror directory, e.g. for NewSync
+	Products  []int       // product IDs to work on
+	Filter    *FileFilter // file filter, e.g. for DownloadOptions.Filter; nil if unset
+}
+
+// Environment variables LoadProfile falls back to.
+const (
+	ProfilePathEnv = "EPO_BDDS_CONFIG"  // profile file path
+	ProfileNameEnv = "EPO_BDDS_PROFILE" // profile name
+)
+
+// DefaultProfilePath returns the profile file path: $EPO_BDDS_CONFIG, or
+// epo-bdds/config.toml in the user's configuration directory.
+func DefaultProfilePath() (string, error) {
+	if path := os.Getenv(ProfilePathEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "epo-bdds", "config.toml"), nil
+}
+
+// LoadProfile reads the named profile from a profile file (a TOML subset,
+// see the README). An empty path uses DefaultProfilePath; an empty name uses
+// $EPO_BDDS_PROFILE, the file's default_profile, or "default". Credentials
+// are given literally, as names of environment variables (username_env,
+// password_env), or as a HashiCorp Vault secret (vault_path), so the file
+// itself need not hold secrets.
+func LoadProfile(path, name string) (*Profile, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultProfilePath(); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+	profile, err := parseProfile(string(data), name)
+	if err != nil {
+		return nil, fmt.Errorf("profile file %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// parseProfile selects and decodes a profile from a profile file.
+func parseProfile(data, name string) (*Profile, error) {
+	doc, err := parseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	defaultName := "default"
+	for key, v := range doc {
+		switch key {
+		case "default_profile":
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("default_profile must be a string")
+			}
+			defaultName = s
+		case "profiles":
+		default:
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	if name == "" {
+		name = os.Getenv(ProfileNameEnv)
+	}
+	if name == "" {
+		name = defaultName
+	}
+
+	profiles, ok := doc["profiles"].(map[string]any)
+	if _, set := doc["profiles"]; set && !ok {
+		return nil, fmt.Errorf("profiles must be a table")
+	}
+	table, ok := profiles[name].(map[string]any)
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("no profile %q (have %v)", name, names)
+	}
+	profile, err := decodeProfile(table)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	profile.Name = name
+	return profile, nil
+}
+
+// decodeProfile builds a profile from its table, rejecting unknown keys.
+func decodeProfile(table map[string]any) (*Profile, error) {
+	p := &Profile{Config: &Config{}}
+	cfg := p.Config
+	filter := &FileFilter{}
+	var usernameEnv, passwordEnv string
+	var vault *VaultCredentials
+
+	// Decode in key order so the first of several errors is stable.
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v := table[key]
+		var err error
+		switch key {
+		case "username":
+			cfg.Username, err = profileString(v)
+		case "password":
+			cfg.Password, err = profileString(v)
+		case "username_env":
+			usernameEnv, err = profileString(v)
+		case "password_env":
+			passwordEnv, err = profileString(v)
+		case "vault_addr", "vault_mount", "vault_path", "vault_namespace":
+			if vault == nil {
+				vault = &VaultCredentials{}
+			}
+			var s string
+			s, err = profileString(v)
+			switch key {
+			case "vault_addr":
+				vault.Addr = s
+			case "vault_mount":
+				vault.Mount = s
+			case "vault_path":
+				vault.Path = s
+			case "vault_namespace":
+				vault.Namespace = s
+			}
+		case "environment":
+			cfg.Environment, err = profileString(v)
+		case "base_url":
+			cfg.BaseURL, err = profileString(v)
+		case "token_url":
+			cfg.TokenURL, err = profileString(v)
+		case "user_agent":
+			cfg.UserAgent, err = profileString(v)
+		case "proxy_url":
+			cfg.ProxyURL, err = profileString(v)
+		case "max_retries":
+			cfg.MaxRetries, err = profileInt(v)
+		case "max_concurrent_requests":
+			cfg.MaxConcurrentRequests, err = profileInt(v)
+		case "retry_delay":
+			cfg.RetryDelay, err = profileDuration(v)
+		case "timeout":
+			cfg.Timeout, err = profileDuration(v)
+		case "download_header_timeout":
+			cfg.DownloadHeaderTimeout, err = profileDuration(v)
+		case "download_idle_timeout":
+			cfg.DownloadIdleTimeout, err = profileDuration(v)
+		case "background_token_refresh":
+			cfg.BackgroundTokenRefresh, err = profileBool(v)
+		case "mirror_dir":
+			p.MirrorDir, err = profileString(v)
+		case "products":
+			p.Products, err = profileInts(v)
+		case "delivery_name":
+			filter.DeliveryName, err = profileString(v)
+		case "file_name":
+			filter.FileName, err = profileString(v)
+		case "file_pattern":
+			var s string
+			if s, err = profileString(v); err == nil {
+				filter.FilePattern, err = regexp.Compile(s)
+			}
+		case "max_size":
+			var n int
+			n, err = profileInt(v)
+			filter.MaxSize = int64(n)
+		case "published_from":
+			filter.PublishedFrom, err = profileDate(v)
+		case "published_before":
+			filter.PublishedBefore, err = profileDate(v)
+		default:
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if usernameEnv != "" {
+		if cfg.Username = os.Getenv(usernameEnv); cfg.Username == "" {
+			return nil, fmt.Errorf("username_env: $%s is not set", usernameEnv)
+		}
+	}
+	if passwordEnv != "" {
+		if cfg.Password = os.Getenv(passwordEnv); cfg.Password == "" {
+			return nil, fmt.Errorf("password_env: $%s is not set", passwordEnv)
+		}
+	}
+	if vault != nil {
+		if cfg.Username != "" || cfg.Password != "" {
+			return nil, fmt.Errorf("vault credentials cannot be combined with a username or password")
+		}
+		cfg.Credentials = vault
+	}
+	if *filter != (FileFilter{}) {
+		if err := filter.validate(); err != nil {
+			return nil, err
+		}
+		p.Filter = filter
+	}
+	return p, nil
+}
+
+func profileString(v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("must be a string")
+	}
+	return s, nil
+}
+
+func profileInt(v any) (int, error) {
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("must be a non-negative integer")
+	}
+	return int(n), nil
+}
+
+func profileBool(v any) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("must be true or false")
+	}
+	return b, nil
+}
+
+func profileInts(v any) ([]int, error) {
+	values, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("must be an array of integers")
+	}
+	ints := make([]int, len(values))
+	for i, value := range values {
+		n, err := profileInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be an array of integers")
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// profileDuration reads a duration string such as "30s" or "2m".
+func profileDuration(v any) (time.Duration, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf(`must be a duration string such as "30s"`)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// profileDate reads a date ("2024-01-31") or RFC 3339 timestamp string.
+func profileDate(v any) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf(`must be a date string such as "2024-01-31"`)
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,120 @@
+package bdds
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testProfiles = `
+default_profile = "prod"
+
+[profiles.prod]
+username_env = "TEST_BDDS_USER"
+password_env = "TEST_BDDS_PASS"
+max_retries = 5
+retry_delay = "2s"
+timeout = "1m"
+background_token_refresh = true
+mirror_dir = "/data/bdds"
+products = [3, 4]
+file_name = "*.zip"
+published_from = "2024-01-01"
+
+[profiles.test]
+base_url = "https://bdds-test.example.com"
+vault_addr = "https://vault.example.com"
+vault_path = "epo-bdds/test"
+`
+
+func writeProfiles(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadProfile(t *testing.T) {
+	t.Setenv("TEST_BDDS_USER", "alice")
+	t.Setenv("TEST_BDDS_PASS", "secret")
+	t.Setenv(ProfileNameEnv, "")
+	path := writeProfiles(t, testProfiles)
+
+	p, err := LoadProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if p.Name != "prod" {
+		t.Errorf("Name = %q, want the default profile", p.Name)
+	}
+	cfg := p.Config
+	if cfg.Username != "alice" || cfg.Password != "secret" {
+		t.Errorf("credentials = %q/%q, want them from the environment", cfg.Username, cfg.Password)
+	}
+	if cfg.MaxRetries != 5 || cfg.RetryDelay != 2*time.Second || cfg.Timeout != time.Minute || !cfg.BackgroundTokenRefresh {
+		t.Errorf("Config = %+v", cfg)
+	}
+	if p.MirrorDir != "/data/bdds" || !reflect.DeepEqual(p.Products, []int{3, 4}) {
+		t.Errorf("MirrorDir = %q, Products = %v", p.MirrorDir, p.Products)
+	}
+	if p.Filter == nil || p.Filter.FileName != "*.zip" || !p.Filter.PublishedFrom.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Filter = %+v", p.Filter)
+	}
+
+	test, err := LoadProfile(path, "test")
+	if err != nil {
+		t.Fatalf("LoadProfile test: %v", err)
+	}
+	vault, ok := test.Config.Credentials.(*VaultCredentials)
+	if !ok || vault.Path != "epo-bdds/test" || test.Config.BaseURL != "https://bdds-test.example.com" {
+		t.Errorf("test profile = %+v", test.Config)
+	}
+	if test.Filter != nil {
+		t.Errorf("Filter = %+v, want nil", test.Filter)
+	}
+
+	t.Setenv(ProfileNameEnv, "test")
+	if p, err := LoadProfile(path, ""); err != nil || p.Name != "test" {
+		t.Errorf("profile from $%s = %v, %v", ProfileNameEnv, p, err)
+	}
+}
+
+func TestLoadProfileDefaultPath(t *testing.T) {
+	t.Setenv(ProfilePathEnv, writeProfiles(t, "[profiles.default]\nusername = \"u\"\npassword = \"p\"\n"))
+	t.Setenv(ProfileNameEnv, "")
+	p, err := LoadProfile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "default" || p.Config.Username != "u" {
+		t.Errorf("profile = %+v", p)
+	}
+	if _, err := NewClient(p.Config); err != nil {
+		t.Errorf("NewClient from profile: %v", err)
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	t.Setenv("TEST_BDDS_UNSET", "")
+	tests := map[string]string{
+		"[profiles.prod]\nx = 1":                                   `no profile "missing"`,
+		"[profiles.missing]\nmax_retry = 1":                        `unknown key "max_retry"`,
+		"[profiles.missing]\ntimeout = 30":                         "timeout: must be a duration",
+		"[profiles.missing]\nproducts = [\"3\"]":                   "products: must be an array of integers",
+		"[profiles.missing]\nfile_name = \"[\"":                    "invalid filter pattern",
+		"[profiles.missing]\nusername_env = \"TEST_BDDS_UNSET\"":   "$TEST_BDDS_UNSET is not set",
+		"[profiles.missing]\nusername = \"u\"\nvault_path = \"x\"": "cannot be combined",
+		"extra = 1": `unknown key "extra"`,
+	}
+	for data, want := range tests {
+		_, err := LoadProfile(writeProfiles(t, data), "missing")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadProfile(%q) = %v, want an error containing %q", data, err, want)
+		}
+	}
+}
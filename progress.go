@@ -0,0 +1,85 @@
+package bdds
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultProgressInterval is how often ProgressFunc reports when no interval
+// is given.
+const defaultProgressInterval = 500 * time.Millisecond
+
+// Progress is a snapshot of a transfer, as reported by ProgressFunc.
+type Progress struct {
+	BytesTransferred int64
+	TotalBytes       int64         // 0 or negative when unknown
+	Speed            float64       // bytes per second since the previous report
+	AverageSpeed     float64       // bytes per second since the first report
+	Elapsed          time.Duration // time since the first report
+	ETA              time.Duration // estimated time remaining, 0 when unknown
+}
+
+// Percent returns the completed percentage, or 0 when the total is unknown.
+func (p Progress) Percent() float64 {
+	if p.TotalBytes <= 0 {
+		return 0
+	}
+	return float64(p.BytesTransferred) * 100 / float64(p.TotalBytes)
+}
+
+// Done reports whether the transfer has reached its known total.
+func (p Progress) Done() bool {
+	return p.TotalBytes > 0 && p.BytesTransferred >= p.TotalBytes
+}
+
+// ProgressFunc adapts fn to the func(bytesWritten, totalBytes int64) progress
+// callbacks taken by the download methods. It computes speed and ETA and
+// throttles fn to at most one call per interval (default 500ms when interval
+// is not positive), always reporting completion. The returned callback is
+// safe for concurrent use; create one per transfer.
+func ProgressFunc(fn func(Progress), interval time.Duration) func(bytesWritten, totalBytes int64) {
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	var (
+		mu        sync.Mutex
+		started   bool
+		start     time.Time
+		baseBytes int64
+		lastTime  time.Time
+		lastBytes int64
+	)
+	return func(bytesWritten, totalBytes int64) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		if !started {
+			// Speeds count from the first report, so a resumed transfer
+			// does not credit bytes downloaded earlier.
+			started, start, baseBytes = true, now, bytesWritten
+			lastTime, lastBytes = now, bytesWritten
+		}
+		done := totalBytes > 0 && bytesWritten >= totalBytes
+		if now.Sub(lastTime) < interval && !done {
+			return
+		}
+
+		p := Progress{
+			BytesTransferred: bytesWritten,
+			TotalBytes:       totalBytes,
+			Elapsed:          now.Sub(start),
+		}
+		if d := now.Sub(lastTime).Seconds(); d > 0 {
+			p.Speed = float64(bytesWritten-lastBytes) / d
+		}
+		if s := p.Elapsed.Seconds(); s > 0 {
+			p.AverageSpeed = float64(bytesWritten-baseBytes) / s
+		}
+		if totalBytes > 0 && p.AverageSpeed > 0 && !done {
+			p.ETA = time.Duration(float64(totalBytes-bytesWritten) / p.AverageSpeed * float64(time.Second))
+		}
+		lastTime, lastBytes = now, bytesWritten
+		fn(p)
+	}
+}
@@ -0,0 +1,52 @@
+package bdds
+
+import (
+	"testing"
+	"time"
+)
+
+// TestProgressFuncThrottles verifies reports are throttled to the interval
+// while completion is always reported.
+func TestProgressFuncThrottles(t *testing.T) {
+	var reports []Progress
+	cb := ProgressFunc(func(p Progress) { reports = append(reports, p) }, time.Hour)
+
+	for n := int64(100); n < 1000; n += 100 {
+		cb(n, 1000)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("expected no reports within the interval, got %d", len(reports))
+	}
+	cb(1000, 1000)
+	if len(reports) != 1 || !reports[0].Done() || reports[0].Percent() != 100 {
+		t.Fatalf("expected a single completion report, got %+v", reports)
+	}
+	if reports[0].ETA != 0 {
+		t.Errorf("completed transfer should have no ETA, got %s", reports[0].ETA)
+	}
+}
+
+// TestProgressFuncSpeedAndETA verifies speed and ETA are derived from the
+// bytes transferred since the first report.
+func TestProgressFuncSpeedAndETA(t *testing.T) {
+	var last Progress
+	cb := ProgressFunc(func(p Progress) { last = p }, time.Millisecond)
+
+	cb(5000, 10000) // resumed transfer: earlier bytes are not counted
+	time.Sleep(20 * time.Millisecond)
+	cb(6000, 10000)
+
+	if last.BytesTransferred != 6000 || last.Elapsed <= 0 {
+		t.Fatalf("unexpected report %+v", last)
+	}
+	if last.AverageSpeed <= 0 || last.AverageSpeed > 1000/0.02 {
+		t.Errorf("average speed %.0f B/s does not reflect 1000 bytes in >=20ms", last.AverageSpeed)
+	}
+	want := time.Duration(4000 / last.AverageSpeed * float64(time.Second))
+	if diff := last.ETA - want; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("ETA = %s, want about %s", last.ETA, want)
+	}
+	if last.Percent() != 60 {
+		t.Errorf("Percent = %.1f, want 60", last.Percent())
+	}
+}
@@ -0,0 +1,113 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PruneOptions configures Prune.
+type PruneOptions struct {
+	// MaxAge, if set, also prunes deliveries published longer ago than
+	// this, as a local retention policy.
+	MaxAge time.Duration
+	// DryRun reports what would be removed without removing it.
+	DryRun bool
+}
+
+// PrunedDelivery is a local delivery directory removed by Prune.
+type PrunedDelivery struct {
+	DeliveryID   int
+	DeliveryName string
+	Path         string
+	Reason       string // "expired" or "retention"
+	Files        int    // files removed
+	Bytes        int64  // bytes freed
+}
+
+// PruneReport lists what Prune removed, or would remove in a dry run.
+type PruneReport struct {
+	Removed []PrunedDelivery
+}
+
+// Bytes returns the total number of bytes freed.
+func (r *PruneReport) Bytes() int64 {
+	var n int64
+	for _, d := range r.Removed {
+		n += d.Bytes
+	}
+	return n
+}
+
+// Prune removes the local copies of a product's deliveries that are past
+// their DeliveryExpiryDatetime, or older than opts.MaxAge, from a mirror
+// laid out as by DownloadProduct (dir/<delivery name>/...; for a Sync mirror,
+// dir is <sync dir>/<product ID>) with the default layout. Deliveries without
+// a local directory are skipped, as are local directories that do not belong
+// to a catalog delivery.
+//
+// The report lists what was removed, also when an error stopped pruning
+// part-way.
+func (c *Client) Prune(ctx context.Context, productID int, dir string, opts *PruneOptions) (*PruneReport, error) {
+	product, err := c.GetProduct(ctx, productID, WithNoCache())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &PruneReport{}
+	for _, d := range product.Deliveries {
+		reason := ""
+		switch {
+		case d.DeliveryExpiryDatetime != nil && d.DeliveryExpiryDatetime.Before(now):
+			reason = "expired"
+		case opts != nil && opts.MaxAge > 0 && now.Sub(d.DeliveryPublicationDatetime) > opts.MaxAge:
+			reason = "retention"
+		default:
+			continue
+		}
+
+		path := filepath.Join(dir, deliveryDirName(d))
+		pruned := PrunedDelivery{DeliveryID: d.DeliveryID, DeliveryName: d.DeliveryName, Path: path, Reason: reason}
+		pruned.Files, pruned.Bytes, err = removeTree(path, opts != nil && opts.DryRun)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Removed = append(report.Removed, pruned)
+	}
+	return report, nil
+}
+
+// treeSize returns the number and total size of the regular files below
+// path; the error wraps os.ErrNotExist if path does not exist.
+func treeSize(path string) (files int, bytes int64, err error) {
+	err = filepath.WalkDir(path, func(_ string, de fs.DirEntry, err error) error {
+		if err != nil || !de.Type().IsRegular() {
+			return err
+		}
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		bytes += info.Size()
+		return nil
+	})
+	return files, bytes, err
+}
+
+// removeTree removes path and everything below it, unless dryRun is set,
+// and returns what it held as treeSize does.
+func removeTree(path string, dryRun bool) (files int, bytes int64, err error) {
+	files, bytes, err = treeSize(path)
+	if err != nil || dryRun {
+		return files, bytes, err
+	}
+	return files, bytes, os.RemoveAll(path)
+}
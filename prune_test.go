@@ -0,0 +1,82 @@
+package bdds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPrune verifies expired deliveries and deliveries past the retention
+// period are removed with a report, while current ones are kept.
+func TestPrune(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := now.Add(-time.Hour)
+	future := now.AddDate(0, 1, 0)
+	deliveries := []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", now.AddDate(0, 0, -10), 2),
+		weeklyDelivery(8, "2024-06-01", now.AddDate(-1, 0, 0), 1),
+		weeklyDelivery(9, "2024-10-22", now.AddDate(0, 0, -3), 1),
+		weeklyDelivery(10, "2024-10-01", now.AddDate(0, 0, -20), 1),
+	}
+	deliveries[0].expiry = &expired
+	deliveries[1].expiry = &future
+	deliveries[3].expiry = &expired
+	apiServer, _ := newMirrorServer(t, 3, deliveries)
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	for _, d := range deliveries[:3] { // delivery 10 has no local copy
+		for _, f := range d.files {
+			path := filepath.Join(dir, d.name, f.name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(f.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	opts := &PruneOptions{MaxAge: 90 * 24 * time.Hour, DryRun: true}
+	planned, err := client.Prune(context.Background(), 3, dir, opts)
+	if err != nil {
+		t.Fatalf("Prune (dry run): %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024-10-15")); err != nil || len(planned.Removed) != 2 {
+		t.Fatalf("dry run reported %+v and removed files (%v)", planned.Removed, err)
+	}
+
+	opts.DryRun = false
+	report, err := client.Prune(context.Background(), 3, dir, opts)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(report.Removed) != 2 {
+		t.Fatalf("expected 2 pruned deliveries, got %+v", report.Removed)
+	}
+	first, second := report.Removed[0], report.Removed[1]
+	if first.DeliveryID != 7 || first.Reason != "expired" || first.Files != 2 ||
+		first.Bytes != int64(len("delivery 7 file 1")+len("delivery 7 file 2")) {
+		t.Errorf("unexpected first entry: %+v", first)
+	}
+	if second.DeliveryID != 8 || second.Reason != "retention" || second.Files != 1 {
+		t.Errorf("unexpected second entry: %+v", second)
+	}
+	if report.Bytes() != first.Bytes+second.Bytes {
+		t.Errorf("Bytes() = %d", report.Bytes())
+	}
+	for _, name := range []string{"2024-10-15", "2024-06-01"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2024-10-22", "20241022_01.zip")); err != nil {
+		t.Errorf("current delivery was touched: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Publisher sends a message to a topic of a message broker such as Kafka or
+// NATS. The package ships no broker client; a Publisher is a few lines
+// around the client of your choice (see EventPublisher).
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+}
+
+// PublisherFunc adapts a function to a Publisher.
+type PublisherFunc func(ctx context.Context, topic string, key, value []byte) error
+
+// Publish calls f.
+func (f PublisherFunc) Publish(ctx context.Context, topic string, key, value []byte) error {
+	return f(ctx, topic, key, value)
+}
+
+// DefaultEventTopic is the topic of an EventPublisher without Topic.
+const DefaultEventTopic = "bdds.events"
+
+// EventPublisher publishes new-delivery and download-complete events to a
+// broker topic, carrying the same JSON payload as a Webhook. Messages are
+// keyed by "<product ID>/<delivery ID>", so a partitioned topic keeps the
+// events of a delivery in order.
+//
+// A Kafka publisher using github.com/segmentio/kafka-go:
+//
+//	w := &kafka.Writer{Addr: kafka.TCP("localhost:9092")}
+//	pub := &bdds.EventPublisher{Publisher: bdds.PublisherFunc(
+//		func(ctx context.Context, topic string, key, value []byte) error {
+//			return w.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: value})
+//		})}
+//
+// A NATS publisher using github.com/nats-io/nats.go:
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	pub := &bdds.EventPublisher{Publisher: bdds.PublisherFunc(
+//		func(_ context.Context, subject string, _, value []byte) error {
+//			return nc.Publish(subject, value)
+//		})}
+type EventPublisher struct {
+	Publisher Publisher
+	// Topic receives all events (default: DefaultEventTopic); the event
+	// type is part of the payload.
+	Topic string
+	// Payload, if set, builds the JSON message from an event instead of
+	// sending the event itself.
+	Payload func(*WebhookEvent) any
+}
+
+// PublishNewDelivery publishes a WebhookNewDelivery event for a delivery.
+func (p *EventPublisher) PublishNewDelivery(ctx context.Context, productID int, d *Delivery) error {
+	return p.Publish(ctx, newDeliveryEvent(productID, d))
+}
+
+// PublishFileDownloaded publishes a WebhookFileDownloaded event for a bulk
+// download result, successful or not.
+func (p *EventPublisher) PublishFileDownloaded(ctx context.Context, r *FileResult) error {
+	return p.Publish(ctx, fileDownloadedEvent(r))
+}
+
+// Publish publishes an event.
+func (p *EventPublisher) Publish(ctx context.Context, event *WebhookEvent) error {
+	var payload any = event
+	if p.Payload != nil {
+		payload = p.Payload(event)
+	}
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	topic := p.Topic
+	if topic == "" {
+		topic = DefaultEventTopic
+	}
+	key := strconv.Itoa(event.ProductID) + "/" + strconv.Itoa(event.DeliveryID)
+	if err := p.Publisher.Publish(ctx, topic, []byte(key), value); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// SyncSubscriber returns a Sync subscriber (see Sync.Subscribe) that
+// publishes every delivery a run discovers and every file it downloads or
+// fails to download. onError, if set, receives the publishing errors.
+func (p *EventPublisher) SyncSubscriber(onError func(error)) func(SyncEvent) {
+	return func(e SyncEvent) {
+		var event *WebhookEvent
+		switch e.Type {
+		case DeliveryDiscovered:
+			event = newDeliveryEvent(e.ProductID, e.Delivery)
+		case FileCompleted, FileFailed:
+			if e.File.Skipped || e.File.Planned {
+				return
+			}
+			event = fileDownloadedEvent(e.File)
+		default:
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := p.Publish(ctx, event); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
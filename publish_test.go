@@ -0,0 +1,67 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type publishedMessage struct {
+	topic, key string
+	event      WebhookEvent
+}
+
+func TestEventPublisher(t *testing.T) {
+	var messages []publishedMessage
+	pub := &EventPublisher{Publisher: PublisherFunc(func(_ context.Context, topic string, key, value []byte) error {
+		m := publishedMessage{topic: topic, key: string(key)}
+		if err := json.Unmarshal(value, &m.event); err != nil {
+			t.Errorf("payload: %v", err)
+		}
+		messages = append(messages, m)
+		return nil
+	})}
+	d := &Delivery{DeliveryID: 7, DeliveryName: "2024-10-15"}
+	subscriber := pub.SyncSubscriber(func(err error) { t.Errorf("publish: %v", err) })
+	subscriber(SyncEvent{Type: DeliveryDiscovered, ProductID: 3, Delivery: d})
+	file := &FileResult{ProductID: 3, DeliveryID: 7, File: &DeliveryFile{FileID: 701, FileName: "a.zip"}, Path: "m/a.zip", Bytes: 10}
+	subscriber(SyncEvent{Type: FileStarted, ProductID: 3, File: file})
+	subscriber(SyncEvent{Type: FileCompleted, ProductID: 3, File: file})
+	subscriber(SyncEvent{Type: FileCompleted, ProductID: 3, File: &FileResult{File: &DeliveryFile{}, Skipped: true}})
+	failed := &FileResult{ProductID: 3, DeliveryID: 7, File: &DeliveryFile{FileID: 702, FileName: "b.zip"}, Err: errors.New("boom")}
+	subscriber(SyncEvent{Type: FileFailed, ProductID: 3, File: failed, Err: failed.Err})
+	subscriber(SyncEvent{Type: RunCompleted})
+
+	if len(messages) != 3 {
+		t.Fatalf("published %d messages, want 3", len(messages))
+	}
+	for _, m := range messages {
+		if m.topic != DefaultEventTopic || m.key != "3/7" {
+			t.Errorf("message on %s with key %s", m.topic, m.key)
+		}
+	}
+	if e := messages[0].event; e.Type != WebhookNewDelivery || e.DeliveryName != "2024-10-15" {
+		t.Errorf("new delivery event = %+v", e)
+	}
+	if e := messages[1].event; e.Type != WebhookFileDownloaded || e.FileName != "a.zip" || e.Bytes != 10 || e.Error != "" {
+		t.Errorf("downloaded event = %+v", e)
+	}
+	if e := messages[2].event; e.FileID != 702 || e.Error != "boom" {
+		t.Errorf("failed event = %+v", e)
+	}
+}
+
+func TestEventPublisherError(t *testing.T) {
+	pub := &EventPublisher{
+		Topic: "patents",
+		Publisher: PublisherFunc(func(_ context.Context, topic string, _, _ []byte) error {
+			return errors.New("broker down on " + topic)
+		}),
+	}
+	err := pub.PublishNewDelivery(context.Background(), 3, &Delivery{DeliveryID: 7})
+	if err == nil || !strings.Contains(err.Error(), "broker down on patents") {
+		t.Errorf("expected the publisher error, got %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package bdds
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultQueueAttempts is how often a queued job is attempted before it is
+// moved to the failed list, when QueueOptions.MaxAttempts is unset.
+const defaultQueueAttempts = 3
+
+// QueueJob is one file download in a DownloadQueue.
+type QueueJob struct {
+	ProductID  int       `json:"productId"`
+	DeliveryID int       `json:"deliveryId"`
+	FileID     int       `json:"fileId"`
+	Path       string    `json:"path"`                // local destination path
+	Priority   int       `json:"priority"`            // higher runs first
+	Attempts   int       `json:"attempts"`            // completed attempts so far
+	LastError  string    `json:"lastError,omitempty"` // error of the last failed attempt
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+
+	seq uint64 // FIFO order within a priority
+}
+
+func (j *QueueJob) key() string {
+	return fmt.Sprintf("%d/%d/%d", j.ProductID, j.DeliveryID, j.FileID)
+}
+
+// QueueOptions configures a DownloadQueue.
+type QueueOptions struct {
+	// MaxAttempts is how many times a job is tried before it is moved to the
+	// failed list (default: 3). Each attempt already includes the client's
+	// own request retries.
+	MaxAttempts int
+}
+
+// DownloadQueue is a persistent, prioritized queue of file downloads. Every
+// change is written to a JSON state file with an atomic rename, and jobs are
+// only removed once their download has completed, so a crash or restart
+// resumes with every unfinished job (including ones that were in flight).
+type DownloadQueue struct {
+	client      *Client
+	statePath   string
+	maxAttempts int
+
+	mu       sync.Mutex
+	pending  jobHeap
+	inFlight map[string]*QueueJob
+	failed   []*QueueJob
+	nextSeq  uint64
+}
+
+// queueState is the persisted form of a DownloadQueue.
+type queueState struct {
+	Pending []*QueueJob `json:"pending"`
+	Failed  []*QueueJob `json:"failed"`
+}
+
+// NewDownloadQueue opens the queue persisted at statePath, creating an empty
+// one if the file does not exist. A nil opts uses the defaults.
+func NewDownloadQueue(client *Client, statePath string, opts *QueueOptions) (*DownloadQueue, error) {
+	q := &DownloadQueue{
+		client:      client,
+		statePath:   statePath,
+		maxAttempts: defaultQueueAttempts,
+		inFlight:    make(map[string]*QueueJob),
+	}
+	if opts != nil && opts.MaxAttempts > 0 {
+		q.maxAttempts = opts.MaxAttempts
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue state: %w", err)
+	}
+	var state queueState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse queue state %s: %w", statePath, err)
+	}
+	for _, job := range state.Pending {
+		q.push(job)
+	}
+	q.failed = state.Failed
+	return q, nil
+}
+
+// Enqueue adds a job and persists the queue. A job for a file that is
+// already pending or in flight is ignored.
+func (q *DownloadQueue) Enqueue(job QueueJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, busy := q.inFlight[job.key()]; busy {
+		return nil
+	}
+	for _, pending := range q.pending {
+		if pending.key() == job.key() {
+			return nil
+		}
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = time.Now()
+	}
+	q.push(&job)
+	return q.saveLocked()
+}
+
+// Len returns the number of jobs not yet completed (pending or in flight).
+func (q *DownloadQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) + len(q.inFlight)
+}
+
+// Failed returns copies of the jobs that exhausted their attempts.
+func (q *DownloadQueue) Failed() []QueueJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]QueueJob, len(q.failed))
+	for i, job := range q.failed {
+		out[i] = *job
+	}
+	return out
+}
+
+// RetryFailed moves every failed job back to the pending queue with a fresh
+// attempt budget.
+func (q *DownloadQueue) RetryFailed() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.failed {
+		job.Attempts = 0
+		q.push(job)
+	}
+	q.failed = nil
+	return q.saveLocked()
+}
+
+// Run drains the queue with the given number of workers (at least one) and
+// returns once no jobs remain or ctx is cancelled. Jobs that fail are retried
+// up to MaxAttempts times and then moved to the failed list. The returned
+// error reports cancellation or a failure to persist the queue, not
+// individual job failures (see Failed).
+func (q *DownloadQueue) Run(ctx context.Context, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				job := q.next()
+				if job == nil {
+					return
+				}
+				_, err := q.client.downloadToPath(ctx, job.ProductID, job.DeliveryID, job.FileID, job.Path, "", nil)
+				if err := q.finish(ctx, job, err); err != nil {
+					setErr(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// next pops the highest-priority job and marks it in flight.
+func (q *DownloadQueue) next() *QueueJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return nil
+	}
+	job := heap.Pop(&q.pending).(*QueueJob)
+	q.inFlight[job.key()] = job
+	return job
+}
+
+// finish records the outcome of a job attempt and persists the queue.
+func (q *DownloadQueue) finish(ctx context.Context, job *QueueJob, err error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.inFlight, job.key())
+
+	switch {
+	case err == nil:
+		// Completed: dropped from the queue.
+	case ctx.Err() != nil:
+		// Interrupted by cancellation: not the job's fault, keep its budget.
+		q.push(job)
+	default:
+		job.Attempts++
+		job.LastError = err.Error()
+		if job.Attempts >= q.maxAttempts {
+			q.failed = append(q.failed, job)
+		} else {
+			q.push(job)
+		}
+	}
+	return q.saveLocked()
+}
+
+// push adds a job to the pending heap behind all jobs of equal priority.
+func (q *DownloadQueue) push(job *QueueJob) {
+	q.nextSeq++
+	job.seq = q.nextSeq
+	heap.Push(&q.pending, job)
+}
+
+// saveLocked persists pending (including in-flight) and failed jobs. The
+// caller must hold mu.
+func (q *DownloadQueue) saveLocked() error {
+	state := queueState{Pending: make([]*QueueJob, 0, len(q.pending)+len(q.inFlight)), Failed: q.failed}
+	for _, job := range q.inFlight {
+		state.Pending = append(state.Pending, job)
+	}
+	state.Pending = append(state.Pending, q.pending...)
+	// Persist in run order so a reload keeps the FIFO order within priorities.
+	sort.Slice(state.Pending, func(i, j int) bool { return jobHeap(state.Pending).Less(i, j) })
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(q.statePath, data); err != nil {
+		return fmt.Errorf("failed to persist download queue: %w", err)
+	}
+	return nil
+}
+
+// jobHeap orders jobs by descending priority, then enqueue order.
+type jobHeap []*QueueJob
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x any)   { *h = append(*h, x.(*QueueJob)) }
+func (h *jobHeap) Pop() any {
+	old := *h
+	job := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return job
+}
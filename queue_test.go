@@ -0,0 +1,131 @@
+package bdds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDownloadQueuePriorityAndPersistence verifies jobs run by priority, are
+// persisted across reopen, and are removed once completed.
+func TestDownloadQueuePriorityAndPersistence(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{1: []byte("one"), 2: []byte("two"), 3: []byte("three")})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "queue.json")
+
+	q, err := NewDownloadQueue(client, statePath, nil)
+	if err != nil {
+		t.Fatalf("NewDownloadQueue: %v", err)
+	}
+	for _, job := range []QueueJob{
+		{ProductID: 1, DeliveryID: 1, FileID: 1, Path: filepath.Join(dir, "one"), Priority: 1},
+		{ProductID: 1, DeliveryID: 1, FileID: 2, Path: filepath.Join(dir, "two"), Priority: 5},
+		{ProductID: 1, DeliveryID: 1, FileID: 3, Path: filepath.Join(dir, "three"), Priority: 1},
+		{ProductID: 1, DeliveryID: 1, FileID: 3, Path: filepath.Join(dir, "dup"), Priority: 9},
+	} {
+		if err := q.Enqueue(job); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	// Reopen from disk: the queue survives a restart.
+	q, err = NewDownloadQueue(client, statePath, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if q.Len() != 3 {
+		t.Fatalf("expected 3 pending jobs after reopen, got %d", q.Len())
+	}
+	var order []int
+	for job := q.next(); job != nil; job = q.next() {
+		order = append(order, job.FileID)
+	}
+	if len(order) != 3 || order[0] != 2 || order[1] != 1 || order[2] != 3 {
+		t.Errorf("run order = %v, want [2 1 3]", order)
+	}
+
+	// In-flight jobs are still persisted as pending (crash safety).
+	q, err = NewDownloadQueue(client, statePath, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if err := q.Run(context.Background(), 2); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected empty queue after Run, got %d", q.Len())
+	}
+	for name, want := range map[string]string{"one": "one", "two": "two", "three": "three"} {
+		got, _ := os.ReadFile(filepath.Join(dir, name))
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	q, _ = NewDownloadQueue(client, statePath, nil)
+	if q.Len() != 0 {
+		t.Errorf("expected persisted queue to be empty, got %d", q.Len())
+	}
+}
+
+// TestDownloadQueueFailedJobs verifies a job that keeps failing is moved to
+// the failed list after MaxAttempts and can be retried.
+func TestDownloadQueueFailedJobs(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	statePath := filepath.Join(t.TempDir(), "queue.json")
+
+	q, err := NewDownloadQueue(client, statePath, &QueueOptions{MaxAttempts: 2})
+	if err != nil {
+		t.Fatalf("NewDownloadQueue: %v", err)
+	}
+	if err := q.Enqueue(QueueJob{ProductID: 1, DeliveryID: 1, FileID: 404, Path: filepath.Join(t.TempDir(), "x")}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := q.Run(context.Background(), 1); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	failed := q.Failed()
+	if len(failed) != 1 || failed[0].Attempts != 2 || failed[0].LastError == "" {
+		t.Fatalf("expected one failed job after 2 attempts, got %+v", failed)
+	}
+
+	if err := q.RetryFailed(); err != nil {
+		t.Fatalf("RetryFailed: %v", err)
+	}
+	if q.Len() != 1 || len(q.Failed()) != 0 {
+		t.Errorf("expected job back in the queue, len=%d failed=%d", q.Len(), len(q.Failed()))
+	}
+}
+
+// TestDownloadQueueCancelKeepsJobs verifies cancellation leaves jobs queued
+// without consuming their attempts.
+func TestDownloadQueueCancelKeepsJobs(t *testing.T) {
+	client, err := NewClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewDownloadQueue(client, filepath.Join(t.TempDir(), "queue.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = q.Enqueue(QueueJob{ProductID: 1, DeliveryID: 1, FileID: 1, Path: filepath.Join(t.TempDir(), "x")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.Run(ctx, 1); err == nil {
+		t.Fatal("expected cancellation error")
+	}
+	if q.Len() != 1 {
+		t.Errorf("expected job to remain queued, len=%d", q.Len())
+	}
+}
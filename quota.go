@@ -0,0 +1,111 @@
+package bdds
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// QuotaPolicy says what a Sync does when a run would exceed its Quota.
+type QuotaPolicy int
+
+const (
+	// QuotaRefuse fails the run with a QuotaExceededError before any
+	// download starts.
+	QuotaRefuse QuotaPolicy = iota
+	// QuotaEvictExpired first removes the local copies of expired
+	// deliveries of the products being synced, oldest first, until the run
+	// fits, and refuses the run if it still does not.
+	QuotaEvictExpired
+)
+
+// Quota caps the size of a Sync mirror.
+type Quota struct {
+	MaxBytes int64 // total size of the files below the mirror directory
+	Policy   QuotaPolicy
+}
+
+// SetQuota makes the following runs check, before downloading, that the
+// mirror directory stays within q once the pending files are in, estimated
+// from their catalog sizes. Eviction assumes the default layout, as Prune
+// does, and leaves the store records of evicted files in place; in a dry
+// run, evictions are reported (DeliveryEvicted events) but not carried out.
+// A nil q removes the quota.
+func (s *Sync) SetQuota(q *Quota) {
+	s.quota = q
+}
+
+// enforceQuota checks the pending jobs of a run against the quota, evicting
+// expired deliveries of products if the policy allows.
+func (s *Sync) enforceQuota(products []*ProductWithDeliveries, jobs []*FileResult, dryRun bool) error {
+	_, used, err := treeSize(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		used, err = 0, nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to measure mirror: %w", err)
+	}
+	required := quotaRequired(jobs)
+	if used+required <= s.quota.MaxBytes {
+		return nil
+	}
+
+	if s.quota.Policy == QuotaEvictExpired {
+		type candidate struct {
+			productID int
+			delivery  *Delivery
+		}
+		var candidates []candidate
+		now := time.Now()
+		for _, p := range products {
+			for _, d := range p.Deliveries {
+				if d.DeliveryExpiryDatetime != nil && d.DeliveryExpiryDatetime.Before(now) {
+					candidates = append(candidates, candidate{p.ID, d})
+				}
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].delivery.DeliveryPublicationDatetime.Before(candidates[j].delivery.DeliveryPublicationDatetime)
+		})
+		for _, c := range candidates {
+			if used+required <= s.quota.MaxBytes {
+				return nil
+			}
+			path := filepath.Join(s.dir, strconv.Itoa(c.productID), deliveryDirName(c.delivery))
+			_, freed, err := removeTree(path, dryRun)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to evict delivery %s: %w", c.delivery.DeliveryName, err)
+			}
+			used -= freed
+			s.emit(SyncEvent{Type: DeliveryEvicted, ProductID: c.productID, Delivery: c.delivery})
+		}
+		if used+required <= s.quota.MaxBytes {
+			return nil
+		}
+	}
+	return &QuotaExceededError{Path: s.dir, MaxBytes: s.quota.MaxBytes, Used: used, Required: required}
+}
+
+// quotaRequired estimates how much the mirror grows by downloading jobs:
+// their catalog sizes, less the partial files they resume and the outdated
+// copies they replace.
+func quotaRequired(jobs []*FileResult) int64 {
+	var total int64
+	for _, job := range jobs {
+		size := parseFileSize(job.File.FileSize)
+		for _, path := range []string{job.Path, job.Path + partialSuffix} {
+			if info, err := os.Stat(path); err == nil {
+				size -= info.Size()
+			}
+		}
+		total += max(size, 0)
+	}
+	return total
+}
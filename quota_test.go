@@ -0,0 +1,118 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSyncQuotaRefuse(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, stats := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", published, 2)})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "other.bin"), make([]byte, 100), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewSync(client, NewMemorySyncStore(), dir, nil)
+	s.SetQuota(&Quota{MaxBytes: 120})
+
+	_, err := s.Run(context.Background(), 3)
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expected a QuotaExceededError, got %v", err)
+	}
+	if quotaErr.Used != 100 || quotaErr.Required != 34 || quotaErr.MaxBytes != 120 {
+		t.Errorf("error = %+v", quotaErr)
+	}
+	if n := atomic.LoadInt32(&stats.downloads); n != 0 {
+		t.Errorf("downloaded %d files over quota", n)
+	}
+
+	s.SetQuota(&Quota{MaxBytes: 134})
+	if _, err := s.Run(context.Background(), 3); err != nil {
+		t.Errorf("Run within quota: %v", err)
+	}
+}
+
+func TestSyncQuotaEvictExpired(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	expiry := published.AddDate(0, 3, 0)
+	expired := weeklyDelivery(7, "2024-10-15", published, 1)
+	expired.expiry = &expiry
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		expired,
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 1),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	store := NewMemorySyncStore()
+	dir := t.TempDir()
+	first := NewSync(client, store, dir, &DownloadOptions{
+		DeliveryFilter: func(d *Delivery) bool { return d.DeliveryID == 7 },
+	})
+	if _, err := first.Run(context.Background(), 3); err != nil {
+		t.Fatalf("first Run: %v", err)
+	}
+	expiredDir := filepath.Join(dir, "3", "2024-10-15")
+	if err := os.WriteFile(filepath.Join(expiredDir, "extra.bin"), make([]byte, 1000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSync(client, store, dir, nil)
+	var evicted []int
+	s.Subscribe(func(e SyncEvent) {
+		if e.Type == DeliveryEvicted {
+			evicted = append(evicted, e.Delivery.DeliveryID)
+		}
+	})
+
+	// A dry run reports the eviction but keeps the files.
+	s.SetQuota(&Quota{MaxBytes: 500, Policy: QuotaEvictExpired})
+	dry := NewSync(client, store, dir, &DownloadOptions{DryRun: true})
+	dry.SetQuota(&Quota{MaxBytes: 500, Policy: QuotaEvictExpired})
+	if _, err := dry.RunSince(context.Background(), time.Time{}, 3); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if _, err := os.Stat(expiredDir); err != nil {
+		t.Fatalf("dry run evicted the delivery: %v", err)
+	}
+
+	results, err := s.RunSince(context.Background(), time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("RunSince: %v", err)
+	}
+	if len(results) != 1 || results[0].DeliveryID != 8 {
+		t.Errorf("results = %v, want only delivery 8", results)
+	}
+	if _, err := os.Stat(expiredDir); !os.IsNotExist(err) {
+		t.Errorf("expired delivery not evicted: %v", err)
+	}
+	if len(evicted) != 1 || evicted[0] != 7 {
+		t.Errorf("evicted = %v, want [7]", evicted)
+	}
+
+	// Nothing left to evict: a tighter quota refuses.
+	s.SetQuota(&Quota{MaxBytes: 10, Policy: QuotaEvictExpired})
+	if err := os.Remove(results[0].Path); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(context.Background(), &SyncRecord{ProductID: 3, DeliveryID: 8, FileID: 801, Checksum: "outdated"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RunSince(context.Background(), time.Time{}, 3); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("expected the quota to refuse the run, got %v", err)
+	}
+}
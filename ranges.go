@@ -0,0 +1,169 @@
+package bdds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/patent-dev/epo-bdds/generated"
+)
+
+// withRange returns a request editor that asks for the byte range
+// [start, end]. A negative end requests everything from start onwards.
+func withRange(start, end int64) generated.RequestEditorFn {
+	return func(_ context.Context, req *http.Request) error {
+		if end < 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+		} else {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+		}
+		return nil
+	}
+}
+
+// withIfRange returns a request editor that makes a Range request
+// conditional on the file still matching validator, an ETag or date; a
+// changed file is sent in full instead.
+func withIfRange(validator string) generated.RequestEditorFn {
+	return func(_ context.Context, req *http.Request) error {
+		req.Header.Set("If-Range", validator)
+		return nil
+	}
+}
+
+// contentRange is a parsed Content-Range response header. Total is -1 when the
+// server reports an unknown complete length ("*").
+type contentRange struct {
+	Start, End, Total int64
+}
+
+// parseContentRange parses "bytes start-end/total" and the unsatisfied-range
+// form "bytes */total".
+func parseContentRange(v string) (contentRange, bool) {
+	unit, spec, ok := strings.Cut(strings.TrimSpace(v), " ")
+	if !ok || !strings.EqualFold(unit, "bytes") {
+		return contentRange{}, false
+	}
+	rng, total, ok := strings.Cut(spec, "/")
+	if !ok {
+		return contentRange{}, false
+	}
+
+	cr := contentRange{Start: -1, End: -1, Total: -1}
+	if total != "*" {
+		n, err := strconv.ParseInt(total, 10, 64)
+		if err != nil || n < 0 {
+			return contentRange{}, false
+		}
+		cr.Total = n
+	}
+	if rng == "*" {
+		return cr, cr.Total >= 0
+	}
+	first, last, ok := strings.Cut(rng, "-")
+	if !ok {
+		return contentRange{}, false
+	}
+	start, err1 := strconv.ParseInt(first, 10, 64)
+	end, err2 := strconv.ParseInt(last, 10, 64)
+	if err1 != nil || err2 != nil || start < 0 || end < start {
+		return contentRange{}, false
+	}
+	cr.Start, cr.End = start, end
+	return cr, true
+}
+
+// downloadResponseError maps a non-success download response to a typed error,
+// consuming the body. It returns nil for 200 and 206.
+func downloadResponseError(resp *http.Response, productID, deliveryID, fileID int) error {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return nil
+	case http.StatusNotFound:
+		return withRequestID(&NotFoundError{
+			Resource: "file",
+			ID:       fmt.Sprintf("%d/%d/%d", productID, deliveryID, fileID),
+		}, resp)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return responseError(resp, body)
+	}
+}
+
+// DownloadFileRange writes length bytes of a file starting at offset to dst
+// and returns the number of bytes written, e.g. to read the central directory
+// of a large ZIP archive without downloading it. A negative length reads to
+// the end of the file; a range running past the end is cut short there.
+//
+// An interrupted range is resumed from its last written byte. A server that
+// ignores the Range header is handled by skipping the leading bytes of the
+// full response.
+func (c *Client) DownloadFileRange(ctx context.Context, productID, deliveryID, fileID int, offset, length int64, dst io.Writer, opts ...CallOption) (int64, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("invalid range offset %d", offset)
+	}
+	if length == 0 {
+		return 0, nil
+	}
+	end := int64(-1)
+	if length > 0 {
+		end = offset + length - 1
+	}
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	var written int64
+	err := c.retryableRequest(ctx, func() error {
+		start := offset + written
+		resp, err := c.generatedClient.DownloadFile(ctx, productID, deliveryID, fileID, withRange(start, end))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		want := int64(-1)
+		if end >= 0 {
+			want = end - start + 1
+		}
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			cr, ok := parseContentRange(resp.Header.Get("Content-Range"))
+			if !ok || cr.Start != start {
+				return &nonRetryableError{err: fmt.Errorf("server returned mismatched Content-Range %q for range starting at %d", resp.Header.Get("Content-Range"), start)}
+			}
+			want = cr.End - start + 1
+		case http.StatusOK:
+			if n, err := io.CopyN(io.Discard, resp.Body, start); err != nil {
+				if err == io.EOF {
+					return &nonRetryableError{err: fmt.Errorf("range offset %d is beyond the end of the file (%d bytes)", start, n)}
+				}
+				return err
+			}
+		case http.StatusRequestedRangeNotSatisfiable:
+			return &nonRetryableError{err: fmt.Errorf("range offset %d is beyond the end of the file", start)}
+		default:
+			return downloadResponseError(resp, productID, deliveryID, fileID)
+		}
+
+		body := io.Reader(resp.Body)
+		if want >= 0 {
+			body = io.LimitReader(resp.Body, want)
+		}
+		n, err := io.Copy(dst, body)
+		written += n
+		if err != nil {
+			return err
+		}
+		if want >= 0 && n < want {
+			if resp.StatusCode == http.StatusOK {
+				return nil // the file ends inside the requested range
+			}
+			return fmt.Errorf("range ended after %d of %d bytes: %w", n, want, io.ErrUnexpectedEOF)
+		}
+		return nil
+	})
+	return written, err
+}
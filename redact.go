@@ -0,0 +1,94 @@
+package bdds
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secrets in error messages.
+const redacted = "[REDACTED]"
+
+var (
+	// secretJSONField matches string values of credential fields in JSON,
+	// e.g. token endpoint responses echoing a token.
+	secretJSONField = regexp.MustCompile(`(?i)("(?:access_token|refresh_token|id_token|token|password|client_secret|secret)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// secretFormField matches credential fields in query strings and form
+	// bodies.
+	secretFormField = regexp.MustCompile(`(?i)\b((?:access_token|refresh_token|id_token|token|password|client_secret|secret)=)[^&\s"]+`)
+	// authCredentials matches Authorization header values.
+	authCredentials = regexp.MustCompile(`(?i)\b(Bearer|Basic|Negotiate|NTLM)\s+[A-Za-z0-9._~+/=-]+`)
+	// urlPassword matches the password of credentials embedded in a URL.
+	urlPassword = regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`)
+)
+
+// redactSecrets masks tokens, passwords and authorization credentials in s,
+// along with any of the given secrets, so that response bodies can be
+// quoted in errors and logs.
+func redactSecrets(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		if len(secret) >= 4 {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	s = secretJSONField.ReplaceAllString(s, `$1"`+redacted+`"`)
+	s = secretFormField.ReplaceAllString(s, "${1}"+redacted)
+	s = authCredentials.ReplaceAllString(s, "$1 "+redacted)
+	return urlPassword.ReplaceAllString(s, "${1}"+redacted+"@")
+}
+
+// redactURL shortens a URL that may carry a secret in its credentials, path
+// or query, such as a Slack webhook, to its scheme and host.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	s := u.Scheme + "://" + u.Host
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		s += "/..."
+	}
+	return s
+}
+
+// redactURLError masks the URL of a *url.Error, which http.Client.Do quotes
+// in full, with redactURL.
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	return &url.Error{Op: urlErr.Op, URL: redactURL(urlErr.URL), Err: urlErr.Err}
+}
+
+// String formats the configuration with its passwords and proxy credentials
+// redacted, so that it can be logged.
+func (c Config) String() string {
+	type plain Config // without the String method
+	p := plain(c)
+	if p.Password != "" {
+		p.Password = redacted
+	}
+	return redactSecrets(fmt.Sprintf("%+v", p))
+}
+
+// GoString is String, for the %#v verb.
+func (c Config) GoString() string {
+	return c.String()
+}
+
+// String formats the account with its password redacted.
+func (a Account) String() string {
+	password := ""
+	if a.Password != "" {
+		password = redacted
+	}
+	return fmt.Sprintf("{Username:%s Password:%s Products:%v}", a.Username, password, a.Products)
+}
+
+// GoString is String, for the %#v verb.
+func (a Account) GoString() string {
+	return a.String()
+}
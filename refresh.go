@@ -0,0 +1,105 @@
+package bdds
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// backgroundRefreshAhead is how long before expiry the background
+	// refresh renews a token: earlier than requests would
+	// (tokenRefreshBuffer), so they never wait for a password grant.
+	backgroundRefreshAhead = 2 * tokenRefreshBuffer
+	// backgroundRefreshRetry is the delay after a failed background refresh.
+	backgroundRefreshRetry = 30 * time.Second
+)
+
+// startTokenRefresh starts the background refresh of Config.BackgroundTokenRefresh.
+func (c *Client) startTokenRefresh() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.refreshWake = make(chan struct{}, 1)
+	c.stopRefresh = cancel
+	c.refreshDone = make(chan struct{})
+	go func() {
+		defer close(c.refreshDone)
+		c.refreshTokens(ctx)
+	}()
+}
+
+// wakeTokenRefresh tells the background refresh that a token was obtained.
+func (c *Client) wakeTokenRefresh() {
+	if c.refreshWake == nil {
+		return
+	}
+	select {
+	case c.refreshWake <- struct{}{}:
+	default:
+	}
+}
+
+// refreshTokens renews every account's token shortly before it expires,
+// until ctx is cancelled. Accounts get their first token from a request.
+func (c *Client) refreshTokens(ctx context.Context) {
+	for {
+		var next time.Time
+		for _, a := range c.allAccounts() {
+			if due, ok := c.refreshToken(ctx, a); ok && (next.IsZero() || due.Before(next)) {
+				next = due
+			}
+		}
+
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-c.refreshWake:
+		case <-fire:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// refreshToken renews an account's token if it is due and returns when it
+// is due next; ok is false for an account without a token. The old token
+// stays in use while the new one is granted.
+func (c *Client) refreshToken(ctx context.Context, a *account) (due time.Time, ok bool) {
+	a.mu.Lock()
+	if a.token == "" {
+		a.mu.Unlock()
+		return time.Time{}, false
+	}
+	if due := refreshDue(a.tokenIssued, a.tokenExpiry); time.Now().Before(due) {
+		a.mu.Unlock()
+		return due, true
+	}
+	g := c.grantTokenLocked(ctx, a)
+	a.mu.Unlock()
+
+	select {
+	case <-g.done:
+	case <-ctx.Done():
+		return time.Time{}, false
+	}
+	if g.err != nil {
+		return time.Now().Add(backgroundRefreshRetry), true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return refreshDue(a.tokenIssued, a.tokenExpiry), true
+}
+
+// refreshDue returns when the background refresh renews a token:
+// backgroundRefreshAhead before it expires, or halfway through the lifetime
+// of short-lived tokens.
+func refreshDue(issued, expiry time.Time) time.Time {
+	return expiry.Add(-min(backgroundRefreshAhead, expiry.Sub(issued)/2))
+}
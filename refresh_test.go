@@ -0,0 +1,101 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackgroundTokenRefresh(t *testing.T) {
+	authServer, authCalls := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(&Config{
+		Username:               "u",
+		Password:               "p",
+		BaseURL:                apiServer.URL,
+		TokenURL:               authServer.URL,
+		RevokeURL:              authServer.URL,
+		BackgroundTokenRefresh: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.ListProducts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(authCalls); n != 1 {
+		t.Fatalf("expected 1 password grant, got %d", n)
+	}
+
+	// Age the token to 6 minutes before expiry: still valid for requests,
+	// due for the background refresh.
+	a := client.account
+	a.mu.Lock()
+	old := a.token
+	a.tokenIssued, a.tokenExpiry = time.Now().Add(-time.Hour), time.Now().Add(6*time.Minute)
+	a.mu.Unlock()
+	client.wakeTokenRefresh()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(authCalls) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(authCalls); n != 2 {
+		t.Fatalf("expected the background refresh to grant a token, got %d grants", n)
+	}
+	a.mu.Lock()
+	renewed, expiry := a.token, a.tokenExpiry
+	a.mu.Unlock()
+	if renewed == old || time.Until(expiry) < 50*time.Minute {
+		t.Errorf("token not renewed: %q expiring in %s", renewed, time.Until(expiry))
+	}
+	if _, err := client.ListProducts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(authCalls); n != 2 {
+		t.Errorf("request after the refresh granted another token (%d grants)", n)
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case <-client.refreshDone:
+	default:
+		t.Error("refresh goroutine still running after Close")
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestRefreshDue(t *testing.T) {
+	issued := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	if got := refreshDue(issued, issued.Add(time.Hour)); !got.Equal(issued.Add(50 * time.Minute)) {
+		t.Errorf("refreshDue for a 1h token = %s", got)
+	}
+	if got := refreshDue(issued, issued.Add(4*time.Minute)); !got.Equal(issued.Add(2 * time.Minute)) {
+		t.Errorf("refreshDue for a 4m token = %s", got)
+	}
+}
+
+func TestCloseWithoutRefresh(t *testing.T) {
+	client, err := NewClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
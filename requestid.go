@@ -0,0 +1,100 @@
+package bdds
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/http"
+)
+
+// RequestIDHeader carries the ID of a call in each of its requests.
+const RequestIDHeader = "X-Request-ID"
+
+// correlationHeaders are the response headers in which servers and gateways
+// return their own ID of a request, in order of preference.
+var correlationHeaders = []string{RequestIDHeader, "X-Correlation-ID", "Request-Id"}
+
+// WithRequestID sends id as the X-Request-ID of the requests of the call,
+// e.g. to correlate them with the caller's own tracing, instead of a
+// generated one.
+func WithRequestID(id string) CallOption {
+	return func(o *callOptions) { o.requestID = id }
+}
+
+// RequestID returns the request ID of the failed request behind err, for
+// reference in an EPO support ticket: the ID the server returned in a
+// correlation header, or else the X-Request-ID the client sent. It returns
+// "" for errors without a response.
+func RequestID(err error) string {
+	var e interface{ requestID() string }
+	if errors.As(err, &e) {
+		return e.requestID()
+	}
+	return ""
+}
+
+func (e *AuthError) requestID() string      { return e.RequestID }
+func (e *NotFoundError) requestID() string  { return e.RequestID }
+func (e *RateLimitError) requestID() string { return e.RequestID }
+func (e *statusError) requestID() string    { return e.RequestID }
+
+// withRequestID records the request ID of resp in err, if it is one of the
+// errors of a response.
+func withRequestID(err error, resp *http.Response) error {
+	id := responseRequestID(resp)
+	switch e := err.(type) {
+	case *AuthError:
+		e.RequestID = id
+	case *NotFoundError:
+		e.RequestID = id
+	case *RateLimitError:
+		e.RequestID = id
+	case *statusError:
+		e.RequestID = id
+	}
+	return err
+}
+
+// responseRequestID returns the server's ID of resp, or else the ID its
+// request was sent with.
+func responseRequestID(resp *http.Response) string {
+	for _, h := range correlationHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			return id
+		}
+	}
+	if resp.Request != nil {
+		return resp.Request.Header.Get(RequestIDHeader)
+	}
+	return ""
+}
+
+// requestIDTransport sets the X-Request-ID of each request that has none:
+// that of its call (see withCallOptions), or else a new one.
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Header.Get(RequestIDHeader) == "" {
+		id := newRequestID()
+		if o := callOptionsFrom(req.Context()); o != nil && o.requestID != "" {
+			id = o.requestID
+		}
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+	}
+	return base.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *requestIDTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+func newRequestID() string {
+	return rand.Text()
+}
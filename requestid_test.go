@@ -0,0 +1,65 @@
+package bdds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		ids []string
+	)
+	record := func(r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, r.Header.Get(RequestIDHeader))
+	}
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		if strings.HasSuffix(r.URL.Path, "/products/404") {
+			w.Header().Set("X-Correlation-ID", "epo-42")
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+
+	_, err := client.ListProducts(ctx)
+	id := RequestID(err)
+	mu.Lock()
+	sent := ids
+	ids = nil
+	mu.Unlock()
+	if id == "" || !strings.HasSuffix(err.Error(), "(request ID "+id+")") {
+		t.Errorf("error %q carries request ID %q", err, id)
+	}
+	// The token grant and all four attempts of the call share its ID.
+	if len(sent) != 5 || strings.Count(strings.Join(sent, " "), id) != 5 {
+		t.Errorf("sent IDs %q, want 5 times %q", sent, id)
+	}
+
+	_, err = client.ListProducts(ctx, WithRequestID("caller-1"), WithNoRetry())
+	if got := RequestID(err); got != "caller-1" {
+		t.Errorf("RequestID = %q, want caller-1", got)
+	}
+
+	_, err = client.GetProduct(ctx, 404)
+	if got := RequestID(err); got != "epo-42" {
+		t.Errorf("RequestID = %q, want the server's epo-42 (%v)", got, err)
+	}
+}
@@ -0,0 +1,49 @@
+package bdds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// revokeToken revokes and forgets an account's access token.
+func (c *Client) revokeToken(ctx context.Context, a *account) error {
+	a.mu.Lock()
+	token := a.token
+	if token != "" && time.Now().Before(a.tokenExpiry) {
+		a.rejectedToken = token
+	} else {
+		token = "" // nothing live to revoke
+	}
+	a.token, a.tokenExpiry = "", time.Time{}
+	a.mu.Unlock()
+	if token == "" {
+		return nil
+	}
+
+	data := url.Values{
+		"token":           {token},
+		"token_type_hint": {"access_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.RevokeURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revocation request: %w", err)
+	}
+	req.Header.Set("Authorization", "Basic "+c.config.ClientID)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token revocation failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("token revocation failed (status %d): %s", resp.StatusCode, strings.TrimSpace(redactSecrets(string(body), token, c.config.ClientID)))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newRevokeServer records the tokens revoked at it.
+func newRevokeServer(t *testing.T, status int) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var revoked []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Basic "+DefaultConfig().ClientID {
+			t.Errorf("revocation without client authorization: %q", r.Header.Get("Authorization"))
+		}
+		if hint := r.PostFormValue("token_type_hint"); hint != "access_token" {
+			t.Errorf("token_type_hint = %q", hint)
+		}
+		mu.Lock()
+		revoked = append(revoked, r.PostFormValue("token"))
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), revoked...)
+	}
+}
+
+func newRevokeTestClient(t *testing.T, revokeURL string, cache TokenCache) *Client {
+	t.Helper()
+	authServer, _ := newAuthServer(3600)
+	t.Cleanup(authServer.Close)
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+	}))
+	t.Cleanup(apiServer.Close)
+
+	client, err := NewClient(&Config{
+		Username:   "u",
+		Password:   "p",
+		BaseURL:    apiServer.URL,
+		TokenURL:   authServer.URL,
+		RevokeURL:  revokeURL,
+		TokenCache: cache,
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCloseRevokesToken(t *testing.T) {
+	revokeServer, revoked := newRevokeServer(t, http.StatusOK)
+	client := newRevokeTestClient(t, revokeServer.URL, nil)
+	token := client.account.token
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := revoked(); len(got) != 1 || got[0] != token {
+		t.Errorf("revoked %q, want [%q]", got, token)
+	}
+	if client.account.token != "" {
+		t.Error("token kept after Close")
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if got := revoked(); len(got) != 1 {
+		t.Errorf("second Close revoked again: %q", got)
+	}
+}
+
+func TestCloseRevocationFailure(t *testing.T) {
+	revokeServer, _ := newRevokeServer(t, http.StatusBadRequest)
+	client := newRevokeTestClient(t, revokeServer.URL, nil)
+	if err := client.Close(context.Background()); err == nil {
+		t.Error("expected an error for a rejected revocation")
+	}
+}
+
+func TestCloseKeepsCachedToken(t *testing.T) {
+	revokeServer, revoked := newRevokeServer(t, http.StatusOK)
+	fastTokenCacheKeys(t)
+	cache, err := NewFileTokenCache(filepath.Join(t.TempDir(), "token"), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newRevokeTestClient(t, revokeServer.URL, cache)
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := revoked(); len(got) != 0 {
+		t.Errorf("revoked a cached token: %q", got)
+	}
+}
@@ -35,9 +35,11 @@ ignored=""
 
 covered=0
 missing=0
+# Here-strings rather than `printf | grep -q`: grep -q exits on the first match,
+# and the resulting SIGPIPE in printf would fail the pipeline under pipefail.
 for m in $methods; do
-  printf '%s\n' "$ignored" | grep -qxF "$m" && continue
-  if printf '%s\n' "$tests" | grep -qxF "$m"; then
+  grep -qxF "$m" <<<"$ignored" && continue
+  if grep -qxF "$m" <<<"$tests"; then
     covered=$((covered + 1))
   else
     echo "MISSING: TestIntegration$m (no integration test for Client.$m)"
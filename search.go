@@ -0,0 +1,78 @@
+package bdds
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+// ProductMatch is a product found by SearchProducts.
+type ProductMatch struct {
+	*Product
+	// Score ranks the match; higher is better. Scores only compare results
+	// of the same search.
+	Score int `json:"score"`
+}
+
+// SearchProducts returns the products whose name or description mentions
+// the words of query, best match first. Words are compared like product
+// names in GetProductByName: case, diacritics and punctuation don't matter.
+// A word counts more in the name than in the description, and as a whole
+// word than as part of one; products mentioning all words rank above those
+// mentioning only some. SearchProducts returns nil if nothing matches.
+func (c *Client) SearchProducts(ctx context.Context, query string, opts ...CallOption) ([]ProductMatch, error) {
+	products, err := c.ListProducts(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(normalizeName(query))
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	var matches []ProductMatch
+	for _, p := range uniqueProducts(products) {
+		if score := scoreProduct(p, words); score > 0 {
+			matches = append(matches, ProductMatch{Product: p, Score: score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b ProductMatch) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return matches, nil
+}
+
+// scoreProduct scores how well a product matches the normalized words of
+// a query: per word 4 for a whole word of the name, 2 for part of one, plus
+// 2 and 1 for the description. Matching every word doubles the score.
+func scoreProduct(p *Product, words []string) int {
+	name := strings.Fields(normalizeName(p.Name))
+	description := strings.Fields(normalizeName(p.Description))
+	score, found := 0, 0
+	for _, w := range words {
+		s := 2*wordScore(name, w) + wordScore(description, w)
+		if s > 0 {
+			found++
+		}
+		score += s
+	}
+	if found == len(words) {
+		score *= 2
+	}
+	return score
+}
+
+// wordScore returns 2 if w is one of words, 1 if it is part of one, else 0.
+func wordScore(words []string, w string) int {
+	if slices.Contains(words, w) {
+		return 2
+	}
+	if slices.ContainsFunc(words, func(s string) bool { return strings.Contains(s, w) }) {
+		return 1
+	}
+	return 0
+}
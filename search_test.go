@@ -0,0 +1,89 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestSearchProducts(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"id": 3, "name": "EP DocDB front file", "description": "Weekly bibliographic data"},
+			{"id": 4, "name": "EP full-text data – front file", "description": "Full texts of EP publications"},
+			{"id": 14, "name": "EP DocDB back file", "description": "Bibliographic backlog"},
+			{"id": 17, "name": "PATSTAT Global", "description": "Statistical database of patent data"},
+		})
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+
+	ids := func(query string) []int {
+		t.Helper()
+		matches, err := client.SearchProducts(ctx, query)
+		if err != nil {
+			t.Fatalf("SearchProducts(%q): %v", query, err)
+		}
+		var out []int
+		for _, m := range matches {
+			if m.Score <= 0 {
+				t.Errorf("SearchProducts(%q): product %d scored %d", query, m.ID, m.Score)
+			}
+			out = append(out, m.ID)
+		}
+		return out
+	}
+	for _, tt := range []struct {
+		query string
+		want  []int
+	}{
+		// All words rank first, ties go by product ID.
+		{"docdb front", []int{3, 4, 14}},
+		{"full-text", []int{4}},
+		{"FULL TEXT", []int{4}},
+		// Name beats description, whole words beat parts of words.
+		{"bibliographic", []int{3, 14}},
+		{"data", []int{4, 3, 17}},
+		{"statistic", []int{17}},
+		{"unknown", nil},
+		{"  ", nil},
+	} {
+		if got := ids(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("SearchProducts(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestSearchProductsRepeatedRows verifies products the recorded catalog
+// lists twice are found once.
+func TestSearchProductsRepeatedRows(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	products := recordedProducts(t)
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(products)
+	}))
+	defer apiServer.Close()
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	matches, err := client.SearchProducts(context.Background(), "worldwide")
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+	var got []int
+	for _, m := range matches {
+		got = append(got, m.ID)
+	}
+	slices.Sort(got)
+	if want := []int{3, 5, 11, 14}; !slices.Equal(got, want) {
+		t.Errorf("SearchProducts(worldwide) = %v, want each of %v once", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package bdds
+
+import "os"
+
+// preallocate extends f to size bytes. Platforms without a preallocation
+// call get a sparse file whose blocks are allocated as segments arrive.
+func preallocate(f *os.File, size int64) error {
+	return f.Truncate(size)
+}
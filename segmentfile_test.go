@@ -0,0 +1,119 @@
+package bdds
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestSegmentFileOutOfOrderWrites verifies segments written concurrently and
+// out of order land at their final offsets, with and without mmap.
+func TestSegmentFileOutOfOrderWrites(t *testing.T) {
+	for _, mmap := range []bool{false, true} {
+		name := "pwrite"
+		if mmap {
+			name = "mmap"
+		}
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "big.zip")
+			content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+
+			sf, err := CreateSegmentFile(path, int64(len(content)), &SegmentFileOptions{Mmap: mmap})
+			if err != nil {
+				t.Fatalf("CreateSegmentFile: %v", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Stat: %v", err)
+			}
+			if info.Size() != int64(len(content)) {
+				t.Errorf("preallocated size = %d, want %d", info.Size(), len(content))
+			}
+
+			var wg sync.WaitGroup
+			for _, off := range []int{30, 0, 20, 10} {
+				wg.Add(1)
+				go func(off int) {
+					defer wg.Done()
+					end := min(off+10, len(content))
+					if _, err := sf.WriteAt(content[off:end], int64(off)); err != nil {
+						t.Errorf("WriteAt(%d): %v", off, err)
+					}
+				}(off)
+			}
+			wg.Wait()
+
+			got, err := io.ReadAll(io.NewSectionReader(sf, 0, sf.Size()))
+			if err != nil {
+				t.Fatalf("read back: %v", err)
+			}
+			if string(got) != string(content) {
+				t.Errorf("ReadAt content = %q", got)
+			}
+			if err := sf.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			got, err = os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if string(got) != string(content) {
+				t.Errorf("file content = %q, want %q", got, content)
+			}
+		})
+	}
+}
+
+// TestSegmentFileRejectsOverflow verifies writes cannot grow the file past its
+// preallocated size.
+func TestSegmentFileRejectsOverflow(t *testing.T) {
+	sf, err := CreateSegmentFile(filepath.Join(t.TempDir(), "f"), 4, nil)
+	if err != nil {
+		t.Fatalf("CreateSegmentFile: %v", err)
+	}
+	defer func() { _ = sf.Close() }()
+
+	if _, err := sf.WriteAt([]byte("abc"), 2); err == nil {
+		t.Error("expected error writing past the preallocated size")
+	}
+	if _, err := sf.WriteAt([]byte("x"), -1); err == nil {
+		t.Error("expected error for negative offset")
+	}
+}
+
+// TestSegmentFileCloseDuringWrites verifies writes racing with Close either
+// complete or fail with os.ErrClosed, with and without mmap.
+func TestSegmentFileCloseDuringWrites(t *testing.T) {
+	for _, mmap := range []bool{false, true} {
+		sf, err := CreateSegmentFile(filepath.Join(t.TempDir(), "f"), 1<<16, &SegmentFileOptions{Mmap: mmap})
+		if err != nil {
+			t.Fatalf("CreateSegmentFile: %v", err)
+		}
+		var wg sync.WaitGroup
+		for w := range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				chunk := make([]byte, 1<<12)
+				for off := int64(w) << 14; ; off = (off + 1<<12) % (1 << 16) {
+					if _, err := sf.WriteAt(chunk, off); err != nil {
+						if !errors.Is(err, os.ErrClosed) {
+							t.Errorf("mmap %v: write failed with %v, want os.ErrClosed", mmap, err)
+						}
+						return
+					}
+				}
+			}()
+		}
+		if err := sf.Close(); err != nil {
+			t.Errorf("mmap %v: Close: %v", mmap, err)
+		}
+		wg.Wait()
+		if _, err := sf.ReadAt(make([]byte, 1), 0); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("mmap %v: read after Close = %v, want os.ErrClosed", mmap, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package bdds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Sink is a destination for the files of bulk downloads (see
+// DownloadOptions.Sink), e.g. an object store. The default writes to the
+// local file system (see DirSink).
+type Sink interface {
+	// Create starts storing a file under name, a slash-separated relative
+	// path such as "docdb/2024-10-15/docdb_01.zip". file is its catalog
+	// entry, e.g. for object metadata. Create may be called concurrently.
+	Create(ctx context.Context, name string, file *DeliveryFile) (SinkFile, error)
+}
+
+// SinkFile is one file being written to a Sink. Exactly one of Commit and
+// Abort is called once writing has finished.
+type SinkFile interface {
+	io.Writer
+	// Commit stores the written content under the file's name.
+	Commit() error
+	// Abort abandons a failed download. The content must not appear under
+	// the file's name; a sink may keep it to resume a later download.
+	Abort() error
+}
+
+// DirSink returns a Sink that stores files below dir. Each file is written
+// to "<path>.part" and renamed into place on Commit; an aborted .part file is
+// kept and resumed by the next download of the same file (see
+// DownloadFileToPath). This is what bulk downloads use when no Sink is set.
+func DirSink(dir string) Sink {
+	return dirSink(dir)
+}
+
+type dirSink string
+
+func (d dirSink) Create(_ context.Context, name string, _ *DeliveryFile) (SinkFile, error) {
+	return openPartial(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+// partialFile is a download in progress in "<path>.part". The validator of
+// the response its content came from is kept in "<path>.part.validator", so
+// a later download only resumes it if the file is unchanged.
+type partialFile struct {
+	*os.File
+	path      string
+	offset    int64  // bytes already present when opened, to resume from
+	validator string // ETag or Last-Modified of the kept content
+}
+
+// openPartial opens the partial file for path, creating missing parent
+// directories, positioned after any content kept from an earlier attempt.
+func openPartial(path string) (*partialFile, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	f, err := os.OpenFile(path+partialSuffix, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open partial file: %w", err)
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	validator, _ := os.ReadFile(path + partialValidatorSuffix)
+	return &partialFile{File: f, path: path, offset: offset, validator: string(validator)}, nil
+}
+
+// resumeValidator returns the validator of the kept content.
+func (f *partialFile) resumeValidator() string {
+	return f.validator
+}
+
+// setResumeValidator records the validator of the response being written,
+// before its content, so a crash cannot leave content with a wrong one.
+func (f *partialFile) setResumeValidator(v string) error {
+	f.validator = v
+	if v == "" {
+		if err := os.Remove(f.path + partialValidatorSuffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return writeFileAtomic(f.path+partialValidatorSuffix, []byte(v))
+}
+
+// Commit syncs the partial file and renames it over the final path.
+func (f *partialFile) Commit() error {
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync %s: %w", f.Name(), err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", f.Name(), err)
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+	_ = os.Remove(f.path + partialValidatorSuffix)
+	return nil
+}
+
+// Abort closes the partial file, keeping it only if it has content worth
+// resuming: some bytes and a validator to resume them with.
+func (f *partialFile) Abort() error {
+	info, err := f.Stat()
+	_ = f.Close()
+	if err != nil || info.Size() == 0 || f.validator == "" {
+		return removePartial(f.path)
+	}
+	return nil
+}
+
+// discard closes and removes the partial file, for failures that resuming
+// would not fix.
+func (f *partialFile) discard() error {
+	_ = f.Close()
+	return removePartial(f.path)
+}
+
+// removePartial removes the partial file of path and its validator.
+func removePartial(path string) error {
+	_ = os.Remove(path + partialValidatorSuffix)
+	return os.Remove(path + partialSuffix)
+}
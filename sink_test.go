@@ -0,0 +1,151 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memSink stores committed files in memory and records aborted ones.
+type memSink struct {
+	mu        sync.Mutex
+	failName  string // writes to this file fail
+	committed map[string]string
+	aborted   []string
+	files     map[string]*DeliveryFile
+}
+
+func newMemSink() *memSink {
+	return &memSink{committed: map[string]string{}, files: map[string]*DeliveryFile{}}
+}
+
+func (s *memSink) Create(_ context.Context, name string, file *DeliveryFile) (SinkFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = file
+	return &memSinkFile{sink: s, name: name}, nil
+}
+
+type memSinkFile struct {
+	sink *memSink
+	name string
+	buf  bytes.Buffer
+}
+
+func (f *memSinkFile) Write(p []byte) (int, error) {
+	if f.name == f.sink.failName {
+		return 0, errors.New("upload rejected")
+	}
+	return f.buf.Write(p)
+}
+
+func (f *memSinkFile) Commit() error {
+	f.sink.mu.Lock()
+	defer f.sink.mu.Unlock()
+	f.sink.committed[f.name] = f.buf.String()
+	return nil
+}
+
+func (f *memSinkFile) Abort() error {
+	f.sink.mu.Lock()
+	defer f.sink.mu.Unlock()
+	f.sink.aborted = append(f.sink.aborted, f.name)
+	return nil
+}
+
+// TestDownloadProductSink verifies bulk downloads write through a custom Sink
+// under slash-separated names, committing good files and aborting failed ones.
+func TestDownloadProductSink(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", published, 2),
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 1),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	sink := newMemSink()
+	sink.failName = "docdb/2024-10-22/20241022_01.zip"
+	results, err := client.DownloadProduct(context.Background(), 3, "docdb", &DownloadOptions{Sink: sink})
+	if err == nil {
+		t.Fatal("expected an error for the rejected upload")
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	want := map[string]string{
+		"docdb/2024-10-15/20241015_01.zip": "delivery 7 file 1",
+		"docdb/2024-10-15/20241015_02.zip": "delivery 7 file 2",
+	}
+	for name, content := range want {
+		if got := sink.committed[name]; got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+		if f := sink.files[name]; f == nil || f.FileChecksum != sha1Hex(content) {
+			t.Errorf("%s was created without its catalog entry: %+v", name, f)
+		}
+	}
+	if len(sink.committed) != 2 {
+		t.Errorf("expected 2 committed files, got %v", sink.committed)
+	}
+	if len(sink.aborted) != 1 || sink.aborted[0] != sink.failName {
+		t.Errorf("aborted = %v, want [%s]", sink.aborted, sink.failName)
+	}
+	if results[2].Err == nil || results[2].Bytes != 0 {
+		t.Errorf("failed upload reported as %+v", results[2])
+	}
+}
+
+// TestDirSink verifies DirSink stores files below its directory like the
+// default destination.
+func TestDirSink(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", published, 2)})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	dir := t.TempDir()
+	if _, err := client.DownloadDelivery(context.Background(), 3, 7, "2024-10-15", &DownloadOptions{Sink: DirSink(dir)}); err != nil {
+		t.Fatalf("DownloadDelivery: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		got, err := os.ReadFile(filepath.Join(dir, "2024-10-15", fmt.Sprintf("20241015_%02d.zip", i)))
+		if want := fmt.Sprintf("delivery 7 file %d", i); err != nil || string(got) != want {
+			t.Errorf("file %d = %q, %v; want %q", i, got, err, want)
+		}
+	}
+	assertNoTempFiles(t, filepath.Join(dir, "2024-10-15"))
+}
+
+// TestSinkRejectsLocalOptions verifies options that need local files are
+// refused with a Sink before anything is downloaded.
+func TestSinkRejectsLocalOptions(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer, stats := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", time.Now(), 1)})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	_, err := client.DownloadDelivery(context.Background(), 3, 7, "", &DownloadOptions{Sink: newMemSink(), SkipExisting: true})
+	if err == nil {
+		t.Fatal("expected SkipExisting with a Sink to be rejected")
+	}
+	if n := atomic.LoadInt32(&stats.downloads); n != 0 {
+		t.Errorf("expected no downloads, got %d", n)
+	}
+}
@@ -0,0 +1,124 @@
+package bdds
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// sqliteSyncSchema creates the tables an SQLiteSyncStore keeps its state in.
+// Timestamps are stored as RFC 3339 text, which every SQLite driver handles
+// the same way.
+var sqliteSyncSchema = []string{`CREATE TABLE IF NOT EXISTS bdds_sync_files (
+	product_id    INTEGER NOT NULL,
+	delivery_id   INTEGER NOT NULL,
+	file_id       INTEGER NOT NULL,
+	file_name     TEXT NOT NULL,
+	checksum      TEXT NOT NULL,
+	size          INTEGER NOT NULL,
+	path          TEXT NOT NULL,
+	downloaded_at TEXT NOT NULL,
+	in_progress   INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (product_id, delivery_id, file_id)
+)`, `CREATE TABLE IF NOT EXISTS bdds_sync_runs (
+	product_id INTEGER PRIMARY KEY,
+	last_run   TEXT NOT NULL
+)`}
+
+// SQLiteSyncStore is a SyncStore in an SQLite database, for mirrors with tens
+// of thousands of files. Every Put is its own transaction, and the records can
+// be queried directly in the bdds_sync_files table (last runs are in
+// bdds_sync_runs).
+//
+// It only uses database/sql, so the caller picks the driver (for example
+// modernc.org/sqlite or github.com/mattn/go-sqlite3) and opens the database.
+type SQLiteSyncStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteSyncStore returns a store in db, creating its tables if needed and
+// adding columns missing from tables of earlier versions.
+func NewSQLiteSyncStore(ctx context.Context, db *sql.DB) (*SQLiteSyncStore, error) {
+	for _, stmt := range sqliteSyncSchema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to create sync state table: %w", err)
+		}
+	}
+	// in_progress was added after the first release of the table.
+	var hasInProgress bool
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) > 0 FROM pragma_table_info('bdds_sync_files') WHERE name = 'in_progress'`,
+	).Scan(&hasInProgress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect sync state table: %w", err)
+	}
+	if !hasInProgress {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE bdds_sync_files ADD COLUMN in_progress INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return nil, fmt.Errorf("failed to upgrade sync state table: %w", err)
+		}
+	}
+	return &SQLiteSyncStore{db: db}, nil
+}
+
+// Get implements SyncStore.
+func (s *SQLiteSyncStore) Get(ctx context.Context, ref FileRef) (*SyncRecord, error) {
+	r := &SyncRecord{ProductID: ref.ProductID, DeliveryID: ref.DeliveryID, FileID: ref.FileID}
+	var downloadedAt string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT file_name, checksum, size, path, downloaded_at, in_progress FROM bdds_sync_files
+		WHERE product_id = ? AND delivery_id = ? AND file_id = ?`,
+		ref.ProductID, ref.DeliveryID, ref.FileID,
+	).Scan(&r.FileName, &r.Checksum, &r.Size, &r.Path, &downloadedAt, &r.InProgress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.DownloadedAt, err = time.Parse(time.RFC3339Nano, downloadedAt); err != nil {
+		return nil, fmt.Errorf("invalid downloaded_at %q: %w", downloadedAt, err)
+	}
+	return r, nil
+}
+
+// Put implements SyncStore.
+func (s *SQLiteSyncStore) Put(ctx context.Context, r *SyncRecord) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO bdds_sync_files
+		(product_id, delivery_id, file_id, file_name, checksum, size, path, downloaded_at, in_progress)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ProductID, r.DeliveryID, r.FileID, r.FileName, r.Checksum, r.Size, r.Path,
+		r.DownloadedAt.UTC().Format(time.RFC3339Nano), r.InProgress,
+	)
+	return err
+}
+
+// LastRun implements SyncStore.
+func (s *SQLiteSyncStore) LastRun(ctx context.Context, productID int) (time.Time, error) {
+	var lastRun string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT last_run FROM bdds_sync_runs WHERE product_id = ?`, productID,
+	).Scan(&lastRun)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, lastRun)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid last_run %q: %w", lastRun, err)
+	}
+	return t, nil
+}
+
+// SetLastRun implements SyncStore.
+func (s *SQLiteSyncStore) SetLastRun(ctx context.Context, productID int, started time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO bdds_sync_runs (product_id, last_run) VALUES (?, ?)`,
+		productID, started.UTC().Format(time.RFC3339Nano),
+	)
+	return err
+}
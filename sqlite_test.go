@@ -0,0 +1,126 @@
+package bdds
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite" // test-only: a pure Go SQLite driver
+)
+
+// openTestSQLite opens a fresh SQLite database file for the test.
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "sync.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestSQLiteSyncStore(t *testing.T) {
+	db := openTestSQLite(t)
+	ctx := context.Background()
+	sqlite, err := NewSQLiteSyncStore(ctx, db)
+	if err != nil {
+		t.Fatalf("NewSQLiteSyncStore: %v", err)
+	}
+	// Opening an existing database again leaves it as is.
+	if _, err := NewSQLiteSyncStore(ctx, db); err != nil {
+		t.Fatalf("NewSQLiteSyncStore on an existing database: %v", err)
+	}
+	var store SyncStore = sqlite
+
+	ref := FileRef{ProductID: 3, DeliveryID: 7, FileID: 701}
+	if r, err := store.Get(ctx, ref); r != nil || err != nil {
+		t.Fatalf("Get before Put = %+v, %v; want nil, nil", r, err)
+	}
+
+	downloaded := time.Date(2024, 10, 15, 10, 30, 0, 123, time.FixedZone("CET", 3600))
+	for _, inProgress := range []bool{true, false} {
+		err := store.Put(ctx, &SyncRecord{
+			ProductID: 3, DeliveryID: 7, FileID: 701,
+			FileName: "20241015_01.zip", Checksum: "ABCDEF", Size: 42,
+			Path: "mirror/3/2024-10-15/20241015_01.zip", DownloadedAt: downloaded,
+			InProgress: inProgress,
+		})
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		if r, err := store.Get(ctx, ref); err != nil || r == nil || r.InProgress != inProgress {
+			t.Fatalf("Get after Put with InProgress %v = %+v, %v", inProgress, r, err)
+		}
+	}
+
+	r, err := store.Get(ctx, ref)
+	if err != nil || r == nil {
+		t.Fatalf("Get = %+v, %v", r, err)
+	}
+	if r.Ref() != ref || r.FileName != "20241015_01.zip" || r.Checksum != "ABCDEF" || r.Size != 42 || r.InProgress ||
+		r.Path != "mirror/3/2024-10-15/20241015_01.zip" || !r.DownloadedAt.Equal(downloaded) {
+		t.Errorf("unexpected record: %+v", r)
+	}
+
+	// The second Put replaced the row, and timestamps are RFC 3339 text in
+	// UTC, so the table can be queried directly.
+	var rows int
+	var downloadedAt string
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*), MAX(downloaded_at) FROM bdds_sync_files`).Scan(&rows, &downloadedAt); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 1 || downloadedAt != "2024-10-15T09:30:00.000000123Z" {
+		t.Errorf("table holds %d rows, downloaded_at %q; want 1 row at 2024-10-15T09:30:00.000000123Z", rows, downloadedAt)
+	}
+
+	if last, err := store.LastRun(ctx, 3); !last.IsZero() || err != nil {
+		t.Fatalf("LastRun before SetLastRun = %v, %v; want zero", last, err)
+	}
+	for _, started := range []time.Time{downloaded.Add(-time.Hour), downloaded} {
+		if err := store.SetLastRun(ctx, 3, started); err != nil {
+			t.Fatalf("SetLastRun: %v", err)
+		}
+	}
+	if last, err := store.LastRun(ctx, 3); !last.Equal(downloaded) || err != nil {
+		t.Errorf("LastRun = %v, %v; want %v", last, err, downloaded)
+	}
+	if last, err := store.LastRun(ctx, 4); !last.IsZero() || err != nil {
+		t.Errorf("LastRun of another product = %v, %v; want zero", last, err)
+	}
+}
+
+// TestSQLiteSyncStoreUpgrade verifies a table created before in_progress
+// existed gets the column, keeping its records.
+func TestSQLiteSyncStoreUpgrade(t *testing.T) {
+	db := openTestSQLite(t)
+	ctx := context.Background()
+	for _, stmt := range []string{`CREATE TABLE bdds_sync_files (
+		product_id    INTEGER NOT NULL,
+		delivery_id   INTEGER NOT NULL,
+		file_id       INTEGER NOT NULL,
+		file_name     TEXT NOT NULL,
+		checksum      TEXT NOT NULL,
+		size          INTEGER NOT NULL,
+		path          TEXT NOT NULL,
+		downloaded_at TEXT NOT NULL,
+		PRIMARY KEY (product_id, delivery_id, file_id)
+	)`, `INSERT INTO bdds_sync_files VALUES (3, 7, 701, 'a.zip', 'ABC', 1, 'a.zip', '2024-10-15T09:30:00Z')`} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := NewSQLiteSyncStore(ctx, db)
+	if err != nil {
+		t.Fatalf("NewSQLiteSyncStore: %v", err)
+	}
+	ref := FileRef{ProductID: 3, DeliveryID: 7, FileID: 701}
+	if r, err := store.Get(ctx, ref); err != nil || r == nil || r.FileName != "a.zip" || r.InProgress {
+		t.Fatalf("Get of a record from before the upgrade = %+v, %v", r, err)
+	}
+	if err := store.Put(ctx, &SyncRecord{ProductID: 3, DeliveryID: 7, FileID: 702, DownloadedAt: time.Now(), InProgress: true}); err != nil {
+		t.Fatalf("Put after the upgrade: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package bdds
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Stats are the download statistics of a client and its clones since the
+// client was created or its statistics were last reset.
+type Stats struct {
+	// FilesDownloaded and FilesFailed count whole-file downloads, by
+	// DownloadFile, DownloadFileToPath, DownloadFileStream,
+	// DownloadFileParallel, DownloadAndExtract and the bulk and sync
+	// functions built on them. A failed download is counted once, after
+	// its retries.
+	FilesDownloaded int64
+	FilesFailed     int64
+	// BytesReceived counts the file content received by all downloads,
+	// including ranged requests and attempts that were retried.
+	BytesReceived int64
+	// DownloadTime is the time spent receiving file content, summed over
+	// concurrent downloads.
+	DownloadTime time.Duration
+	// Retries counts the retried requests of all calls.
+	Retries int64
+}
+
+// Throughput returns the average rate at which file content was received,
+// in bytes per second, or 0 before any was.
+func (s Stats) Throughput() float64 {
+	if s.DownloadTime <= 0 {
+		return 0
+	}
+	return float64(s.BytesReceived) / s.DownloadTime.Seconds()
+}
+
+// Stats returns the download statistics of the client, e.g. to log a
+// summary at the end of a batch run. Clones share them.
+func (c *Client) Stats() Stats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	return c.stats.s
+}
+
+// ResetStats returns the download statistics of the client, as Stats does,
+// and starts counting from zero.
+func (c *Client) ResetStats() Stats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	s := c.stats.s
+	c.stats.s = Stats{}
+	return s
+}
+
+// clientStats collects the Stats of a client.
+type clientStats struct {
+	mu sync.Mutex
+	s  Stats
+}
+
+func (cs *clientStats) update(fn func(s *Stats)) {
+	if cs == nil {
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	fn(&cs.s)
+}
+
+// recordFile counts a whole-file download that ended with err.
+func (cs *clientStats) recordFile(err error) {
+	cs.update(func(s *Stats) {
+		if err != nil {
+			s.FilesFailed++
+		} else {
+			s.FilesDownloaded++
+		}
+	})
+}
+
+func (cs *clientStats) recordRetry() {
+	cs.update(func(s *Stats) { s.Retries++ })
+}
+
+// streamStatsBody counts the file of DownloadFileStream as downloaded once
+// its body was read to the end, and as failed if reading it failed or it was
+// closed before.
+type streamStatsBody struct {
+	io.ReadCloser
+	stats *clientStats
+	once  sync.Once
+}
+
+func (b *streamStatsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	switch {
+	case err == io.EOF:
+		b.once.Do(func() { b.stats.recordFile(nil) })
+	case err != nil:
+		b.once.Do(func() { b.stats.recordFile(err) })
+	}
+	return n, err
+}
+
+func (b *streamStatsBody) Close() error {
+	b.once.Do(func() { b.stats.recordFile(errStreamClosed) })
+	return b.ReadCloser.Close()
+}
+
+// errStreamClosed marks a download stream closed before its end.
+var errStreamClosed = errors.New("download stream closed before its end")
+
+// statsTransport counts the bytes and time of successful download
+// responses.
+type statsTransport struct {
+	base  http.RoundTripper
+	stats *clientStats
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Method == http.MethodGet && downloadRequestPath.MatchString(req.URL.Path) &&
+		resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		resp.Body = &statsBody{ReadCloser: resp.Body, stats: t.stats, start: time.Now()}
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *statsTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// statsBody counts the bytes read from a download body, and the time from
+// its response until it ended or was closed.
+type statsBody struct {
+	io.ReadCloser
+	stats *clientStats
+	start time.Time
+	once  sync.Once
+}
+
+func (b *statsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.stats.update(func(s *Stats) { s.BytesReceived += int64(n) })
+	}
+	if err != nil {
+		b.end()
+	}
+	return n, err
+}
+
+func (b *statsBody) Close() error {
+	b.end()
+	return b.ReadCloser.Close()
+}
+
+func (b *statsBody) end() {
+	b.once.Do(func() {
+		elapsed := time.Since(b.start)
+		b.stats.update(func(s *Stats) { s.DownloadTime += elapsed })
+	})
+}
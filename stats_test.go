@@ -0,0 +1,59 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	var calls int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/file/404/"):
+			http.NotFound(w, r)
+		case atomic.AddInt32(&calls, 1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte("hello"))
+		}
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+	if err := client.DownloadFile(ctx, 3, 7, 9, &bytes.Buffer{}); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if err := client.DownloadFile(ctx, 3, 7, 404, &bytes.Buffer{}); err == nil {
+		t.Fatal("DownloadFile of a missing file succeeded")
+	}
+	clone, err := client.Clone(nil)
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	body, err := clone.DownloadFileStream(ctx, 3, 7, 9)
+	if err != nil {
+		t.Fatalf("DownloadFileStream: %v", err)
+	}
+	_, _ = io.Copy(io.Discard, body)
+	_ = body.Close()
+
+	stats := client.ResetStats()
+	if stats.FilesDownloaded != 2 || stats.FilesFailed != 1 || stats.BytesReceived != 10 || stats.Retries != 1 {
+		t.Errorf("Stats = %+v", stats)
+	}
+	if stats.DownloadTime <= 0 || stats.Throughput() <= 0 {
+		t.Errorf("no download time in %+v", stats)
+	}
+	if stats := clone.Stats(); stats != (Stats{}) {
+		t.Errorf("Stats after reset = %+v", stats)
+	}
+}
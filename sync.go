@@ -0,0 +1,295 @@
+package bdds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyncRecord is the stored state of a file a Sync has downloaded.
+type SyncRecord struct {
+	ProductID    int       `json:"productId"`
+	DeliveryID   int       `json:"deliveryId"`
+	FileID       int       `json:"fileId"`
+	FileName     string    `json:"fileName"`
+	Checksum     string    `json:"checksum,omitempty"` // catalog checksum at download time
+	Size         int64     `json:"size"`
+	Path         string    `json:"path"`
+	DownloadedAt time.Time `json:"downloadedAt"`
+	// InProgress marks a file whose download started but has not completed,
+	// e.g. because the process died; the next run resumes it.
+	InProgress bool `json:"inProgress,omitempty"`
+}
+
+// Ref returns the catalog file the record belongs to.
+func (r *SyncRecord) Ref() FileRef {
+	return FileRef{ProductID: r.ProductID, DeliveryID: r.DeliveryID, FileID: r.FileID}
+}
+
+// SyncStore persists which files a Sync has downloaded. Implementations must
+// be safe for concurrent use.
+type SyncStore interface {
+	// Get returns the record of a file, or nil if there is none.
+	Get(ctx context.Context, ref FileRef) (*SyncRecord, error)
+	// Put adds or replaces the record of a file.
+	Put(ctx context.Context, record *SyncRecord) error
+	// LastRun returns when the last complete run of a product started, or
+	// the zero time if there has been none.
+	LastRun(ctx context.Context, productID int) (time.Time, error)
+	// SetLastRun records the start of a complete run of a product.
+	SetLastRun(ctx context.Context, productID int, started time.Time) error
+}
+
+// MemorySyncStore is a SyncStore held in memory, e.g. for tests or a single
+// long-running process.
+type MemorySyncStore struct {
+	mu       sync.Mutex
+	records  map[FileRef]*SyncRecord
+	lastRuns map[int]time.Time
+}
+
+// NewMemorySyncStore returns an empty MemorySyncStore.
+func NewMemorySyncStore() *MemorySyncStore {
+	return &MemorySyncStore{records: make(map[FileRef]*SyncRecord), lastRuns: make(map[int]time.Time)}
+}
+
+// Get implements SyncStore.
+func (s *MemorySyncStore) Get(_ context.Context, ref FileRef) (*SyncRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[ref]; ok {
+		copied := *r
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// Put implements SyncStore.
+func (s *MemorySyncStore) Put(_ context.Context, record *SyncRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *record
+	s.records[record.Ref()] = &copied
+	return nil
+}
+
+// LastRun implements SyncStore.
+func (s *MemorySyncStore) LastRun(_ context.Context, productID int) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastRuns[productID], nil
+}
+
+// SetLastRun implements SyncStore.
+func (s *MemorySyncStore) SetLastRun(_ context.Context, productID int, started time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRuns[productID] = started
+	return nil
+}
+
+// Records returns all records ordered by product, delivery and file ID.
+func (s *MemorySyncStore) Records() []*SyncRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]*SyncRecord, 0, len(s.records))
+	for _, r := range s.records {
+		copied := *r
+		records = append(records, &copied)
+	}
+	sortSyncRecords(records)
+	return records
+}
+
+// sortSyncRecords orders records by product, delivery and file ID.
+func sortSyncRecords(records []*SyncRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		if a.DeliveryID != b.DeliveryID {
+			return a.DeliveryID < b.DeliveryID
+		}
+		return a.FileID < b.FileID
+	})
+}
+
+// Sync keeps a local mirror of one or more products up to date. Each run
+// looks only at deliveries published since the last complete run and
+// downloads the files its store has no record of, or whose catalog checksum
+// changed since they were downloaded. A file is marked in progress in the
+// store when its download starts and recorded once it has completed, so a run
+// after a crash skips the completed files and resumes the partial ones from
+// their kept ".part" files (see DirSink).
+//
+// A run over several products shares one worker pool, one client (credentials
+// and request limits) and one progress callback; Summarize totals its results
+// per product. Subscribe follows a run as a stream of events, and SetQuota
+// caps the size of the mirror.
+type Sync struct {
+	client *Client
+	store  SyncStore
+	dir    string
+	opts   *DownloadOptions
+	quota  *Quota
+	subs   syncSubscribers
+}
+
+// NewSync returns a Sync that mirrors into dir and keeps its state in store.
+// opts configures the downloads as for the bulk download methods; nil uses
+// the defaults.
+func NewSync(client *Client, store SyncStore, dir string, opts *DownloadOptions) *Sync {
+	return &Sync{client: client, store: store, dir: dir, opts: opts}
+}
+
+// Run synchronizes the given products. Deliveries published before the start
+// of a product's last complete run are skipped; a run is complete when all of
+// its files succeeded. Files are stored as
+// dir/<product ID>/<delivery name>/<file name>, as by DownloadFiles, unless
+// opts.Layout says otherwise, and
+// opts.DeliveryFilter selects the deliveries considered (widening the filter
+// later needs RunSince to reach older deliveries). It returns the
+// results of the files it downloaded, in catalog order; the error is the join
+// of the per-file errors, including failures to update the store. A dry run
+// (opts.DryRun) leaves the store unchanged.
+func (s *Sync) Run(ctx context.Context, productIDs ...int) ([]*FileResult, error) {
+	return s.run(ctx, time.Time{}, false, productIDs)
+}
+
+// RunSince is Run considering the deliveries published at or after since
+// instead of those since the last complete run. A zero since considers all
+// deliveries. The run is only recorded as the last complete run if it covered
+// at least the period since the previous one.
+func (s *Sync) RunSince(ctx context.Context, since time.Time, productIDs ...int) ([]*FileResult, error) {
+	return s.run(ctx, since, true, productIDs)
+}
+
+func (s *Sync) run(ctx context.Context, since time.Time, explicit bool, productIDs []int) (results []*FileResult, err error) {
+	defer func() {
+		s.emit(SyncEvent{Type: RunCompleted, Results: results, Err: err})
+	}()
+	started := time.Now()
+	var opts DownloadOptions
+	if s.opts != nil {
+		opts = *s.opts
+	}
+
+	// complete lists the products whose run, if it succeeds, becomes their
+	// last complete run.
+	complete := make(map[int]bool)
+	var products []*ProductWithDeliveries
+	var jobs []*FileResult
+	for _, productID := range productIDs {
+		lastRun, err := s.store.LastRun(ctx, productID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sync state: %w", err)
+		}
+		cutoff := lastRun
+		complete[productID] = true
+		if explicit {
+			cutoff, complete[productID] = since, !since.After(lastRun)
+		}
+
+		product, err := s.client.GetProduct(ctx, productID, WithNoCache())
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+		for _, d := range product.Deliveries {
+			if d.DeliveryPublicationDatetime.Before(cutoff) {
+				continue
+			}
+			if opts.DeliveryFilter != nil && !opts.DeliveryFilter(d) {
+				continue
+			}
+			pending := len(jobs)
+			for _, job := range deliveryJobs(productID, product.Name, d, s.dir, filesLayout, &opts) {
+				record, err := s.store.Get(ctx, FileRef{ProductID: productID, DeliveryID: d.DeliveryID, FileID: job.File.FileID})
+				if err != nil {
+					return nil, fmt.Errorf("failed to read sync state: %w", err)
+				}
+				if record != nil && !record.InProgress && strings.EqualFold(record.Checksum, job.File.FileChecksum) {
+					continue
+				}
+				jobs = append(jobs, job)
+			}
+			if len(jobs) > pending {
+				s.emit(SyncEvent{Type: DeliveryDiscovered, ProductID: productID, Delivery: d})
+			}
+		}
+	}
+	if err := preflight(s.dir, jobs, &opts); err != nil {
+		return nil, err
+	}
+	if s.quota != nil {
+		if err := s.enforceQuota(products, jobs, opts.DryRun); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record files even if ctx is cancelled right after they completed.
+	storeCtx := context.WithoutCancel(ctx)
+	record := func(r *FileResult) *SyncRecord {
+		return &SyncRecord{
+			ProductID:  r.ProductID,
+			DeliveryID: r.DeliveryID,
+			FileID:     r.File.FileID,
+			FileName:   r.File.FileName,
+			Checksum:   r.File.FileChecksum,
+			Path:       r.Path,
+		}
+	}
+	userStart := opts.OnFileStart
+	opts.OnFileStart = func(r *FileResult) {
+		// The marker is informational; a failure to store it does not stop
+		// the download, and the completed record replaces it.
+		started := record(r)
+		started.InProgress = true
+		_ = s.store.Put(storeCtx, started)
+		s.emit(SyncEvent{Type: FileStarted, ProductID: r.ProductID, File: r})
+		if userStart != nil {
+			userStart(r)
+		}
+	}
+	userComplete := opts.OnFileComplete
+	opts.OnFileComplete = func(r *FileResult) {
+		if r.Err == nil && !r.Planned {
+			done := record(r)
+			done.Size, done.DownloadedAt = r.Bytes, r.CompletedAt
+			if r.Skipped {
+				done.Size = parseFileSize(r.File.FileSize)
+			}
+			if err := s.store.Put(storeCtx, done); err != nil {
+				r.Err = fmt.Errorf("failed to record sync state: %w", err)
+			}
+		}
+		if r.Err != nil {
+			s.emit(SyncEvent{Type: FileFailed, ProductID: r.ProductID, File: r, Err: r.Err})
+		} else {
+			s.emit(SyncEvent{Type: FileCompleted, ProductID: r.ProductID, File: r})
+		}
+		if userComplete != nil {
+			userComplete(r)
+		}
+	}
+
+	errs := []error{s.client.downloadBatch(ctx, jobs, &opts), writeRequestedManifest(jobs, &opts)}
+	for _, job := range jobs {
+		if job.Err != nil {
+			complete[job.ProductID] = false
+		}
+	}
+	for _, productID := range productIDs {
+		if complete[productID] && ctx.Err() == nil && !opts.DryRun {
+			if err := s.store.SetLastRun(storeCtx, productID, started); err != nil {
+				errs = append(errs, fmt.Errorf("failed to record sync state: %w", err))
+			}
+		}
+	}
+	return jobs, errors.Join(errs...)
+}
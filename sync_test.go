@@ -0,0 +1,289 @@
+package bdds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSync verifies a Sync downloads only files it has no record of, records
+// them, and re-downloads a file whose catalog checksum changed.
+func TestSync(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, stats := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", published, 2),
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 1),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	store := NewMemorySyncStore()
+	dir := t.TempDir()
+
+	// A dry run plans everything and records nothing.
+	results, err := NewSync(client, store, dir, &DownloadOptions{DryRun: true}).Run(context.Background(), 3)
+	if err != nil || len(results) != 3 || !results[0].Planned {
+		t.Fatalf("dry run = %v, %v; want 3 planned files", results, err)
+	}
+	if len(store.Records()) != 0 || atomic.LoadInt32(&stats.downloads) != 0 {
+		t.Fatal("dry run downloaded or recorded files")
+	}
+	if last, _ := store.LastRun(context.Background(), 3); !last.IsZero() {
+		t.Errorf("dry run recorded a last run: %v", last)
+	}
+
+	// First run: only the older delivery is published yet.
+	first := NewSync(client, store, dir, &DownloadOptions{
+		DeliveryFilter: func(d *Delivery) bool { return d.DeliveryID == 7 },
+	})
+	results, err = first.Run(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("first Run: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("first run downloaded %d files, want 2", len(results))
+	}
+
+	// Second run: only the new delivery is downloaded. RunSince looks at
+	// all deliveries; the records alone keep delivery 7 from repeating.
+	s := NewSync(client, store, dir, nil)
+	results, err = s.RunSince(context.Background(), time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if len(results) != 1 || results[0].File.FileID != 801 {
+		t.Fatalf("second run downloaded %v, want only file 801", results)
+	}
+	if n := atomic.LoadInt32(&stats.downloads); n != 3 {
+		t.Errorf("expected 3 downloads in total, got %d", n)
+	}
+
+	records := store.Records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	r := records[2]
+	wantPath := filepath.Join(dir, "3", "2024-10-22", "20241022_01.zip")
+	if r.FileID != 801 || r.Path != wantPath || r.Size != int64(len("delivery 8 file 1")) ||
+		r.Checksum != sha1Hex("delivery 8 file 1") || r.DownloadedAt.IsZero() {
+		t.Errorf("unexpected record: %+v", r)
+	}
+	if _, err := os.Stat(wantPath); err != nil {
+		t.Errorf("synced file missing: %v", err)
+	}
+
+	// A record whose checksum no longer matches the catalog is re-downloaded.
+	stale := *records[0]
+	stale.Checksum = "0000"
+	if err := store.Put(context.Background(), &stale); err != nil {
+		t.Fatal(err)
+	}
+	results, err = s.RunSince(context.Background(), time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("third Run: %v", err)
+	}
+	if len(results) != 1 || results[0].File.FileID != stale.FileID {
+		t.Errorf("third run downloaded %v, want only file %d", results, stale.FileID)
+	}
+	if got, _ := store.Get(context.Background(), stale.Ref()); got == nil || got.Checksum != sha1Hex("delivery 7 file 1") {
+		t.Errorf("record not refreshed: %+v", got)
+	}
+}
+
+// TestSyncSinceLastRun verifies a run only considers deliveries published
+// since the last complete run, and that an explicit since overrides it.
+func TestSyncSinceLastRun(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", published, 2),
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 1),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+
+	// The state says the product was complete as of 2024-10-20, although
+	// none of its files are recorded (e.g. they were pruned).
+	store := NewMemorySyncStore()
+	lastRun := published.AddDate(0, 0, 5)
+	if err := store.SetLastRun(ctx, 3, lastRun); err != nil {
+		t.Fatal(err)
+	}
+	s := NewSync(client, store, t.TempDir(), nil)
+
+	before := time.Now()
+	results, err := s.Run(ctx, 3)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 1 || results[0].DeliveryID != 8 {
+		t.Fatalf("Run downloaded %v, want only delivery 8", results)
+	}
+	if last, _ := store.LastRun(ctx, 3); last.Before(before) {
+		t.Errorf("LastRun = %v, want the start of the run", last)
+	}
+
+	// An explicit since reaching back further covers the gap and keeps the
+	// run complete; one leaving a gap is not recorded.
+	if err := store.SetLastRun(ctx, 3, lastRun); err != nil {
+		t.Fatal(err)
+	}
+	results, err = s.RunSince(ctx, time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("RunSince: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("RunSince(zero) downloaded %d files, want the 2 of delivery 7", len(results))
+	}
+	if last, _ := store.LastRun(ctx, 3); !last.After(lastRun) {
+		t.Errorf("RunSince(zero) not recorded as complete: LastRun = %v", last)
+	}
+
+	recorded, _ := store.LastRun(ctx, 3)
+	if _, err := s.RunSince(ctx, recorded.Add(time.Hour), 3); err != nil {
+		t.Fatalf("RunSince: %v", err)
+	}
+	if last, _ := store.LastRun(ctx, 3); !last.Equal(recorded) {
+		t.Errorf("RunSince leaving a gap was recorded: LastRun = %v, want %v", last, recorded)
+	}
+}
+
+// TestSyncMultipleProducts verifies one run covers several products with
+// combined progress and a per-product summary.
+func TestSyncMultipleProducts(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	docdb, _ := newMirrorServer(t, 3, []mirrorDelivery{weeklyDelivery(7, "2024-10-15", published, 2)})
+	defer docdb.Close()
+	fulltext, _ := newMirrorServer(t, 4, []mirrorDelivery{weeklyDelivery(9, "2024-10-16", published, 3)})
+	defer fulltext.Close()
+	byProduct := regexp.MustCompile(`/products/(\d+)`)
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m := byProduct.FindStringSubmatch(r.URL.Path); m != nil && m[1] == "4" {
+			fulltext.Config.Handler.ServeHTTP(w, r)
+			return
+		}
+		docdb.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+
+	var mu sync.Mutex
+	var lastWritten, lastTotal int64
+	s := NewSync(client, NewMemorySyncStore(), t.TempDir(), &DownloadOptions{
+		Progress: func(written, total int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			lastWritten, lastTotal = written, total
+		},
+	})
+	results, err := s.Run(context.Background(), 3, 4)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	summaries := Summarize(results)
+	if len(summaries) != 2 || summaries[0].ProductID != 3 || summaries[0].Downloaded != 2 ||
+		summaries[1].ProductID != 4 || summaries[1].Downloaded != 3 {
+		t.Errorf("unexpected summaries: %+v", summaries)
+	}
+	var total int64
+	for _, r := range results {
+		total += r.Bytes
+	}
+	if lastWritten != total || lastTotal != total {
+		t.Errorf("final progress = %d/%d, want %d", lastWritten, lastTotal, total)
+	}
+}
+
+// TestSyncResumesAfterCrash verifies a file whose download was interrupted is
+// left marked in progress with its partial content, and that the next run
+// resumes it with a Range request instead of starting over.
+func TestSyncResumesAfterCrash(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	d := weeklyDelivery(7, "2024-10-15", published, 2)
+	d.files[1].content = strings.Repeat("x", 1000)
+	mirror, _ := newMirrorServer(t, 3, []mirrorDelivery{d})
+	defer mirror.Close()
+
+	var crashed atomic.Bool
+	crashed.Store(true)
+	var mu sync.Mutex
+	var ranges []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/file/702/download") {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+			if crashed.Load() {
+				// Send the first 400 bytes, then drop the connection.
+				conn, buf, _ := w.(http.Hijacker).Hijack()
+				_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nAccept-Ranges: bytes\r\nETag: \"702\"\r\nContent-Length: 1000\r\n\r\n")
+				_, _ = buf.WriteString(strings.Repeat("x", 400))
+				_ = buf.Flush()
+				_ = conn.Close()
+				return
+			}
+		}
+		mirror.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	store := NewMemorySyncStore()
+	dir := t.TempDir()
+	s := NewSync(client, store, dir, nil)
+
+	if _, err := s.Run(context.Background(), 3); err == nil {
+		t.Fatal("expected the interrupted file to fail")
+	}
+	ref := FileRef{ProductID: 3, DeliveryID: 7, FileID: 702}
+	if r, _ := store.Get(context.Background(), ref); r == nil || !r.InProgress {
+		t.Fatalf("interrupted file not marked in progress: %+v", r)
+	}
+	path := filepath.Join(dir, "3", "2024-10-15", "20241015_02.zip")
+	if info, err := os.Stat(path + partialSuffix); err != nil || info.Size() != 400 {
+		t.Fatalf("partial file not kept: %v", err)
+	}
+	if last, _ := store.LastRun(context.Background(), 3); !last.IsZero() {
+		t.Errorf("failed run recorded as complete")
+	}
+
+	crashed.Store(false)
+	mu.Lock()
+	ranges = nil
+	mu.Unlock()
+	results, err := s.Run(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if len(results) != 1 || results[0].File.FileID != 702 {
+		t.Fatalf("second run downloaded %v, want only the interrupted file", results)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=400-" {
+		t.Errorf("expected one request resuming at byte 400, got %q", ranges)
+	}
+	if got, _ := os.ReadFile(path); string(got) != strings.Repeat("x", 1000) {
+		t.Errorf("resumed file has %d bytes", len(got))
+	}
+	if r, _ := store.Get(context.Background(), ref); r == nil || r.InProgress || r.Size != 1000 {
+		t.Errorf("resumed file not recorded as complete: %+v", r)
+	}
+}
@@ -0,0 +1,98 @@
+package bdds
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SyncEventType is the kind of a SyncEvent.
+type SyncEventType int
+
+const (
+	// DeliveryDiscovered: a delivery with files to fetch was found. Sent
+	// before the run's downloads start.
+	DeliveryDiscovered SyncEventType = iota + 1
+	// FileStarted: a file download is starting.
+	FileStarted
+	// FileCompleted: a file was downloaded (or skipped or planned) and
+	// recorded in the store.
+	FileCompleted
+	// FileFailed: a file could not be downloaded or recorded.
+	FileFailed
+	// RunCompleted: the run is over; sent once per run, also when it failed
+	// before downloading anything.
+	RunCompleted
+	// DeliveryEvicted: an expired delivery was removed to stay within the
+	// quota (see Sync.SetQuota).
+	DeliveryEvicted
+)
+
+func (t SyncEventType) String() string {
+	switch t {
+	case DeliveryDiscovered:
+		return "delivery discovered"
+	case FileStarted:
+		return "file started"
+	case FileCompleted:
+		return "file completed"
+	case FileFailed:
+		return "file failed"
+	case RunCompleted:
+		return "run completed"
+	case DeliveryEvicted:
+		return "delivery evicted"
+	}
+	return fmt.Sprintf("SyncEventType(%d)", int(t))
+}
+
+// SyncEvent is a step of a Sync run, sent to the functions registered with
+// Sync.Subscribe.
+type SyncEvent struct {
+	Type      SyncEventType
+	ProductID int           // zero for RunCompleted
+	Delivery  *Delivery     // DeliveryDiscovered, DeliveryEvicted
+	File      *FileResult   // FileStarted, FileCompleted, FileFailed
+	Results   []*FileResult // RunCompleted
+	Err       error         // FileFailed, RunCompleted
+}
+
+// syncSubscribers is the callback registry of a Sync.
+type syncSubscribers struct {
+	mu   sync.Mutex
+	next int
+	fns  map[int]func(SyncEvent)
+}
+
+// Subscribe registers fn to receive the events of all later runs and
+// returns a function that unregisters it. fn is called from the download
+// workers, so it may run concurrently with itself and should return quickly;
+// forward to a buffered channel for slow consumers. Subscribing is safe
+// while a run is in progress.
+func (s *Sync) Subscribe(fn func(SyncEvent)) (unsubscribe func()) {
+	s.subs.mu.Lock()
+	defer s.subs.mu.Unlock()
+	if s.subs.fns == nil {
+		s.subs.fns = make(map[int]func(SyncEvent))
+	}
+	id := s.subs.next
+	s.subs.next++
+	s.subs.fns[id] = fn
+	return func() {
+		s.subs.mu.Lock()
+		defer s.subs.mu.Unlock()
+		delete(s.subs.fns, id)
+	}
+}
+
+// emit sends e to the current subscribers.
+func (s *Sync) emit(e SyncEvent) {
+	s.subs.mu.Lock()
+	fns := make([]func(SyncEvent), 0, len(s.subs.fns))
+	for _, fn := range s.subs.fns {
+		fns = append(fns, fn)
+	}
+	s.subs.mu.Unlock()
+	for _, fn := range fns {
+		fn(e)
+	}
+}
@@ -0,0 +1,82 @@
+package bdds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncEvents(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	published := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	mirror, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", published, 2),
+		weeklyDelivery(8, "2024-10-22", published.AddDate(0, 0, 7), 1),
+	})
+	defer mirror.Close()
+	// File 702 is missing on the server.
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/file/702/download") {
+			http.NotFound(w, r)
+			return
+		}
+		mirror.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	s := NewSync(client, NewMemorySyncStore(), t.TempDir(), nil)
+
+	var mu sync.Mutex
+	var events []SyncEvent
+	unsubscribe := s.Subscribe(func(e SyncEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	if _, err := s.Run(context.Background(), 3); err == nil {
+		t.Fatal("expected the missing file to fail")
+	}
+	counts := make(map[SyncEventType]int)
+	for _, e := range events {
+		counts[e.Type]++
+		switch e.Type {
+		case DeliveryDiscovered:
+			if e.ProductID != 3 || e.Delivery == nil {
+				t.Errorf("incomplete %s event: %+v", e.Type, e)
+			}
+		case FileFailed:
+			if e.File.File.FileID != 702 || e.Err == nil {
+				t.Errorf("unexpected %s event: %+v", e.Type, e)
+			}
+		}
+	}
+	want := map[SyncEventType]int{DeliveryDiscovered: 2, FileStarted: 3, FileCompleted: 2, FileFailed: 1, RunCompleted: 1}
+	for typ, n := range want {
+		if counts[typ] != n {
+			t.Errorf("got %d %s events, want %d", counts[typ], typ, n)
+		}
+	}
+	if first := events[0]; first.Type != DeliveryDiscovered {
+		t.Errorf("first event = %s, want %s", first.Type, DeliveryDiscovered)
+	}
+	last := events[len(events)-1]
+	if last.Type != RunCompleted || len(last.Results) != 3 || last.Err == nil {
+		t.Errorf("last event = %+v, want a failed run with 3 results", last)
+	}
+
+	unsubscribe()
+	events = nil
+	if _, err := s.Run(context.Background(), 3); err == nil {
+		t.Fatal("expected the missing file to fail again")
+	}
+	if len(events) != 0 {
+		t.Errorf("got %d events after unsubscribing", len(events))
+	}
+}
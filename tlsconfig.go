@@ -0,0 +1,91 @@
+package bdds
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions configure TLS for all requests of the client, e.g. to trust a
+// TLS-intercepting proxy or to meet a security baseline.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of certificates trusted in addition to the
+	// system roots, such as the CA of an intercepting proxy.
+	CAFile string
+	// RootCAs, if set, replaces the system roots.
+	RootCAs *x509.CertPool
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13
+	// (default: TLS 1.2).
+	MinVersion uint16
+	// Pins maps host names, e.g. "publication-bdds.apps.epo.org" and
+	// "login.epo.org", to the SHA-256 hashes of subject public key infos
+	// one of which the verified chain of the host must contain, each
+	// base64-encoded with an optional "sha256/" prefix.
+	Pins map[string][]string
+}
+
+// tlsConfig builds the TLS configuration of the default transport.
+func (o *TLSOptions) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.MinVersion != 0 {
+		cfg.MinVersion = o.MinVersion
+	}
+	cfg.RootCAs = o.RootCAs
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		if cfg.RootCAs == nil {
+			if cfg.RootCAs, err = x509.SystemCertPool(); err != nil {
+				cfg.RootCAs = x509.NewCertPool()
+			}
+		} else {
+			cfg.RootCAs = cfg.RootCAs.Clone()
+		}
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %s contains no PEM certificates", o.CAFile)
+		}
+	}
+
+	if len(o.Pins) > 0 {
+		pins := make(map[string]map[string]bool, len(o.Pins))
+		for host, hashes := range o.Pins {
+			set := make(map[string]bool, len(hashes))
+			for _, h := range hashes {
+				h = strings.TrimPrefix(h, "sha256/")
+				if b, err := base64.StdEncoding.DecodeString(h); err != nil || len(b) != sha256.Size {
+					return nil, fmt.Errorf("invalid pin %q for %s: want a base64 SHA-256 hash", h, host)
+				}
+				set[h] = true
+			}
+			pins[strings.ToLower(host)] = set
+		}
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			set, ok := pins[strings.ToLower(cs.ServerName)]
+			if !ok {
+				return nil
+			}
+			for _, chain := range cs.VerifiedChains {
+				for _, cert := range chain {
+					sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+					if set[base64.StdEncoding.EncodeToString(sum[:])] {
+						return nil
+					}
+				}
+			}
+			return &CertificatePinError{Host: cs.ServerName}
+		}
+	}
+	return cfg, nil
+}
+
+// SPKIPin returns the pin of a certificate for TLSOptions.Pins.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
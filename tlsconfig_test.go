@@ -0,0 +1,107 @@
+package bdds
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTLSAPIServer returns a TLS server answering product listings, and a
+// PEM file with its certificate.
+func newTLSAPIServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+	}))
+	t.Cleanup(srv.Close)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return srv, caFile
+}
+
+func TestTLSOptions(t *testing.T) {
+	srv, caFile := newTLSAPIServer(t)
+	ctx := context.Background()
+
+	listWith := func(baseURL string, opts *TLSOptions) error {
+		client, err := NewClient(&Config{BaseURL: baseURL, TLS: opts, MaxRetries: 2, RetryDelay: 1})
+		if err != nil {
+			return err
+		}
+		_, err = client.ListProducts(ctx)
+		return err
+	}
+
+	if err := listWith(srv.URL, nil); err == nil {
+		t.Error("untrusted certificate accepted without CAFile")
+	}
+	if err := listWith(srv.URL, &TLSOptions{CAFile: caFile}); err != nil {
+		t.Errorf("CAFile: %v", err)
+	}
+
+	tls12 := httptest.NewUnstartedServer(srv.Config.Handler)
+	tls12.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	tls12.StartTLS()
+	defer tls12.Close()
+	roots := tls12.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	if err := listWith(tls12.URL, &TLSOptions{RootCAs: roots}); err != nil {
+		t.Errorf("RootCAs: %v", err)
+	}
+	if err := listWith(tls12.URL, &TLSOptions{RootCAs: roots, MinVersion: tls.VersionTLS13}); err == nil {
+		t.Error("TLS 1.2 server accepted with MinVersion TLS 1.3")
+	}
+}
+
+func TestCertificatePins(t *testing.T) {
+	srv, _ := newTLSAPIServer(t)
+	cert := srv.Certificate()
+	opts := &TLSOptions{Pins: map[string][]string{
+		"login.epo.org":                 {"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", SPKIPin(cert)},
+		"publication-bdds.apps.epo.org": {"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+	}}
+	cfg, err := opts.tlsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := func(host string) tls.ConnectionState {
+		return tls.ConnectionState{ServerName: host, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	if err := cfg.VerifyConnection(state("LOGIN.epo.org")); err != nil {
+		t.Errorf("matching pin rejected: %v", err)
+	}
+	err = cfg.VerifyConnection(state("publication-bdds.apps.epo.org"))
+	var pinErr *CertificatePinError
+	if !errors.As(err, &pinErr) || pinErr.Host != "publication-bdds.apps.epo.org" {
+		t.Errorf("mismatching pin: %v, want a CertificatePinError", err)
+	}
+	if err := cfg.VerifyConnection(state("other.example.com")); err != nil {
+		t.Errorf("host without pins rejected: %v", err)
+	}
+	if retry, _ := (&Client{}).classifyRetry(err); retry {
+		t.Error("pin mismatch classified as retryable")
+	}
+}
+
+func TestTLSOptionsInvalid(t *testing.T) {
+	for name, opts := range map[string]*TLSOptions{
+		"missing CA file": {CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"bad pin":         {Pins: map[string][]string{"login.epo.org": {"sha256/not-base64"}}},
+		"short pin":       {Pins: map[string][]string{"login.epo.org": {"c2hvcnQ="}}},
+	} {
+		if _, err := NewClient(&Config{TLS: opts}); err == nil {
+			t.Errorf("%s: NewClient accepted invalid TLS options", name)
+		}
+	}
+}
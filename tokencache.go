@@ -0,0 +1,201 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CachedToken is an OAuth access token kept in a TokenCache.
+type CachedToken struct {
+	AccessToken string    `json:"accessToken"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// TokenCache keeps OAuth tokens between processes, so short-lived commands
+// and parallel cron jobs reuse a token instead of each performing a password
+// grant, which EPO rate-limits. key identifies the account; implementations
+// return nil when they hold no token for it. The client treats the cache as
+// best effort: failures fall back to authenticating.
+type TokenCache interface {
+	Load(ctx context.Context, key string) (*CachedToken, error)
+	Store(ctx context.Context, key string, token *CachedToken) error
+}
+
+// tokenCacheKey identifies an account of a token endpoint in a TokenCache
+// without exposing the user name.
+func tokenCacheKey(tokenURL, username string) string {
+	sum := sha256.Sum256([]byte("bdds-token:" + tokenURL + "\x00" + username))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCachedToken loads a usable token for an account from
+// Config.TokenCache. The caller must hold the account's mutex.
+func (c *Client) loadCachedToken(ctx context.Context, a *account) bool {
+	cached, err := c.config.TokenCache.Load(ctx, tokenCacheKey(c.config.TokenURL, a.username))
+	if err != nil || cached == nil || cached.AccessToken == a.rejectedToken ||
+		!time.Now().Add(tokenRefreshBuffer).Before(cached.Expiry) {
+		return false
+	}
+	a.token, a.tokenIssued, a.tokenExpiry = cached.AccessToken, time.Now(), cached.Expiry
+	c.wakeTokenRefresh()
+	return true
+}
+
+// FileTokenCache is a TokenCache in a single file, encrypted with AES-256-GCM
+// under a key derived from a secret shared by every process using the cache.
+// Use a random secret, e.g. from a secrets manager, rather than the account
+// password: a weak secret can be guessed offline from the file. The key is
+// derived with PBKDF2 and a random salt kept in the file, so guessing is
+// slow and cannot be precomputed. The file is created with mode 0600 and
+// replaced atomically; a file that cannot be decrypted, say after the secret
+// changed, is treated as empty and overwritten.
+type FileTokenCache struct {
+	path   string
+	secret string
+	mu     sync.Mutex
+	salt   []byte      // salt aead was derived with
+	aead   cipher.AEAD // nil until derived
+}
+
+const (
+	// tokenCacheVersion is the first byte of a token cache file, followed by
+	// the salt, the nonce and the sealed tokens.
+	tokenCacheVersion = 1
+	tokenCacheSaltLen = 16
+)
+
+// tokenCacheIterations is the PBKDF2 iteration count for FileTokenCache keys.
+var tokenCacheIterations = 600_000
+
+// NewFileTokenCache returns a cache stored at path and encrypted with a key
+// derived from secret, which should be random; see FileTokenCache.
+func NewFileTokenCache(path, secret string) (*FileTokenCache, error) {
+	if secret == "" {
+		return nil, errors.New("token cache secret must not be empty")
+	}
+	return &FileTokenCache{path: path, secret: secret}, nil
+}
+
+// cipherLocked returns the cipher for salt, deriving its key unless it is
+// the salt of the last one.
+func (f *FileTokenCache) cipherLocked(salt []byte) (cipher.AEAD, error) {
+	if f.aead != nil && bytes.Equal(salt, f.salt) {
+		return f.aead, nil
+	}
+	key, err := pbkdf2.Key(sha256.New, f.secret, salt, tokenCacheIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	f.salt, f.aead = salt, aead
+	return aead, nil
+}
+
+// Load implements TokenCache.
+func (f *FileTokenCache) Load(_ context.Context, key string) (*CachedToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokens, err := f.readLocked()
+	if err != nil {
+		return nil, err
+	}
+	return tokens[key], nil
+}
+
+// Store implements TokenCache.
+func (f *FileTokenCache) Store(_ context.Context, key string, token *CachedToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tokens, err := f.readLocked()
+	if err != nil {
+		tokens = nil // unreadable: start over
+	}
+	if tokens == nil {
+		tokens = make(map[string]*CachedToken)
+	}
+	now := time.Now()
+	for k, t := range tokens {
+		if !t.Expiry.After(now) {
+			delete(tokens, k)
+		}
+	}
+	tokens[key] = token
+
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	// Keep the salt of the file read, or of the last one written.
+	salt := f.salt
+	if f.aead == nil {
+		salt = make([]byte, tokenCacheSaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+	}
+	aead, err := f.cipherLocked(salt)
+	if err != nil {
+		return err
+	}
+	data := append([]byte{tokenCacheVersion}, salt...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data = append(data, nonce...)
+	if err := writeFileAtomic(f.path, aead.Seal(data, nonce, plain, data[:1+len(salt)])); err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	return nil
+}
+
+// readLocked decrypts the cache file; a missing file is an empty cache.
+func (f *FileTokenCache) readLocked() (map[string]*CachedToken, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token cache: %w", err)
+	}
+	header := 1 + tokenCacheSaltLen
+	if len(data) < header || data[0] != tokenCacheVersion {
+		return nil, fmt.Errorf("token cache %s is corrupt", f.path)
+	}
+	aead, err := f.cipherLocked(bytes.Clone(data[1:header]))
+	if err != nil {
+		return nil, err
+	}
+	n := aead.NonceSize()
+	if len(data) < header+n {
+		return nil, fmt.Errorf("token cache %s is corrupt", f.path)
+	}
+	plain, err := aead.Open(nil, data[header:header+n], data[header+n:], data[:header])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token cache %s: %w", f.path, err)
+	}
+	var tokens map[string]*CachedToken
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token cache %s: %w", f.path, err)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,187 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastTokenCacheKeys lowers the PBKDF2 iterations of FileTokenCache keys
+// for the test, which derives many of them.
+func fastTokenCacheKeys(t *testing.T) {
+	iterations := tokenCacheIterations
+	tokenCacheIterations = 1000
+	t.Cleanup(func() { tokenCacheIterations = iterations })
+}
+
+func TestFileTokenCacheSharedAcrossClients(t *testing.T) {
+	fastTokenCacheKeys(t)
+	authServer, authCalls := newAuthServer(3600)
+	defer authServer.Close()
+	var mu sync.Mutex
+	revoked := map[string]bool{}
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if revoked[r.Header.Get("Authorization")] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "x", "description": "y"}})
+	}))
+	defer apiServer.Close()
+
+	path := filepath.Join(t.TempDir(), "token.cache")
+	newClient := func(secret string) *Client {
+		cache, err := NewFileTokenCache(path, secret)
+		if err != nil {
+			t.Fatalf("NewFileTokenCache: %v", err)
+		}
+		client := newTestClient(t, apiServer.URL, authServer.URL)
+		client.config.TokenCache = cache
+		return client
+	}
+	ctx := context.Background()
+
+	first := newClient("p")
+	if _, err := first.ListProducts(ctx); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if _, err := newClient("p").ListProducts(ctx); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if n := atomic.LoadInt32(authCalls); n != 1 {
+		t.Errorf("expected one password grant for two clients, got %d", n)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(first.account.token)) {
+		t.Error("token stored in plain text")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// A cache written under another secret is ignored and replaced.
+	if _, err := newClient("other").ListProducts(ctx); err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if n := atomic.LoadInt32(authCalls); n != 2 {
+		t.Errorf("expected a password grant for an unreadable cache, got %d grants", n)
+	}
+
+	// A revoked cached token is replaced, not reloaded.
+	third := newClient("other")
+	cached, err := third.config.TokenCache.Load(ctx, tokenCacheKey(third.config.TokenURL, "u"))
+	if err != nil || cached == nil {
+		t.Fatalf("Load = %v, %v", cached, err)
+	}
+	mu.Lock()
+	revoked["Bearer "+cached.AccessToken] = true
+	mu.Unlock()
+	if _, err := third.ListProducts(ctx); err != nil {
+		t.Fatalf("ListProducts with a revoked cached token: %v", err)
+	}
+	if third.account.token == cached.AccessToken {
+		t.Error("client kept the revoked token")
+	}
+	if n := atomic.LoadInt32(authCalls); n != 3 {
+		t.Errorf("expected one more password grant after revocation, got %d grants", n)
+	}
+}
+
+func TestFileTokenCacheDropsExpired(t *testing.T) {
+	fastTokenCacheKeys(t)
+	cache, err := NewFileTokenCache(filepath.Join(t.TempDir(), "token.cache"), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if got, err := cache.Load(ctx, "a"); got != nil || err != nil {
+		t.Fatalf("Load from a missing file = %v, %v", got, err)
+	}
+	if err := cache.Store(ctx, "a", &CachedToken{AccessToken: "old", Expiry: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Store(ctx, "b", &CachedToken{AccessToken: "new", Expiry: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cache.Load(ctx, "a"); got != nil {
+		t.Errorf("expired token kept: %+v", got)
+	}
+	if got, _ := cache.Load(ctx, "b"); got == nil || got.AccessToken != "new" {
+		t.Errorf("Load(b) = %+v", got)
+	}
+	if _, err := NewFileTokenCache("x", ""); err == nil {
+		t.Error("expected an error for an empty secret")
+	}
+}
+
+// TestFileTokenCacheSalted verifies each cache file gets a random salt for
+// its key, and a file in another format is treated as empty.
+func TestFileTokenCacheSalted(t *testing.T) {
+	fastTokenCacheKeys(t)
+	dir := t.TempDir()
+	ctx := context.Background()
+	token := &CachedToken{AccessToken: "t", Expiry: time.Now().Add(time.Hour)}
+
+	var salts [][]byte
+	for _, name := range []string{"a", "b"} {
+		path := filepath.Join(dir, name)
+		cache, err := NewFileTokenCache(path, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Store(ctx, "k", token); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(path)
+		if len(data) < 1+tokenCacheSaltLen || data[0] != tokenCacheVersion {
+			t.Fatalf("cache file %s has no header: %x", name, data)
+		}
+		salts = append(salts, data[1:1+tokenCacheSaltLen])
+
+		// A second Store keeps the salt; another process reads the file.
+		if err := cache.Store(ctx, "k2", token); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); !bytes.Equal(data[1:1+tokenCacheSaltLen], salts[len(salts)-1]) {
+			t.Errorf("cache file %s changed its salt", name)
+		}
+		other, _ := NewFileTokenCache(path, "secret")
+		if got, err := other.Load(ctx, "k"); err != nil || got == nil || got.AccessToken != "t" {
+			t.Errorf("Load = %+v, %v", got, err)
+		}
+	}
+	if bytes.Equal(salts[0], salts[1]) {
+		t.Error("two cache files share a salt")
+	}
+
+	// A file from before salting cannot be read, and is replaced.
+	path := filepath.Join(dir, "legacy")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0xaa}, 64), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cache, _ := NewFileTokenCache(path, "secret")
+	if got, err := cache.Load(ctx, "k"); got != nil || err == nil {
+		t.Errorf("Load from a legacy file = %+v, %v; want an error", got, err)
+	}
+	if err := cache.Store(ctx, "k", token); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cache.Load(ctx, "k"); err != nil || got == nil {
+		t.Errorf("Load after replacing a legacy file = %+v, %v", got, err)
+	}
+}
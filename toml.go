@@ -0,0 +1,269 @@
+package bdds
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tomlParser reads the subset of TOML used by profile files: [table] and
+// [dotted.table] headers, bare or quoted keys, and values that are strings,
+// integers, booleans or arrays of them. Anything else (floats, dates, inline
+// tables, arrays of tables, multi-line strings) is reported as an error.
+type tomlParser struct {
+	s    string
+	pos  int
+	line int
+}
+
+// parseTOML parses a document into nested maps: a table is a
+// map[string]any, a string a string, an integer an int64, a boolean a bool
+// and an array a []any.
+func parseTOML(s string) (map[string]any, error) {
+	p := &tomlParser{s: s, line: 1}
+	root := map[string]any{}
+	table := root
+	defined := map[string]bool{}
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+		if p.peek() == '[' {
+			path, err := p.tableHeader()
+			if err != nil {
+				return nil, err
+			}
+			name := strings.Join(path, ".")
+			if defined[name] {
+				return nil, p.errorf("table [%s] defined twice", name)
+			}
+			defined[name] = true
+			if table, err = p.subTable(root, path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '=' {
+			return nil, p.errorf("expected = after key %q", key)
+		}
+		p.pos++
+		p.skipBlank(false)
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := table[key]; dup {
+			return nil, p.errorf("key %q defined twice", key)
+		}
+		table[key] = value
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool  { return p.pos >= len(p.s) }
+func (p *tomlParser) peek() byte { return p.s[p.pos] }
+
+// skipBlank skips spaces, tabs and comments, and newlines if newlines is
+// set.
+func (p *tomlParser) skipBlank(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine consumes the rest of a line after a key/value pair or header.
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q after value", p.peek())
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+func (p *tomlParser) tableHeader() ([]string, error) {
+	p.pos++ // [
+	if !p.eof() && p.peek() == '[' {
+		return nil, p.errorf("arrays of tables are not supported")
+	}
+	var path []string
+	for {
+		p.skipBlank(false)
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, key)
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("unterminated table header")
+		}
+		switch p.peek() {
+		case '.':
+			p.pos++
+		case ']':
+			p.pos++
+			return path, p.endOfLine()
+		default:
+			return nil, p.errorf("unexpected %q in table header", p.peek())
+		}
+	}
+}
+
+// subTable returns the table at path below root, creating missing tables.
+func (p *tomlParser) subTable(root map[string]any, path []string) (map[string]any, error) {
+	table := root
+	for _, key := range path {
+		switch v := table[key].(type) {
+		case nil:
+			sub := map[string]any{}
+			table[key] = sub
+			table = sub
+		case map[string]any:
+			table = v
+		default:
+			return nil, p.errorf("key %q is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+func (p *tomlParser) key() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected a key")
+	}
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.str()
+	}
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c != '_' && c != '-' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a key, found %q", p.peek())
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *tomlParser) value() (any, error) {
+	if p.eof() {
+		return nil, p.errorf("expected a value")
+	}
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.str()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return nil, p.errorf("inline tables are not supported")
+	}
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n#,]", rune(p.peek())) {
+		p.pos++
+	}
+	word := p.s[start:p.pos]
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(word, "_", ""), 0, 64)
+	if err != nil {
+		return nil, p.errorf("unsupported value %q (quote strings and durations)", word)
+	}
+	return n, nil
+}
+
+// str reads a basic ("...") or literal ('...') single-line string.
+func (p *tomlParser) str() (string, error) {
+	quote := p.peek()
+	if strings.HasPrefix(p.s[p.pos:], strings.Repeat(string(quote), 3)) {
+		return "", p.errorf("multi-line strings are not supported")
+	}
+	start := p.pos
+	p.pos++
+	for !p.eof() && p.peek() != quote && p.peek() != '\n' {
+		if quote == '"' && p.peek() == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.eof() || p.peek() != quote {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+	raw := p.s[start:p.pos]
+	if quote == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+	s, err := strconv.Unquote(raw)
+	if err != nil {
+		return "", p.errorf("invalid string %s", raw)
+	}
+	return s, nil
+}
+
+// array reads an array, which may span several lines.
+func (p *tomlParser) array() ([]any, error) {
+	p.pos++ // [
+	values := []any{}
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return values, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array, found %q", p.peek())
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package bdds
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	doc, err := parseTOML(`# comment
+title = "BDDS" # trailing comment
+count = 1_000
+enabled = true
+
+[a.b]
+path = 'C:\data'
+"quoted key" = "tab\there"
+list = [
+  1, 2, # comment
+  3,
+]
+names = ["x", 'y']
+empty = []
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"title":   "BDDS",
+		"count":   int64(1000),
+		"enabled": true,
+		"a": map[string]any{
+			"b": map[string]any{
+				"path":       `C:\data`,
+				"quoted key": "tab\there",
+				"list":       []any{int64(1), int64(2), int64(3)},
+				"names":      []any{"x", "y"},
+				"empty":      []any{},
+			},
+		},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("parseTOML =\n%#v\nwant\n%#v", doc, want)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := map[string]string{
+		"a = 1\na = 2":          "line 2: key \"a\" defined twice",
+		"[t]\n[t]":              "line 2: table [t] defined twice",
+		"a = 1.5":               "unsupported value",
+		"a = 2024-01-01":        "unsupported value",
+		"a = \"open":            "unterminated string",
+		"a = [1, 2":             "unterminated array",
+		"a = { b = 1 }":         "inline tables",
+		"[[t]]":                 "arrays of tables",
+		"a = \"\"\"x\"\"\"":     "multi-line strings",
+		"a = 1 b = 2":           "unexpected",
+		"a 1":                   "expected =",
+		"a = 1\n[a]\nb = 2":     "not a table",
+		"a = \"x\"\n\n = \"y\"": "line 3: expected a key",
+	}
+	for doc, want := range tests {
+		_, err := parseTOML(doc)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseTOML(%q) = %v, want an error containing %q", doc, err, want)
+		}
+	}
+}
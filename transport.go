@@ -0,0 +1,246 @@
+package bdds
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// TransportOptions tune the connections of the client's default transport,
+// e.g. for parallel mirroring, which needs a larger connection pool than
+// http.DefaultTransport keeps. Unset fields keep its defaults.
+type TransportOptions struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept per host
+	// for reuse (default: 2).
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps the connections per host (default: unlimited).
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for this long (default: 90s).
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period (default: 30s; negative
+	// disables keep-alives).
+	KeepAlive time.Duration
+	// DisableHTTP2 restricts connections to HTTP/1.1, which opens a
+	// connection per concurrent request instead of multiplexing them.
+	DisableHTTP2 bool
+}
+
+// newHTTPClient builds the client's HTTP client from Config.HTTPClient,
+// Transport, TransportOptions and the proxy settings, auditing its requests,
+// wrapping it in Config.Middleware, setting request IDs, counting download Stats, applying
+// the client's timeouts and capping its concurrent requests at
+// Config.MaxConcurrentRequests.
+func newHTTPClient(cfg *Config, stats *clientStats) (*http.Client, error) {
+	httpClient := &http.Client{Transport: cfg.Transport}
+	if cfg.HTTPClient != nil {
+		copied := *cfg.HTTPClient // keep the caller's client unwrapped
+		httpClient = &copied
+	}
+	if configuresTransport(cfg) {
+		transport, err := baseTransport(cfg)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = transport
+	}
+	if cfg.DebugDump != nil {
+		httpClient.Transport = &dumpTransport{base: httpClient.Transport, w: cfg.DebugDump, bodies: cfg.DebugDumpBodies}
+	}
+	if cfg.Auditor != nil {
+		logger := cfg.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		httpClient.Transport = &auditTransport{base: httpClient.Transport, auditor: cfg.Auditor, logger: logger}
+	}
+	if cfg.Logger != nil {
+		httpClient.Transport = &loggingTransport{base: httpClient.Transport, logger: cfg.Logger}
+	}
+	httpClient.Transport = withMiddleware(httpClient.Transport, cfg.Middleware)
+	httpClient.Transport = &requestIDTransport{base: httpClient.Transport}
+	httpClient.Transport = &statsTransport{base: httpClient.Transport, stats: stats}
+	if cfg.MaxConcurrentRequests > 0 {
+		httpClient.Transport = newLimitedTransport(httpClient.Transport, cfg.MaxConcurrentRequests)
+	}
+	httpClient.Transport = newTimeoutTransport(httpClient.Transport, cfg)
+	return httpClient, nil
+}
+
+// newTimeoutTransport applies the timeouts of cfg to base.
+func newTimeoutTransport(base http.RoundTripper, cfg *Config) *timeoutTransport {
+	return &timeoutTransport{
+		base:          base,
+		timeout:       cfg.Timeout,
+		headerTimeout: cfg.DownloadHeaderTimeout,
+		idleTimeout:   cfg.DownloadIdleTimeout,
+	}
+}
+
+// configuresTransport reports whether cfg has settings for the default
+// transport.
+func configuresTransport(cfg *Config) bool {
+	return cfg.ProxyURL != "" || len(cfg.ProxyConnectHeader) > 0 || cfg.TransportOptions != nil || cfg.TLS != nil
+}
+
+// baseTransport returns http.DefaultTransport configured by the proxy,
+// transport and TLS settings, or nil to use it as is. The default transport honours
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY from the environment.
+func baseTransport(cfg *Config) (http.RoundTripper, error) {
+	if !configuresTransport(cfg) {
+		return nil, nil
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ProxyURL != "" {
+		u, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	t.ProxyConnectHeader = cfg.ProxyConnectHeader.Clone()
+
+	if opts := cfg.TransportOptions; opts != nil {
+		if opts.MaxIdleConnsPerHost > 0 {
+			t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+			t.MaxIdleConns = max(t.MaxIdleConns, opts.MaxIdleConnsPerHost)
+		}
+		t.MaxConnsPerHost = opts.MaxConnsPerHost
+		if opts.IdleConnTimeout > 0 {
+			t.IdleConnTimeout = opts.IdleConnTimeout
+		}
+		if opts.KeepAlive != 0 {
+			dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: opts.KeepAlive}
+			t.DialContext = dialer.DialContext
+		}
+		if opts.DisableHTTP2 {
+			t.ForceAttemptHTTP2 = false
+			t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+	}
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = tlsConfig
+	}
+	return t, nil
+}
+
+// downloadRequestPath matches the path of file download requests.
+var downloadRequestPath = regexp.MustCompile(`/file/\d+/download$`)
+
+// timeoutTransport applies the client's timeouts per request. Catalog, OAuth
+// and other requests are bounded as a whole, including reading the body, by
+// timeout. File downloads only by headerTimeout for the response and by
+// idleTimeout for each read of the body, so that a download taking hours is
+// not cut off while a stalled one still fails.
+type timeoutTransport struct {
+	base          http.RoundTripper
+	timeout       time.Duration
+	headerTimeout time.Duration
+	idleTimeout   time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !downloadRequestPath.MatchString(req.URL.Path) {
+		ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+		resp, err := base.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	w := &watchdog{cancel: cancel}
+	w.start(t.headerTimeout)
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if w.stop() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		return nil, &DownloadTimeoutError{Timeout: t.headerTimeout}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &idleBody{ReadCloser: resp.Body, watchdog: w, idle: t.idleTimeout}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *timeoutTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// closeIdleConnections closes the idle connections of a transport that
+// keeps them, as http.Client.CloseIdleConnections does.
+func closeIdleConnections(rt http.RoundTripper) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if c, ok := rt.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// watchdog cancels a request when a timer started by start fires before
+// stop is called.
+type watchdog struct {
+	cancel context.CancelFunc
+	timer  *time.Timer
+	fired  atomic.Bool
+}
+
+func (w *watchdog) start(d time.Duration) {
+	w.timer = time.AfterFunc(d, func() {
+		w.fired.Store(true)
+		w.cancel()
+	})
+}
+
+// stop stops the timer and reports whether it fired.
+func (w *watchdog) stop() bool {
+	w.timer.Stop()
+	return w.fired.Load()
+}
+
+// idleBody fails a read of a download body that waits longer than idle for
+// data. Time spent between reads, e.g. while a download is paused, does not
+// count.
+type idleBody struct {
+	io.ReadCloser
+	watchdog *watchdog
+	idle     time.Duration
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	b.watchdog.start(b.idle)
+	n, err := b.ReadCloser.Read(p)
+	if b.watchdog.stop() {
+		return n, &DownloadTimeoutError{Stalled: true, Timeout: b.idle}
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	defer b.watchdog.cancel()
+	return b.ReadCloser.Close()
+}
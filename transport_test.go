@@ -0,0 +1,247 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProxyURL(t *testing.T) {
+	var mu sync.Mutex
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "Basic " + base64.StdEncoding.EncodeToString([]byte("proxyuser:proxypass"))
+		if got := r.Header.Get("Proxy-Authorization"); got != want {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		mu.Lock()
+		hosts = append(hosts, r.URL.Host)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Host {
+		case "login.test":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "token", "token_type": "Bearer", "expires_in": 3600,
+			})
+		default:
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+		}
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(&Config{
+		Username: "u",
+		Password: "p",
+		BaseURL:  "http://api.test",
+		TokenURL: "http://login.test/token",
+		ProxyURL: "http://proxyuser:proxypass@" + proxy.Listener.Addr().String(),
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.ListProducts(context.Background()); err != nil {
+		t.Fatalf("ListProducts through proxy: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hosts) != 2 || hosts[0] != "login.test" || hosts[1] != "api.test" {
+		t.Errorf("proxied hosts = %q, want the token and API hosts", hosts)
+	}
+}
+
+func TestProxyURLInvalid(t *testing.T) {
+	for _, proxyURL := range []string{"ftp://proxy:21", "http://", "://proxy"} {
+		if _, err := NewClient(&Config{ProxyURL: proxyURL}); err == nil {
+			t.Errorf("NewClient accepted proxy URL %q", proxyURL)
+		}
+	}
+}
+
+func TestProxyConnectHeader(t *testing.T) {
+	header := http.Header{"Proxy-Authorization": {"Negotiate abc"}}
+	rt, err := baseTransport(&Config{ProxyURL: "http://proxy:3128", ProxyConnectHeader: header})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rt.(*http.Transport).ProxyConnectHeader.Get("Proxy-Authorization")
+	if got != "Negotiate abc" {
+		t.Errorf("ProxyConnectHeader = %q", got)
+	}
+	if rt, err := baseTransport(&Config{}); err != nil || rt != nil {
+		t.Errorf("baseTransport without proxy settings = %v, %v; want the default transport", rt, err)
+	}
+}
+
+// countingTransport counts the requests per host and signs them with a
+// header, as a request-signing transport would.
+type countingTransport struct {
+	mu    sync.Mutex
+	hosts map[string]int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.hosts[req.URL.Host]++
+	c.mu.Unlock()
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Signature", "signed")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestCustomTransport(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "signed" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+	}))
+	defer apiServer.Close()
+
+	for _, name := range []string{"Transport", "HTTPClient"} {
+		t.Run(name, func(t *testing.T) {
+			rt := &countingTransport{hosts: map[string]int{}}
+			cfg := &Config{Username: "u", Password: "p", BaseURL: apiServer.URL, TokenURL: authServer.URL, MaxConcurrentRequests: 2}
+			if name == "Transport" {
+				cfg.Transport = rt
+			} else {
+				cfg.HTTPClient = &http.Client{Transport: rt}
+			}
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			if _, err := client.ListProducts(context.Background()); err != nil {
+				t.Fatalf("ListProducts: %v", err)
+			}
+			rt.mu.Lock()
+			defer rt.mu.Unlock()
+			if len(rt.hosts) != 2 {
+				t.Errorf("requests per host = %v, want the auth and API hosts", rt.hosts)
+			}
+			if cfg.HTTPClient != nil && cfg.HTTPClient.Transport != rt {
+				t.Error("NewClient modified the caller's HTTPClient")
+			}
+		})
+	}
+}
+
+func TestCustomTransportConflicts(t *testing.T) {
+	for name, cfg := range map[string]*Config{
+		"both":             {Transport: http.DefaultTransport, HTTPClient: &http.Client{}},
+		"proxy+transport":  {Transport: http.DefaultTransport, ProxyURL: "http://proxy:3128"},
+		"proxy+httpclient": {HTTPClient: &http.Client{}, ProxyURL: "http://proxy:3128"},
+	} {
+		if _, err := NewClient(cfg); err == nil {
+			t.Errorf("%s: NewClient accepted conflicting settings", name)
+		}
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		switch {
+		case !strings.HasSuffix(r.URL.Path, "/download"):
+			time.Sleep(200 * time.Millisecond) // slow catalog
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+		case strings.Contains(r.URL.Path, "/file/1/"): // slow but steady
+			w.Header().Set("Content-Length", "10")
+			for i := 0; i < 10; i++ {
+				_, _ = w.Write([]byte("x"))
+				flusher.Flush()
+				time.Sleep(30 * time.Millisecond)
+			}
+		case strings.Contains(r.URL.Path, "/file/2/"): // stalls
+			w.Header().Set("Content-Length", "10")
+			_, _ = w.Write([]byte("x"))
+			flusher.Flush()
+			<-r.Context().Done()
+		default: // no response
+			<-r.Context().Done()
+		}
+	}))
+	defer apiServer.Close()
+
+	client, err := NewClient(&Config{
+		Username:              "u",
+		Password:              "p",
+		BaseURL:               apiServer.URL,
+		TokenURL:              authServer.URL,
+		Timeout:               100 * time.Millisecond,
+		DownloadHeaderTimeout: 100 * time.Millisecond,
+		DownloadIdleTimeout:   100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.GetProduct(ctx, 3, WithNoRetry()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("slow catalog request: %v, want a deadline error", err)
+	}
+
+	var buf bytes.Buffer
+	if err := client.DownloadFile(ctx, 3, 1, 1, &buf, WithNoRetry()); err != nil || buf.Len() != 10 {
+		t.Errorf("download outlasting Timeout failed: %v (%d bytes)", err, buf.Len())
+	}
+
+	var timeoutErr *DownloadTimeoutError
+	err = client.DownloadFile(ctx, 3, 1, 2, io.Discard, WithNoRetry())
+	if !errors.As(err, &timeoutErr) || !timeoutErr.Stalled {
+		t.Errorf("stalled download: %v", err)
+	}
+	err = client.DownloadFile(ctx, 3, 1, 3, io.Discard, WithNoRetry())
+	if !errors.As(err, &timeoutErr) || timeoutErr.Stalled {
+		t.Errorf("download without response: %v", err)
+	}
+}
+
+func TestTransportOptions(t *testing.T) {
+	rt, err := baseTransport(&Config{TransportOptions: &TransportOptions{
+		MaxIdleConnsPerHost: 200,
+		MaxConnsPerHost:     64,
+		IdleConnTimeout:     time.Minute,
+		KeepAlive:           15 * time.Second,
+		DisableHTTP2:        true,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := rt.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 200 || tr.MaxIdleConns < 200 || tr.MaxConnsPerHost != 64 || tr.IdleConnTimeout != time.Minute {
+		t.Errorf("pool settings not applied: %+v", tr)
+	}
+	if tr.ForceAttemptHTTP2 || tr.TLSNextProto == nil || tr.DialContext == nil {
+		t.Error("HTTP/2 or keep-alive settings not applied")
+	}
+	if tr.Proxy == nil {
+		t.Error("tuned transport lost the proxy from the environment")
+	}
+
+	if err := (&Config{TransportOptions: &TransportOptions{MaxConnsPerHost: -1}}).Validate(); err == nil {
+		t.Error("negative limit accepted")
+	}
+	if _, err := NewClient(&Config{TransportOptions: &TransportOptions{}, Transport: http.DefaultTransport}); err == nil {
+		t.Error("TransportOptions accepted with a custom Transport")
+	}
+	if _, err := NewClient(&Config{TransportOptions: &TransportOptions{MaxIdleConnsPerHost: 16}}); err != nil {
+		t.Errorf("NewClient: %v", err)
+	}
+}
@@ -1,36 +1,154 @@
 package bdds
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// The catalog types marshal to JSON with the field names of the BDDS API,
+// so catalog data can be serialized directly, e.g. by SaveCatalog.
 
 // Product represents a BDDS product
 type Product struct {
-	ID          int
-	Name        string
-	Description string
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 // ProductWithDeliveries represents a product with its deliveries
 type ProductWithDeliveries struct {
-	ID          int
-	Name        string
-	Description string
-	Deliveries  []*Delivery
+	ID          int         `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Deliveries  []*Delivery `json:"deliveries"`
 }
 
 // Delivery represents a product delivery
 type Delivery struct {
-	DeliveryID                  int
-	DeliveryName                string
-	DeliveryPublicationDatetime time.Time
-	DeliveryExpiryDatetime      *time.Time
-	Files                       []*DeliveryFile
+	DeliveryID                  int             `json:"deliveryId"`
+	DeliveryName                string          `json:"deliveryName"`
+	DeliveryPublicationDatetime time.Time       `json:"deliveryPublicationDatetime"`
+	DeliveryExpiryDatetime      *time.Time      `json:"deliveryExpiryDatetime"`
+	Files                       []*DeliveryFile `json:"files"`
 }
 
 // DeliveryFile represents a file in a delivery
 type DeliveryFile struct {
-	FileID                  int
-	FileName                string
-	FileSize                string
-	FileChecksum            string
-	FilePublicationDatetime time.Time
+	FileID                  int       `json:"fileId"`
+	FileName                string    `json:"fileName"`
+	FileSize                string    `json:"fileSize"`
+	FileChecksum            string    `json:"fileChecksum"`
+	FilePublicationDatetime time.Time `json:"filePublicationDatetime"`
+}
+
+// String returns the product's ID and name, e.g. "3 EP DocDB front file".
+func (p *Product) String() string {
+	return fmt.Sprintf("%d %s", p.ID, p.Name)
+}
+
+// Summary describes the product in one line: its ID, name and description.
+func (p *Product) Summary() string {
+	if p.Description == "" {
+		return p.String()
+	}
+	return p.String() + ": " + p.Description
+}
+
+// String returns the product's ID and name, e.g. "3 EP DocDB front file".
+func (p *ProductWithDeliveries) String() string {
+	return fmt.Sprintf("%d %s", p.ID, p.Name)
+}
+
+// Summary describes the product in one line: its ID, name, number of
+// deliveries and latest publication date.
+func (p *ProductWithDeliveries) Summary() string {
+	var latest time.Time
+	for _, d := range p.Deliveries {
+		if d.DeliveryPublicationDatetime.After(latest) {
+			latest = d.DeliveryPublicationDatetime
+		}
+	}
+	s := fmt.Sprintf("%s: %s", p, plural(len(p.Deliveries), "delivery", "deliveries"))
+	if !latest.IsZero() {
+		s += ", latest " + latest.Format(time.DateOnly)
+	}
+	return s
+}
+
+// String returns the delivery's ID and name, e.g. "3262 2026/023".
+func (d *Delivery) String() string {
+	return fmt.Sprintf("%d %s", d.DeliveryID, d.DeliveryName)
+}
+
+// Summary describes the delivery in one line: its ID, name, publication
+// and expiry dates and number of files.
+func (d *Delivery) Summary() string {
+	s := fmt.Sprintf("%s: published %s", d, d.DeliveryPublicationDatetime.Format(time.DateOnly))
+	if d.DeliveryExpiryDatetime != nil {
+		s += ", expires " + d.DeliveryExpiryDatetime.Format(time.DateOnly)
+	}
+	return s + ", " + plural(len(d.Files), "file", "files")
+}
+
+// String returns the file's ID and name, e.g. "9416 legstat_xml_202623.zip".
+func (f *DeliveryFile) String() string {
+	return fmt.Sprintf("%d %s", f.FileID, f.FileName)
+}
+
+// Summary describes the file in one line: its ID, name, size and
+// publication date.
+func (f *DeliveryFile) Summary() string {
+	s := f.String()
+	if f.FileSize != "" {
+		s += " (" + f.FileSize + ")"
+	}
+	if !f.FilePublicationDatetime.IsZero() {
+		s += ", published " + f.FilePublicationDatetime.Format(time.DateOnly)
+	}
+	return s
+}
+
+// SizeBytes returns the file's size in bytes, parsed from FileSize. The
+// catalog rounds sizes ("216.6 MB"), so it is approximate; it is 0 if the
+// size is missing or unreadable.
+func (f *DeliveryFile) SizeBytes() int64 {
+	return parseFileSize(f.FileSize)
+}
+
+// TotalSizeBytes returns the approximate size of the delivery's files in
+// bytes, see DeliveryFile.SizeBytes.
+func (d *Delivery) TotalSizeBytes() int64 {
+	var n int64
+	for _, f := range d.Files {
+		n += f.SizeBytes()
+	}
+	return n
+}
+
+// TotalSizeBytes returns the approximate size of all files of the product
+// in bytes, e.g. the disk space a full mirror needs, see
+// DeliveryFile.SizeBytes.
+func (p *ProductWithDeliveries) TotalSizeBytes() int64 {
+	var n int64
+	for _, d := range p.Deliveries {
+		n += d.TotalSizeBytes()
+	}
+	return n
+}
+
+// FileCount returns the number of files in all of the product's deliveries.
+func (p *ProductWithDeliveries) FileCount() int {
+	n := 0
+	for _, d := range p.Deliveries {
+		n += len(d.Files)
+	}
+	return n
+}
+
+// plural formats a count with the singular or plural noun.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
 }
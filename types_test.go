@@ -0,0 +1,65 @@
+package bdds
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTypeSummaries(t *testing.T) {
+	published := time.Date(2026, 6, 2, 9, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	expiry := published.AddDate(1, 0, 0)
+	file := &DeliveryFile{FileID: 9416, FileName: "legstat_xml_202623.zip", FileSize: "216.6 MB", FilePublicationDatetime: published}
+	delivery := &Delivery{DeliveryID: 3262, DeliveryName: "2026/023", DeliveryPublicationDatetime: published, Files: []*DeliveryFile{file}}
+	older := &Delivery{DeliveryID: 3200, DeliveryName: "2026/022", DeliveryPublicationDatetime: published.AddDate(0, 0, -7), DeliveryExpiryDatetime: &expiry}
+	product := &ProductWithDeliveries{ID: 5, Name: "INPADOC", Deliveries: []*Delivery{older, delivery}}
+
+	for _, tt := range []struct{ got, want string }{
+		{fmt.Sprint(&Product{ID: 3, Name: "EP DocDB front file"}), "3 EP DocDB front file"},
+		{(&Product{ID: 3, Name: "EP DocDB front file", Description: "Weekly"}).Summary(), "3 EP DocDB front file: Weekly"},
+		{(&Product{ID: 3, Name: "EP DocDB front file"}).Summary(), "3 EP DocDB front file"},
+		{fmt.Sprint(product), "5 INPADOC"},
+		{product.Summary(), "5 INPADOC: 2 deliveries, latest 2026-06-02"},
+		{(&ProductWithDeliveries{ID: 5, Name: "INPADOC"}).Summary(), "5 INPADOC: 0 deliveries"},
+		{fmt.Sprint(delivery), "3262 2026/023"},
+		{delivery.Summary(), "3262 2026/023: published 2026-06-02, 1 file"},
+		{older.Summary(), "3200 2026/022: published 2026-05-26, expires 2027-06-02, 0 files"},
+		{fmt.Sprint(file), "9416 legstat_xml_202623.zip"},
+		{file.Summary(), "9416 legstat_xml_202623.zip (216.6 MB), published 2026-06-02"},
+		{(&DeliveryFile{FileID: 1, FileName: "a.zip"}).Summary(), "1 a.zip"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestTotalSizeBytes(t *testing.T) {
+	product := &ProductWithDeliveries{ID: 14, Deliveries: []*Delivery{
+		{DeliveryID: 1, Files: []*DeliveryFile{
+			{FileID: 1, FileSize: "1.5 GB"},
+			{FileID: 2, FileSize: "406.6 kB"},
+		}},
+		{DeliveryID: 2, Files: []*DeliveryFile{
+			{FileID: 3, FileSize: "2 MiB"},
+			{FileID: 4, FileSize: "unknown"},
+		}},
+		{DeliveryID: 3},
+	}}
+
+	if got, want := product.Deliveries[0].TotalSizeBytes(), int64(1_500_406_600); got != want {
+		t.Errorf("Delivery.TotalSizeBytes = %d, want %d", got, want)
+	}
+	if got, want := product.Deliveries[1].TotalSizeBytes(), int64(2<<20); got != want {
+		t.Errorf("Delivery.TotalSizeBytes = %d, want %d", got, want)
+	}
+	if got, want := product.TotalSizeBytes(), int64(1_500_406_600+2<<20); got != want {
+		t.Errorf("ProductWithDeliveries.TotalSizeBytes = %d, want %d", got, want)
+	}
+	if got := product.FileCount(); got != 4 {
+		t.Errorf("FileCount = %d, want 4", got)
+	}
+	if got := (&ProductWithDeliveries{}).TotalSizeBytes(); got != 0 {
+		t.Errorf("empty product TotalSizeBytes = %d, want 0", got)
+	}
+}
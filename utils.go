@@ -2,7 +2,13 @@ package bdds
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 // readJSON reads and unmarshals JSON from a reader
@@ -10,6 +16,43 @@ func readJSON(r io.Reader, v interface{}) error {
 	return json.NewDecoder(r).Decode(v)
 }
 
+// readBody reads and closes an HTTP response body
+func readBody(resp *http.Response) ([]byte, error) {
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, nil
+}
+
+// fileSizeUnits maps the units of the catalog's human-readable file sizes to
+// byte multipliers. EPO reports decimal (SI) units, e.g. "406.6 kB" for a
+// 406624-byte file; binary units are accepted as well.
+var fileSizeUnits = map[string]float64{
+	"B": 1, "BYTE": 1, "BYTES": 1,
+	"KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12,
+	"KIB": 1 << 10, "MIB": 1 << 20, "GIB": 1 << 30, "TIB": 1 << 40,
+}
+
+// parseFileSize converts a catalog file size such as "17.7 kB" or "1.5 GB" to
+// an approximate byte count, returning 0 when the value cannot be parsed.
+func parseFileSize(s string) int64 {
+	fields := strings.Fields(strings.TrimSpace(s))
+	if len(fields) != 2 {
+		return 0
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	mult, ok := fileSizeUnits[strings.ToUpper(fields[1])]
+	if !ok {
+		return 0
+	}
+	return int64(value * mult)
+}
+
 // progressReader wraps an io.Reader to track progress
 type progressReader struct {
 	reader     io.Reader
@@ -39,3 +82,29 @@ func (cw *countingWriter) Write(p []byte) (int, error) {
 	cw.n += int64(n)
 	return n, err
 }
+
+// writeFileAtomic writes data to path via a synced temporary file in the same
+// directory and a rename, so readers (and a crash) only ever observe the old
+// or the new content, never a partial write.
+func writeFileAtomic(path string, data []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
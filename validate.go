@@ -0,0 +1,110 @@
+package bdds
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Validate reports the problems of c that would otherwise only surface at
+// the first request, or not at all: malformed URLs, negative retries,
+// timeouts and limits, a username without a password, conflicting HTTP
+// settings and overlapping accounts. Unset fields are valid, as NewClient
+// gives them defaults. The result joins a *ConfigError for each problem;
+// NewClient returns it unchanged.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	var errs []error
+	invalid := func(field, format string, args ...any) {
+		errs = append(errs, &ConfigError{Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+	checkURL := func(field, raw string, schemes ...string) {
+		if raw == "" {
+			return
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			invalid(field, "malformed URL: %v", errors.Unwrap(err))
+			return
+		}
+		if !slices.Contains(schemes, u.Scheme) {
+			invalid(field, "unsupported scheme %q in %s", u.Scheme, u.Redacted())
+			return
+		}
+		if u.Host == "" {
+			invalid(field, "missing host in %s", u.Redacted())
+		}
+	}
+	nonNegative := func(field string, d time.Duration) {
+		if d < 0 {
+			invalid(field, "negative duration %v", d)
+		}
+	}
+
+	if c.Environment != "" {
+		if _, ok := LookupEnvironment(c.Environment); !ok {
+			invalid("Environment", "unknown environment %q", c.Environment)
+		}
+	}
+	checkURL("BaseURL", c.BaseURL, "http", "https")
+	checkURL("TokenURL", c.TokenURL, "http", "https")
+	checkURL("RevokeURL", c.RevokeURL, "http", "https")
+	checkURL("ProxyURL", c.ProxyURL, "http", "https", "socks5", "socks5h")
+
+	if c.Credentials == nil {
+		switch {
+		case c.Username != "" && c.Password == "":
+			invalid("Password", "username is set without a password")
+		case c.Username == "" && c.Password != "":
+			invalid("Username", "password is set without a username")
+		}
+	}
+	if c.MaxRetries < 0 {
+		invalid("MaxRetries", "negative retry count %d", c.MaxRetries)
+	}
+	if c.MaxConcurrentRequests < 0 {
+		invalid("MaxConcurrentRequests", "negative limit %d", c.MaxConcurrentRequests)
+	}
+	nonNegative("RetryDelay", c.RetryDelay)
+	nonNegative("Timeout", c.Timeout)
+	nonNegative("DownloadHeaderTimeout", c.DownloadHeaderTimeout)
+	nonNegative("DownloadIdleTimeout", c.DownloadIdleTimeout)
+	nonNegative("CredentialTTL", c.CredentialTTL)
+	nonNegative("CatalogCacheTTL", c.CatalogCacheTTL)
+
+	switch {
+	case c.Transport != nil && c.HTTPClient != nil:
+		invalid("Transport", "set either HTTPClient or Transport, not both")
+	case configuresTransport(c) && (c.Transport != nil || c.HTTPClient != nil):
+		invalid("HTTPClient", "proxy, transport and TLS settings cannot be combined with a custom HTTPClient or Transport")
+	}
+	if o := c.TransportOptions; o != nil {
+		if o.MaxIdleConnsPerHost < 0 || o.MaxConnsPerHost < 0 || o.IdleConnTimeout < 0 {
+			invalid("TransportOptions", "negative limit")
+		}
+	}
+
+	for i, m := range c.Middleware {
+		if m == nil {
+			invalid(fmt.Sprintf("Middleware[%d]", i), "nil middleware")
+		}
+	}
+
+	products := make(map[int]int)
+	for i, a := range c.Accounts {
+		if a.Username == "" || a.Password == "" {
+			invalid(fmt.Sprintf("Accounts[%d]", i), "missing username or password")
+		}
+		for _, productID := range a.Products {
+			if j, dup := products[productID]; dup && j != i {
+				invalid(fmt.Sprintf("Accounts[%d].Products", i), "product %d is already assigned to account %d", productID, j)
+			}
+			products[productID] = i
+		}
+	}
+	return errors.Join(errs...)
+}
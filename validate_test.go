@@ -0,0 +1,65 @@
+package bdds
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		config *Config
+		field  string
+	}{
+		"malformed base URL":  {&Config{BaseURL: "https://bdds .epo.org"}, "BaseURL"},
+		"relative base URL":   {&Config{BaseURL: "publication-bdds.apps.epo.org"}, "BaseURL"},
+		"token URL scheme":    {&Config{TokenURL: "ftp://login.epo.org/token"}, "TokenURL"},
+		"proxy without host":  {&Config{ProxyURL: "http://"}, "ProxyURL"},
+		"unknown environment": {&Config{Environment: "staging"}, "Environment"},
+		"no password":         {&Config{Username: "u"}, "Password"},
+		"no username":         {&Config{Password: "p"}, "Username"},
+		"negative retries":    {&Config{MaxRetries: -1}, "MaxRetries"},
+		"negative timeout":    {&Config{Timeout: -1}, "Timeout"},
+		"negative limit":      {&Config{MaxConcurrentRequests: -2}, "MaxConcurrentRequests"},
+		"client and transport": {
+			&Config{HTTPClient: &http.Client{}, Transport: http.DefaultTransport}, "Transport",
+		},
+		"duplicate product": {&Config{Accounts: []Account{
+			{Username: "a", Password: "p", Products: []int{3}},
+			{Username: "b", Password: "p", Products: []int{3}},
+		}}, "Accounts[1].Products"},
+	}
+	for name, tt := range tests {
+		err := tt.config.Validate()
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) {
+			t.Errorf("%s: Validate = %v, want a ConfigError", name, err)
+			continue
+		}
+		if cfgErr.Field != tt.field {
+			t.Errorf("%s: field %q, want %q (%v)", name, cfgErr.Field, tt.field, err)
+		}
+		if _, err := NewClient(tt.config); !errors.As(err, &cfgErr) {
+			t.Errorf("%s: NewClient = %v, want a ConfigError", name, err)
+		}
+	}
+}
+
+func TestConfigValidateValid(t *testing.T) {
+	var nilConfig *Config
+	for _, cfg := range []*Config{nilConfig, {}, DefaultConfig(), {
+		Username: "u", Password: "p", ProxyURL: "socks5://proxy:1080", Environment: ProductionEnvironment,
+	}} {
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate(%v) = %v", cfg, err)
+		}
+	}
+}
+
+func TestConfigValidateJoinsErrors(t *testing.T) {
+	err := (&Config{MaxRetries: -1, RetryDelay: -1}).Validate()
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("Validate = %v, want both problems", err)
+	}
+}
@@ -0,0 +1,174 @@
+package bdds
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// MirrorEntry is a catalog file as found (or not) in a local mirror.
+type MirrorEntry struct {
+	DeliveryID int
+	File       *DeliveryFile
+	Path       string // local path; empty for missing files
+	Problem    string // why the file is corrupt; empty otherwise
+}
+
+// MirrorReport is the result of VerifyLocalMirror.
+type MirrorReport struct {
+	Verified []MirrorEntry // present with matching size and checksum
+	Missing  []MirrorEntry // in the catalog but not found locally
+	Corrupt  []MirrorEntry // found with the wrong size or checksum
+	Extra    []string      // local files that match no catalog file
+}
+
+// OK reports whether the mirror is complete and intact. Extra files do not
+// count as a problem.
+func (r *MirrorReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Corrupt) == 0
+}
+
+// VerifyLocalMirror audits a local mirror of a product against the catalog.
+// Every regular file below dir is matched to a catalog file by its path
+// under opts.Layout, by default the DownloadProduct layout
+// "<delivery>/<file>". A file found elsewhere, e.g. in a flat directory, is
+// matched by file name, provided only one catalog file has that name. Matched
+// files are re-hashed against the catalog checksum using opts.Concurrency
+// workers; opts.DeliveryFilter and opts.Filter restrict which files are
+// expected to be present.
+//
+// Per-file findings go into the report; the error is reserved for failures to
+// fetch the catalog or walk dir.
+func (c *Client) VerifyLocalMirror(ctx context.Context, productID int, dir string, opts *DownloadOptions) (*MirrorReport, error) {
+	if opts != nil && opts.Layout != "" {
+		if err := validateLayout(opts.Layout); err != nil {
+			return nil, err
+		}
+	}
+	product, err := c.GetProduct(ctx, productID, WithNoCache())
+	if err != nil {
+		return nil, err
+	}
+
+	layout := layoutOf(opts, productLayout)
+	byPath := make(map[string]*MirrorEntry)
+	byName := make(map[string][]*MirrorEntry)
+	var order []*MirrorEntry
+	for _, d := range product.Deliveries {
+		if opts != nil && opts.DeliveryFilter != nil && !opts.DeliveryFilter(d) {
+			continue
+		}
+		for _, f := range d.Files {
+			if opts != nil && !opts.Filter.Match(d, f) {
+				continue
+			}
+			l := layoutFile{productID, product.Name, d, f}
+			rel := expandLayout(layout, l)
+			if _, dup := byPath[rel]; dup {
+				continue
+			}
+			entry := &MirrorEntry{DeliveryID: d.DeliveryID, File: f}
+			byPath[rel] = entry
+			name := expandLayout("{file_name}", l)
+			byName[name] = append(byName[name], entry)
+			order = append(order, entry)
+		}
+	}
+
+	report := &MirrorReport{}
+	type check struct {
+		entry *MirrorEntry
+		info  fs.FileInfo
+	}
+	var checks []check
+	claim := func(entry *MirrorEntry, path string, de fs.DirEntry) error {
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+		entry.Path = path
+		checks = append(checks, check{entry: entry, info: info})
+		return nil
+	}
+	// Files outside the layout are matched by name once all files in it are.
+	type stray struct {
+		path string
+		de   fs.DirEntry
+	}
+	var strays []stray
+	err = filepath.WalkDir(dir, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() {
+			return ctx.Err()
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if entry, ok := byPath[rel]; ok && entry.Path == "" {
+			return claim(entry, path, de)
+		}
+		strays = append(strays, stray{path, de})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range strays {
+		if entries := byName[s.de.Name()]; len(entries) == 1 && entries[0].Path == "" {
+			if err := claim(entries[0], s.path, s.de); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		report.Extra = append(report.Extra, s.path)
+	}
+
+	concurrency := defaultConcurrency
+	if opts != nil && opts.Concurrency > 0 {
+		concurrency = opts.Concurrency
+	}
+	queue := make(chan check)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(checks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ch := range queue {
+				problem, err := verifyLocalFile(ch.entry.Path, ch.info, ch.entry.File)
+				if err != nil {
+					problem = err.Error()
+				}
+				ch.entry.Problem = problem
+			}
+		}()
+	}
+	for _, ch := range checks {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- ch
+	}
+	close(queue)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range order {
+		switch {
+		case entry.Path == "":
+			report.Missing = append(report.Missing, *entry)
+		case entry.Problem != "":
+			report.Corrupt = append(report.Corrupt, *entry)
+		default:
+			report.Verified = append(report.Verified, *entry)
+		}
+	}
+	sort.Strings(report.Extra)
+	return report, nil
+}
@@ -0,0 +1,130 @@
+package bdds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestVerifyLocalMirror verifies missing, corrupt and extra files are reported
+// after a product mirror has been tampered with.
+func TestVerifyLocalMirror(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	oct15 := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{
+		weeklyDelivery(7, "2024-10-15", oct15, 2),
+		weeklyDelivery(8, "2024-10-22", oct15.AddDate(0, 0, 7), 2),
+	})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if _, err := client.DownloadProduct(ctx, 3, dir, nil); err != nil {
+		t.Fatalf("DownloadProduct: %v", err)
+	}
+	report, err := client.VerifyLocalMirror(ctx, 3, dir, nil)
+	if err != nil {
+		t.Fatalf("VerifyLocalMirror: %v", err)
+	}
+	if !report.OK() || len(report.Verified) != 4 || len(report.Extra) != 0 {
+		t.Fatalf("fresh mirror should verify cleanly: %+v", report)
+	}
+
+	// Corrupt one file (same size), delete one, add a stray file.
+	if err := os.WriteFile(filepath.Join(dir, "2024-10-15", "20241015_02.zip"), []byte("delivery 7 file X"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "2024-10-22", "20241022_01.zip")); err != nil {
+		t.Fatal(err)
+	}
+	stray := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(stray, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = client.VerifyLocalMirror(ctx, 3, dir, &DownloadOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("VerifyLocalMirror: %v", err)
+	}
+	if report.OK() {
+		t.Error("tampered mirror reported OK")
+	}
+	if len(report.Verified) != 2 {
+		t.Errorf("expected 2 verified files, got %d", len(report.Verified))
+	}
+	if len(report.Corrupt) != 1 || report.Corrupt[0].File.FileName != "20241015_02.zip" || report.Corrupt[0].Problem == "" {
+		t.Errorf("unexpected corrupt list: %+v", report.Corrupt)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].File.FileName != "20241022_01.zip" || report.Missing[0].DeliveryID != 8 {
+		t.Errorf("unexpected missing list: %+v", report.Missing)
+	}
+	if len(report.Extra) != 1 || report.Extra[0] != stray {
+		t.Errorf("unexpected extra list: %v", report.Extra)
+	}
+}
+
+// TestVerifyLocalMirrorSharedFileNames verifies files sharing a name across
+// deliveries are each checked at their own path, and that files in a flat
+// directory are matched by name only where the name is unique.
+func TestVerifyLocalMirrorSharedFileNames(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	oct15 := time.Date(2024, 10, 15, 10, 0, 0, 0, time.UTC)
+	first := weeklyDelivery(7, "2024-10-15", oct15, 1)
+	first.files = append(first.files, mirrorFile{id: 790, name: "readme.txt", content: "readme of week 42"})
+	second := weeklyDelivery(8, "2024-10-22", oct15.AddDate(0, 0, 7), 1)
+	second.files = append(second.files, mirrorFile{id: 890, name: "readme.txt", content: "readme of week 43"})
+	apiServer, _ := newMirrorServer(t, 3, []mirrorDelivery{first, second})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if _, err := client.DownloadProduct(ctx, 3, dir, nil); err != nil {
+		t.Fatalf("DownloadProduct: %v", err)
+	}
+	report, err := client.VerifyLocalMirror(ctx, 3, dir, nil)
+	if err != nil {
+		t.Fatalf("VerifyLocalMirror: %v", err)
+	}
+	if !report.OK() || len(report.Verified) != 4 || len(report.Extra) != 0 {
+		t.Fatalf("mirror with shared file names should verify cleanly: %+v", report)
+	}
+
+	// Swapping the readmes corrupts both; moving a uniquely named file to
+	// the top is still recognized.
+	week42, week43 := filepath.Join(dir, "2024-10-15", "readme.txt"), filepath.Join(dir, "2024-10-22", "readme.txt")
+	if err := os.WriteFile(week42, []byte("readme of week 43"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(week43, []byte("readme of week 42"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "2024-10-22", "20241022_01.zip"), filepath.Join(dir, "20241022_01.zip")); err != nil {
+		t.Fatal(err)
+	}
+	stray := filepath.Join(dir, "readme.txt")
+	if err := os.WriteFile(stray, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = client.VerifyLocalMirror(ctx, 3, dir, nil)
+	if err != nil {
+		t.Fatalf("VerifyLocalMirror: %v", err)
+	}
+	if len(report.Corrupt) != 2 || report.Corrupt[0].Path != week42 || report.Corrupt[1].Path != week43 {
+		t.Errorf("unexpected corrupt list: %+v", report.Corrupt)
+	}
+	if len(report.Verified) != 2 || len(report.Missing) != 0 {
+		t.Errorf("verified %d, missing %+v; want 2 verified and none missing", len(report.Verified), report.Missing)
+	}
+	if len(report.Extra) != 1 || report.Extra[0] != stray {
+		t.Errorf("unexpected extra list: %v", report.Extra)
+	}
+}
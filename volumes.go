@@ -0,0 +1,336 @@
+package bdds
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VolumeSet is one logical dataset that a delivery may split across numbered
+// volume files, e.g. docdb_backfile_001.zip, docdb_backfile_002.zip.
+type VolumeSet struct {
+	// Name identifies the set: the volume file name with its number removed,
+	// e.g. "docdb_backfile.zip", or "archive.zip" for archive.zip.001.
+	Name string
+	// Volumes are the set's files in volume order. A file that is not part
+	// of a numbered series forms a set of one.
+	Volumes []*DeliveryFile
+	// Split reports that the volumes are byte-level pieces of a single archive
+	// (archive.zip.001, archive.zip.002, ...) that must be joined before use,
+	// rather than independent archives.
+	Split bool
+}
+
+// IsMultiPart reports whether the set consists of more than one volume.
+func (s *VolumeSet) IsMultiPart() bool {
+	return len(s.Volumes) > 1
+}
+
+var (
+	// splitVolumePattern matches byte-split archives: archive.zip.001
+	splitVolumePattern = regexp.MustCompile(`^(.+\.[A-Za-z0-9]+)\.(\d{2,4})$`)
+	// numberedVolumePattern matches numbered archives: name_001.zip, name-part2.zip
+	numberedVolumePattern = regexp.MustCompile(`(?i)^(.+?)([_.-](?:part|vol|volume)?)(\d{1,4})(\.[A-Za-z0-9]+(?:\.gz)?)$`)
+)
+
+// volumeKey parses a file name into its set key and volume number.
+func volumeKey(name string) (key, setName string, number int, split, ok bool) {
+	if m := splitVolumePattern.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		return "split:" + m[1] + ":" + strconv.Itoa(len(m[2])), m[1], n, true, true
+	}
+	if m := numberedVolumePattern.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[3])
+		return "num:" + m[1] + m[2] + "#" + m[4] + ":" + strconv.Itoa(len(m[3])), m[1] + m[4], n, false, true
+	}
+	return "", "", 0, false, false
+}
+
+// GroupVolumes groups a delivery's files into volume sets. Files sharing a
+// name pattern and volume-number width form a set only when there are at least
+// two of them and their numbers are contiguous; everything else (including
+// weekly files that merely end in a number) is returned as a set of one. Sets
+// are returned in the order their first file appears in files.
+func GroupVolumes(files []*DeliveryFile) []*VolumeSet {
+	type member struct {
+		file   *DeliveryFile
+		number int
+	}
+	type group struct {
+		name    string
+		split   bool
+		members []member
+	}
+
+	// order records, per first appearance, either an ungroupable file or the
+	// key of a candidate group.
+	type slot struct {
+		file *DeliveryFile
+		key  string
+	}
+	groups := make(map[string]*group)
+	var order []slot
+	for _, f := range files {
+		key, name, n, split, ok := volumeKey(f.FileName)
+		if !ok {
+			order = append(order, slot{file: f})
+			continue
+		}
+		g, exists := groups[key]
+		if !exists {
+			g = &group{name: name, split: split}
+			groups[key] = g
+			order = append(order, slot{key: key})
+		}
+		g.members = append(g.members, member{file: f, number: n})
+	}
+
+	var sets []*VolumeSet
+	for _, s := range order {
+		if s.file != nil {
+			sets = append(sets, &VolumeSet{Name: s.file.FileName, Volumes: []*DeliveryFile{s.file}})
+			continue
+		}
+		g := groups[s.key]
+		sort.SliceStable(g.members, func(i, j int) bool { return g.members[i].number < g.members[j].number })
+		contiguous := len(g.members) > 1
+		for i := 1; i < len(g.members); i++ {
+			if g.members[i].number != g.members[i-1].number+1 {
+				contiguous = false
+				break
+			}
+		}
+		if !contiguous {
+			for _, m := range g.members {
+				sets = append(sets, &VolumeSet{Name: m.file.FileName, Volumes: []*DeliveryFile{m.file}})
+			}
+			continue
+		}
+		set := &VolumeSet{Name: g.name, Split: g.split}
+		for _, m := range g.members {
+			set.Volumes = append(set.Volumes, m.file)
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// VolumeMode selects what DownloadVolumes does with a set's volumes.
+type VolumeMode int
+
+const (
+	// VolumesSeparate writes each volume to its own file.
+	VolumesSeparate VolumeMode = iota
+	// VolumesConcatenate joins the volumes, in order, into one file named
+	// after the set. This reassembles byte-split archives.
+	VolumesConcatenate
+	// VolumesExtract extracts the volumes (ZIP archives) into the destination
+	// directory. Byte-split archives are joined first; independent numbered
+	// archives are extracted one after another into the same tree.
+	VolumesExtract
+)
+
+// VolumeOptions configures DownloadVolumes.
+type VolumeOptions struct {
+	Mode VolumeMode
+	// Progress, if set, reports combined progress across all volumes. The
+	// total is estimated from the catalog's human-readable file sizes.
+	Progress func(bytesWritten, totalBytes int64)
+}
+
+// VolumeResult describes the outcome of DownloadVolumes.
+type VolumeResult struct {
+	Set   *VolumeSet
+	Paths []string // volume files, the joined file, or the extracted files, depending on the mode
+	Bytes int64    // bytes downloaded across all volumes
+}
+
+// DownloadVolumes downloads every volume of set, in volume order, into destDir
+// and then applies opts.Mode. A nil opts downloads the volumes as separate
+// files. Downloading stops at the first failing volume.
+func (c *Client) DownloadVolumes(ctx context.Context, productID, deliveryID int, set *VolumeSet, destDir string, opts *VolumeOptions) (*VolumeResult, error) {
+	if opts == nil {
+		opts = &VolumeOptions{}
+	}
+	if err := os.MkdirAll(destDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	var total int64
+	for _, v := range set.Volumes {
+		total += parseFileSize(v.FileSize)
+	}
+
+	result := &VolumeResult{Set: set}
+	var volumePaths []string
+	for _, v := range set.Volumes {
+		path := filepath.Join(destDir, filepath.Base(v.FileName))
+		done := result.Bytes
+		var progressFn func(int64, int64)
+		if opts.Progress != nil {
+			progressFn = func(bytesWritten, _ int64) {
+				opts.Progress(done+bytesWritten, total)
+			}
+		}
+		n, err := c.downloadToPath(ctx, productID, deliveryID, v.FileID, path, v.FileSize, progressFn)
+		result.Bytes += n
+		if err != nil {
+			return result, fmt.Errorf("volume %s: %w", v.FileName, err)
+		}
+		volumePaths = append(volumePaths, path)
+	}
+
+	switch opts.Mode {
+	case VolumesSeparate:
+		result.Paths = volumePaths
+	case VolumesConcatenate:
+		joined := filepath.Join(destDir, filepath.Base(set.Name))
+		if err := concatenateFiles(joined, volumePaths); err != nil {
+			return result, err
+		}
+		result.Paths = []string{joined}
+	case VolumesExtract:
+		archives := volumePaths
+		if set.Split {
+			joined := filepath.Join(destDir, filepath.Base(set.Name))
+			if err := concatenateFiles(joined, volumePaths); err != nil {
+				return result, err
+			}
+			archives = []string{joined}
+		}
+		for _, archive := range archives {
+			extracted, err := extractZipFile(archive, destDir)
+			if err != nil {
+				return result, fmt.Errorf("failed to extract %s: %w", filepath.Base(archive), err)
+			}
+			result.Paths = append(result.Paths, extracted...)
+			if err := os.Remove(archive); err != nil {
+				return result, err
+			}
+		}
+	default:
+		return result, fmt.Errorf("unknown volume mode %d", opts.Mode)
+	}
+	return result, nil
+}
+
+// concatenateFiles joins parts, in order, into dst and removes the parts.
+// The parts are joined in a temporary file renamed over dst, as dst may be
+// one of them: a single volume is its set's joined file.
+func concatenateFiles(dst string, parts []string) (err error) {
+	if len(parts) == 1 && parts[0] == dst {
+		return nil
+	}
+	out, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = out.Close()
+			_ = os.Remove(out.Name())
+		}
+	}()
+	for _, part := range parts {
+		if err := appendFile(out, part); err != nil {
+			return err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", out.Name(), err)
+	}
+	if err := os.Rename(out.Name(), dst); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	for _, part := range parts {
+		if part == dst {
+			continue
+		}
+		if err := os.Remove(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendFile(w io.Writer, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	if _, err := io.Copy(w, in); err != nil {
+		return fmt.Errorf("failed to append %s: %w", path, err)
+	}
+	return nil
+}
+
+// extractZipFile extracts a ZIP archive into destDir and returns the paths of
+// the extracted files. Entries that would escape destDir are rejected.
+func extractZipFile(archive, destDir string) ([]string, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	var paths []string
+	for _, entry := range r.File {
+		target, err := safeJoin(destDir, entry.Name)
+		if err != nil {
+			return paths, err
+		}
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o750); err != nil {
+				return paths, err
+			}
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			return paths, err
+		}
+		err = writeFileFrom(target, rc)
+		_ = rc.Close()
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, target)
+	}
+	return paths, nil
+}
+
+// safeJoin joins an archive entry name onto dir, rejecting absolute names and
+// names that would escape dir ("zip slip").
+func safeJoin(dir, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q escapes the destination directory", name)
+	}
+	return filepath.Join(dir, clean), nil
+}
+
+// writeFileFrom creates path (and its parent directories) from r.
+func writeFileFrom(path string, r io.Reader) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	_, err = io.Copy(f, r)
+	return err
+}
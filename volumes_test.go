@@ -0,0 +1,245 @@
+package bdds
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func namedFiles(names ...string) []*DeliveryFile {
+	files := make([]*DeliveryFile, len(names))
+	for i, name := range names {
+		files[i] = &DeliveryFile{FileID: i + 1, FileName: name, FileSize: "1 kB"}
+	}
+	return files
+}
+
+// TestGroupVolumes verifies numbered and byte-split volumes are grouped while
+// unrelated files stay single.
+func TestGroupVolumes(t *testing.T) {
+	sets := GroupVolumes(namedFiles(
+		"docdb_backfile_002.zip",
+		"readme.txt",
+		"docdb_backfile_001.zip",
+		"archive.zip.001",
+		"archive.zip.002",
+		"archive.zip.003",
+		"EP_docdb_20241015.zip",
+		"gap_1.zip",
+		"gap_3.zip",
+	))
+
+	type want struct {
+		name    string
+		volumes []string
+		split   bool
+	}
+	wants := []want{
+		{name: "docdb_backfile.zip", volumes: []string{"docdb_backfile_001.zip", "docdb_backfile_002.zip"}},
+		{name: "readme.txt", volumes: []string{"readme.txt"}},
+		{name: "archive.zip", volumes: []string{"archive.zip.001", "archive.zip.002", "archive.zip.003"}, split: true},
+		{name: "EP_docdb_20241015.zip", volumes: []string{"EP_docdb_20241015.zip"}},
+		{name: "gap_1.zip", volumes: []string{"gap_1.zip"}},
+		{name: "gap_3.zip", volumes: []string{"gap_3.zip"}},
+	}
+	if len(sets) != len(wants) {
+		t.Fatalf("expected %d sets, got %d", len(wants), len(sets))
+	}
+	for i, w := range wants {
+		s := sets[i]
+		if s.Name != w.name || s.Split != w.split {
+			t.Errorf("set %d: got name %q split %v, want %q %v", i, s.Name, s.Split, w.name, w.split)
+		}
+		if len(s.Volumes) != len(w.volumes) {
+			t.Errorf("set %d: got %d volumes, want %d", i, len(s.Volumes), len(w.volumes))
+			continue
+		}
+		for j, v := range s.Volumes {
+			if v.FileName != w.volumes[j] {
+				t.Errorf("set %d volume %d: got %q, want %q", i, j, v.FileName, w.volumes[j])
+			}
+		}
+		if s.IsMultiPart() != (len(w.volumes) > 1) {
+			t.Errorf("set %d: IsMultiPart = %v", i, s.IsMultiPart())
+		}
+	}
+}
+
+var downloadPathPattern = regexp.MustCompile(`/file/(\d+)/download$`)
+
+// newFileServer serves contents[fileID] on the download endpoint.
+func newFileServer(t *testing.T, contents map[int][]byte) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := downloadPathPattern.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			http.NotFound(w, r)
+			return
+		}
+		id, _ := strconv.Atoi(m[1])
+		content, ok := contents[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		_, _ = w.Write(content)
+	}))
+}
+
+func zipBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip create: %v", err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestDownloadVolumesConcatenate verifies volumes are joined in order with
+// combined progress.
+func TestDownloadVolumesConcatenate(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{1: []byte("second"), 2: []byte("first-")})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	set := &VolumeSet{Name: "data.bin", Volumes: []*DeliveryFile{
+		{FileID: 2, FileName: "data.bin.001", FileSize: "6 B"},
+		{FileID: 1, FileName: "data.bin.002", FileSize: "6 B"},
+	}, Split: true}
+
+	dir := t.TempDir()
+	var lastWritten, lastTotal int64
+	result, err := client.DownloadVolumes(context.Background(), 1, 1, set, dir, &VolumeOptions{
+		Mode: VolumesConcatenate,
+		Progress: func(written, total int64) {
+			lastWritten, lastTotal = written, total
+		},
+	})
+	if err != nil {
+		t.Fatalf("DownloadVolumes: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "data.bin"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(got) != "first-second" {
+		t.Errorf("joined content = %q", got)
+	}
+	if result.Bytes != 12 || lastWritten != 12 || lastTotal != 12 {
+		t.Errorf("bytes = %d, progress = %d/%d, want 12", result.Bytes, lastWritten, lastTotal)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data.bin.001")); !os.IsNotExist(err) {
+		t.Error("expected volume files to be removed after concatenation")
+	}
+}
+
+// TestDownloadVolumesConcatenateSingle verifies a single-file set, whose
+// joined file is the volume itself, is kept intact.
+func TestDownloadVolumesConcatenateSingle(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{1: []byte("content")})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	set := GroupVolumes([]*DeliveryFile{{FileID: 1, FileName: "single.zip", FileSize: "7 B"}})[0]
+
+	dir := t.TempDir()
+	result, err := client.DownloadVolumes(context.Background(), 1, 1, set, dir, &VolumeOptions{Mode: VolumesConcatenate})
+	if err != nil {
+		t.Fatalf("DownloadVolumes: %v", err)
+	}
+	path := filepath.Join(dir, "single.zip")
+	if got, err := os.ReadFile(path); err != nil || string(got) != "content" {
+		t.Errorf("content = %q, %v; want %q", got, err, "content")
+	}
+	if len(result.Paths) != 1 || result.Paths[0] != path {
+		t.Errorf("paths = %v, want [%s]", result.Paths, path)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want only the file", len(entries))
+	}
+}
+
+// TestDownloadVolumesExtractSplit verifies a byte-split ZIP is joined and
+// extracted.
+func TestDownloadVolumesExtractSplit(t *testing.T) {
+	archive := zipBytes(t, map[string]string{"doc/a.xml": "<a/>", "b.xml": "<b/>"})
+	half := len(archive) / 2
+
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+	apiServer := newFileServer(t, map[int][]byte{1: archive[:half], 2: archive[half:]})
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	set := GroupVolumes([]*DeliveryFile{
+		{FileID: 1, FileName: "docs.zip.001"},
+		{FileID: 2, FileName: "docs.zip.002"},
+	})[0]
+
+	dir := t.TempDir()
+	result, err := client.DownloadVolumes(context.Background(), 1, 1, set, dir, &VolumeOptions{Mode: VolumesExtract})
+	if err != nil {
+		t.Fatalf("DownloadVolumes: %v", err)
+	}
+	if len(result.Paths) != 2 {
+		t.Fatalf("expected 2 extracted files, got %v", result.Paths)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "doc", "a.xml"))
+	if err != nil || string(got) != "<a/>" {
+		t.Errorf("extracted doc/a.xml = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs.zip")); !os.IsNotExist(err) {
+		t.Error("expected joined archive to be removed after extraction")
+	}
+}
+
+// TestExtractZipRejectsTraversal verifies entries escaping the destination
+// are rejected.
+func TestExtractZipRejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.zip")
+	if err := os.WriteFile(archive, zipBytes(t, map[string]string{"../escape.txt": "x"}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractZipFile(archive, filepath.Join(dir, "out")); err == nil {
+		t.Fatal("expected error for path traversal entry")
+	}
+}
+
+// TestParseFileSize verifies catalog size parsing.
+func TestParseFileSize(t *testing.T) {
+	tests := map[string]int64{
+		"406.6 kB": 406600,
+		"1.5 GB":   1500000000,
+		"17 B":     17,
+		"1 MiB":    1 << 20,
+		"":         0,
+		"big":      0,
+		"1.5 XB":   0,
+	}
+	for in, want := range tests {
+		if got := parseFileSize(in); got != want {
+			t.Errorf("parseFileSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package bdds
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// defaultWatchInterval is how often WatchProduct polls when interval is unset.
+const defaultWatchInterval = time.Hour
+
+// DeliveryEvent is a delivery that WatchProduct found new in the catalog, or
+// an error from one of its polls.
+type DeliveryEvent struct {
+	ProductID int
+	Delivery  *Delivery // nil when Err is set
+	Err       error
+}
+
+// WatchProduct polls the catalog of a product about every interval (default:
+// one hour, jittered by up to 10% to spread load) and sends an event for each
+// delivery that appears. The deliveries present at the first poll are the
+// baseline and are not reported; use a Sync to catch up on those. Each
+// delivery is reported once, in catalog order.
+//
+// A failed poll is sent as an event with Err set and polling continues. The
+// channel is closed once ctx is cancelled or the client is closed.
+func (c *Client) WatchProduct(ctx context.Context, productID int, interval time.Duration) <-chan DeliveryEvent {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	events := make(chan DeliveryEvent)
+	c.goroutine(ctx, func(ctx context.Context) {
+		defer close(events)
+		send := func(e DeliveryEvent) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var seen map[int]bool // nil until the baseline poll succeeded
+		for {
+			product, err := c.GetProduct(ctx, productID, WithNoCache())
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				if !send(DeliveryEvent{ProductID: productID, Err: err}) {
+					return
+				}
+			case seen == nil:
+				seen = make(map[int]bool, len(product.Deliveries))
+				for _, d := range product.Deliveries {
+					seen[d.DeliveryID] = true
+				}
+			default:
+				for _, d := range product.Deliveries {
+					if seen[d.DeliveryID] {
+						continue
+					}
+					seen[d.DeliveryID] = true
+					if !send(DeliveryEvent{ProductID: productID, Delivery: d}) {
+						return
+					}
+				}
+			}
+
+			jitter := time.Duration((rand.Float64()*2 - 1) * 0.1 * float64(interval))
+			timer := time.NewTimer(interval + jitter)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	})
+	return events
+}
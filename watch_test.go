@@ -0,0 +1,83 @@
+package bdds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWatchProduct verifies the first poll is a baseline, a failed poll is
+// reported without ending the watch, new deliveries are reported once, and
+// the channel closes on cancellation.
+func TestWatchProduct(t *testing.T) {
+	authServer, _ := newAuthServer(3600)
+	defer authServer.Close()
+
+	delivery := func(id int, name string) map[string]interface{} {
+		return map[string]interface{}{
+			"deliveryId":                  id,
+			"deliveryName":                name,
+			"deliveryPublicationDatetime": "2024-10-15T10:00:00Z",
+			"files":                       []interface{}{},
+		}
+	}
+	var polls int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		deliveries := []interface{}{delivery(7, "2024-10-15")}
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+		case 2:
+			http.NotFound(w, nil)
+			return
+		default:
+			deliveries = append(deliveries, delivery(8, "2024-10-22"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": 3, "name": "Test product", "description": "test", "deliveries": deliveries,
+		})
+	}))
+	defer apiServer.Close()
+
+	client := newTestClient(t, apiServer.URL, authServer.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := client.WatchProduct(ctx, 3, 5*time.Millisecond)
+
+	next := func() DeliveryEvent {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return DeliveryEvent{}
+		}
+	}
+
+	var notFound *NotFoundError
+	if e := next(); !errors.As(e.Err, &notFound) || e.Delivery != nil {
+		t.Fatalf("first event = %+v, want the failed poll", e)
+	}
+	if e := next(); e.Err != nil || e.ProductID != 3 || e.Delivery == nil || e.Delivery.DeliveryID != 8 {
+		t.Fatalf("second event = %+v, want delivery 8", e)
+	}
+
+	// Later polls see delivery 8 again but must not report it.
+	for atomic.LoadInt32(&polls) < 6 {
+		select {
+		case e := <-events:
+			t.Fatalf("unexpected event %+v", e)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	for e := range events {
+		t.Errorf("event after cancel: %+v", e)
+	}
+}
@@ -0,0 +1,193 @@
+package bdds
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Webhook event types.
+const (
+	WebhookNewDelivery    = "delivery.new"
+	WebhookFileDownloaded = "file.downloaded"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the request body, as
+// "sha256=<hex>", when Webhook.Secret is set.
+const WebhookSignatureHeader = "X-Bdds-Signature"
+
+// WebhookEvent is the default JSON payload of a webhook notification.
+type WebhookEvent struct {
+	Type         string    `json:"type"` // WebhookNewDelivery or WebhookFileDownloaded
+	ProductID    int       `json:"productId"`
+	DeliveryID   int       `json:"deliveryId"`
+	DeliveryName string    `json:"deliveryName,omitempty"`
+	FileID       int       `json:"fileId,omitempty"`
+	FileName     string    `json:"fileName,omitempty"`
+	Path         string    `json:"path,omitempty"`
+	Bytes        int64     `json:"bytes,omitempty"`
+	Error        string    `json:"error,omitempty"` // a failed download
+	Time         time.Time `json:"time"`
+}
+
+// Webhook posts JSON notifications about new deliveries (from WatchProduct)
+// and completed downloads (from DownloadOptions.OnFileComplete) to one or
+// more URLs, e.g. to trigger ingestion downstream.
+type Webhook struct {
+	URLs []string
+	// Secret, if set, signs every request body with HMAC-SHA256 in the
+	// WebhookSignatureHeader header.
+	Secret string
+	// Payload, if set, builds the JSON body from an event instead of
+	// sending the event itself.
+	Payload func(*WebhookEvent) any
+	// ContentType is the Content-Type of the requests (default:
+	// "application/json"), e.g. CloudEventContentType with
+	// CloudEventPayload.
+	ContentType string
+	// MaxRetries is how often a failed delivery to a URL is retried
+	// (default: 3). Network errors, 429 and 5xx responses are retried.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry, growing linearly
+	// (default: 1s).
+	RetryDelay time.Duration
+	// HTTPClient sends the requests (default: a client with a 30s timeout).
+	HTTPClient *http.Client
+}
+
+// NotifyNewDelivery posts a WebhookNewDelivery event for a delivery.
+func (w *Webhook) NotifyNewDelivery(ctx context.Context, productID int, d *Delivery) error {
+	return w.Notify(ctx, newDeliveryEvent(productID, d))
+}
+
+// NotifyFileDownloaded posts a WebhookFileDownloaded event for a bulk
+// download result, successful or not.
+func (w *Webhook) NotifyFileDownloaded(ctx context.Context, r *FileResult) error {
+	return w.Notify(ctx, fileDownloadedEvent(r))
+}
+
+// newDeliveryEvent returns the WebhookNewDelivery event of a delivery.
+func newDeliveryEvent(productID int, d *Delivery) *WebhookEvent {
+	return &WebhookEvent{
+		Type:         WebhookNewDelivery,
+		ProductID:    productID,
+		DeliveryID:   d.DeliveryID,
+		DeliveryName: d.DeliveryName,
+		Time:         time.Now().UTC(),
+	}
+}
+
+// fileDownloadedEvent returns the WebhookFileDownloaded event of a bulk
+// download result.
+func fileDownloadedEvent(r *FileResult) *WebhookEvent {
+	e := &WebhookEvent{
+		Type:       WebhookFileDownloaded,
+		ProductID:  r.ProductID,
+		DeliveryID: r.DeliveryID,
+		FileID:     r.File.FileID,
+		FileName:   r.File.FileName,
+		Path:       r.Path,
+		Bytes:      r.Bytes,
+		Time:       r.CompletedAt.UTC(),
+	}
+	if r.Err != nil {
+		e.Error = r.Err.Error()
+	}
+	return e
+}
+
+// Notify posts an event to every URL and returns the join of the errors of
+// the URLs that could not be notified.
+func (w *Webhook) Notify(ctx context.Context, event *WebhookEvent) error {
+	var payload any = event
+	if w.Payload != nil {
+		payload = w.Payload(event)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	var errs []error
+	for _, url := range w.URLs {
+		if err := w.post(ctx, url, event.Type, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", redactURL(url), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends body to url, retrying transient failures.
+func (w *Webhook) post(ctx context.Context, url, eventType string, body []byte) error {
+	client := w.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	maxRetries, delay := w.MaxRetries, w.RetryDelay
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+	if delay == 0 {
+		delay = time.Second
+	}
+	contentType := w.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	var errs []error
+	status := 0
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(time.Duration(attempt) * delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return redactURLError(err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Bdds-Event", eventType)
+		if w.Secret != "" {
+			mac := hmac.New(sha256.New, []byte(w.Secret))
+			mac.Write(body)
+			req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs, status = append(errs, redactURLError(err)), 0
+			continue
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		errs, status = append(errs, fmt.Errorf("unexpected status %d", resp.StatusCode)), resp.StatusCode
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			break
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return &RetryExhaustedError{Attempts: len(errs), Errors: errs, LastStatus: status}
+}
@@ -0,0 +1,94 @@
+package bdds
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWebhookNotify verifies signed delivery to every URL with retries of
+// transient failures and no retries of client errors.
+func TestWebhookNotify(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []WebhookEvent
+	var calls int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(body)
+		if got, want := r.Header.Get(WebhookSignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+		if got := r.Header.Get("X-Bdds-Event"); got != WebhookFileDownloaded {
+			t.Errorf("event header = %q", got)
+		}
+		var e WebhookEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("payload: %v", err)
+		}
+		mu.Lock()
+		bodies = append(bodies, e)
+		mu.Unlock()
+	}))
+	defer flaky.Close()
+	var rejected int32
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&rejected, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	hook := &Webhook{URLs: []string{flaky.URL, rejecting.URL}, Secret: "s3cret", RetryDelay: time.Millisecond}
+	err := hook.NotifyFileDownloaded(context.Background(), &FileResult{
+		ProductID: 3, DeliveryID: 7, Path: "mirror/a.zip", Bytes: 42,
+		File:        &DeliveryFile{FileID: 701, FileName: "a.zip"},
+		CompletedAt: time.Now(),
+	})
+	if err == nil {
+		t.Fatal("expected the rejecting URL to be reported")
+	}
+	if n := atomic.LoadInt32(&rejected); n != 1 {
+		t.Errorf("a 400 was retried: %d requests", n)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected the 503 to be retried once, got %d requests", n)
+	}
+	if len(bodies) != 1 || bodies[0].FileID != 701 || bodies[0].Path != "mirror/a.zip" || bodies[0].Bytes != 42 || bodies[0].Error != "" {
+		t.Errorf("unexpected payloads: %+v", bodies)
+	}
+}
+
+// TestWebhookPayload verifies a custom payload replaces the default event.
+func TestWebhookPayload(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	hook := &Webhook{
+		URLs: []string{srv.URL},
+		Payload: func(e *WebhookEvent) any {
+			return map[string]string{"text": "new delivery " + e.DeliveryName}
+		},
+	}
+	if err := hook.NotifyNewDelivery(context.Background(), 3, &Delivery{DeliveryID: 8, DeliveryName: "2024-10-22"}); err != nil {
+		t.Fatalf("NotifyNewDelivery: %v", err)
+	}
+	if got["text"] != "new delivery 2024-10-22" {
+		t.Errorf("payload = %v", got)
+	}
+}